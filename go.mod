@@ -2,5 +2,4 @@ module github.com/MaoDaGreith/logging
 
 go 1.18
 
-require gopkg.in/yaml.v3 v3.0.1 // indirect
-
+require gopkg.in/yaml.v3 v3.0.1
@@ -8,30 +8,128 @@ import (
 
 	"github.com/MaoDaGreith/logging/pkg/core"
 	"github.com/MaoDaGreith/logging/pkg/drivers"
+	"github.com/MaoDaGreith/logging/pkg/processors"
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the logger configuration
 type Config struct {
 	Logger       Logger
-	DefaultLevel string         `json:"default_level"`
-	Drivers      []DriverConfig `json:"drivers"`
+	DefaultLevel string            `json:"default_level" yaml:"default_level"`
+	CustomLevels []LevelConfig     `json:"custom_levels,omitempty" yaml:"custom_levels,omitempty"`
+	Caller       *CallerConfig     `json:"caller,omitempty" yaml:"caller,omitempty"`
+	Stacktrace   *StackConfig      `json:"stacktrace,omitempty" yaml:"stacktrace,omitempty"`
+	Processors   []ProcessorConfig `json:"processors,omitempty" yaml:"processors,omitempty"`
+	Drivers      []DriverConfig    `json:"drivers" yaml:"drivers"`
+}
+
+// ProcessorConfig represents a single processor configuration. Processors
+// run in the order they are listed.
+type ProcessorConfig struct {
+	Type    string                 `json:"type" yaml:"type"`
+	Options map[string]interface{} `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// CallerConfig represents the caller information settings of the logger
+type CallerConfig struct {
+	Enabled      bool     `json:"enabled" yaml:"enabled"`
+	Skip         int      `json:"skip,omitempty" yaml:"skip,omitempty"`
+	ShortPaths   bool     `json:"short_paths,omitempty" yaml:"short_paths,omitempty"`
+	TrimPrefixes []string `json:"trim_prefixes,omitempty" yaml:"trim_prefixes,omitempty"`
+}
+
+// LevelConfig represents a custom level that is registered before the drivers are created
+type LevelConfig struct {
+	Value   int      `json:"value" yaml:"value"`
+	Name    string   `json:"name" yaml:"name"`
+	Aliases []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	Color   string   `json:"color,omitempty" yaml:"color,omitempty"`
+}
+
+// StackConfig represents the automatic stack trace settings of the logger
+type StackConfig struct {
+	Enabled   bool   `json:"enabled" yaml:"enabled"`
+	Level     string `json:"level,omitempty" yaml:"level,omitempty"`
+	MaxFrames int    `json:"max_frames,omitempty" yaml:"max_frames,omitempty"`
 }
 
 // DriverConfig represents a single driver configuration
 type DriverConfig struct {
-	Type     string                 `json:"type"`
-	MinLevel string                 `json:"min_level"`
-	Options  map[string]interface{} `json:"options,omitempty"`
+	Type          string                 `json:"type" yaml:"type"`
+	MinLevel      string                 `json:"min_level" yaml:"min_level"`
+	MaxLevel      string                 `json:"max_level,omitempty" yaml:"max_level,omitempty"`
+	Levels        []string               `json:"levels,omitempty" yaml:"levels,omitempty"`
+	ExcludeLevels []string               `json:"exclude_levels,omitempty" yaml:"exclude_levels,omitempty"`
+	Options       map[string]interface{} `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// UnmarshalYAML decodes a configuration, accepting the "defaultlevel" key of
+// earlier versions when "default_level" is not set
+func (c *Config) UnmarshalYAML(value *yaml.Node) error {
+	type plain Config
+	if err := value.Decode((*plain)(c)); err != nil {
+		return err
+	}
+
+	var legacy struct {
+		DefaultLevel string `yaml:"defaultlevel"`
+	}
+	if err := value.Decode(&legacy); err != nil {
+		return err
+	}
+	if c.DefaultLevel == "" {
+		c.DefaultLevel = legacy.DefaultLevel
+	}
+
+	return nil
+}
+
+// UnmarshalYAML decodes a driver configuration, accepting the "minlevel" key
+// of earlier versions when "min_level" is not set
+func (d *DriverConfig) UnmarshalYAML(value *yaml.Node) error {
+	type plain DriverConfig
+	if err := value.Decode((*plain)(d)); err != nil {
+		return err
+	}
+
+	var legacy struct {
+		MinLevel string `yaml:"minlevel"`
+	}
+	if err := value.Decode(&legacy); err != nil {
+		return err
+	}
+	if d.MinLevel == "" {
+		d.MinLevel = legacy.MinLevel
+	}
+
+	return nil
 }
 
 // Logger is the main interface for logging
 type Logger interface {
+	Trace(msg string, attrs ...core.Attributes) error
 	Debug(msg string, attrs ...core.Attributes) error
 	Info(msg string, attrs ...core.Attributes) error
+	Notice(msg string, attrs ...core.Attributes) error
 	Warning(msg string, attrs ...core.Attributes) error
 	Error(msg string, attrs ...core.Attributes) error
+	Critical(msg string, attrs ...core.Attributes) error
+	Fatal(msg string, attrs ...core.Attributes) error
+	Panic(msg string, attrs ...core.Attributes) error
+	Err(err error, msg string, attrs ...core.Attributes) error
 	Log(level core.Level, msg string, attrs ...core.Attributes) error
+	Tracef(format string, args ...interface{}) error
+	Debugf(format string, args ...interface{}) error
+	Infof(format string, args ...interface{}) error
+	Noticef(format string, args ...interface{}) error
+	Warningf(format string, args ...interface{}) error
+	Errorf(format string, args ...interface{}) error
+	Criticalf(format string, args ...interface{}) error
+	Fatalf(format string, args ...interface{}) error
+	Panicf(format string, args ...interface{}) error
+	Logf(level core.Level, format string, args ...interface{}) error
+	LogFn(level core.Level, fn func() string, attrs ...core.Attributes) error
+	Enabled(level core.Level) bool
 	NewTransaction(txID string) core.Transaction
 	Close() error
 }
@@ -87,6 +185,75 @@ func LoadDefault() (*Config, error) {
 
 // CreateLogger creates a logger from a configuration
 func (c *Config) CreateLogger() (Logger, error) {
+	for _, levelConfig := range c.CustomLevels {
+		err := core.RegisterLevel(core.LevelDefinition{
+			Level:   core.Level(levelConfig.Value),
+			Name:    levelConfig.Name,
+			Aliases: levelConfig.Aliases,
+			Color:   levelConfig.Color,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to register level '%s': %w", levelConfig.Name, err)
+		}
+	}
+
+	options, err := c.loggerOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	driverInstances, err := c.createDrivers()
+	if err != nil {
+		return nil, err
+	}
+
+	options = append(options, core.WithDrivers(driverInstances...))
+	return core.NewLoggerWithOptions(options...), nil
+}
+
+// loggerOptions converts the logger-wide settings to logger options
+func (c *Config) loggerOptions() ([]core.LoggerOption, error) {
+	var options []core.LoggerOption
+
+	if c.Caller != nil && c.Caller.Enabled {
+		options = append(options,
+			core.AddCaller(),
+			core.AddCallerSkip(c.Caller.Skip),
+			core.TrimCallerPrefixes(c.Caller.TrimPrefixes...),
+		)
+		if c.Caller.ShortPaths {
+			options = append(options, core.ShortCallerPaths())
+		}
+	}
+
+	if c.Stacktrace != nil && c.Stacktrace.Enabled {
+		level := core.Error
+		if c.Stacktrace.Level != "" {
+			parsed, err := core.ParseLevel(c.Stacktrace.Level)
+			if err != nil {
+				return nil, fmt.Errorf("invalid stacktrace level: %w", err)
+			}
+			level = parsed
+		}
+		options = append(options,
+			core.AddStacktrace(level),
+			core.WithStacktraceDepth(c.Stacktrace.MaxFrames),
+		)
+	}
+
+	for _, processorConfig := range c.Processors {
+		processor, err := processors.Create(processorConfig.Type, processorConfig.Options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create processor '%s': %w", processorConfig.Type, err)
+		}
+		options = append(options, core.WithProcessors(processor))
+	}
+
+	return options, nil
+}
+
+// createDrivers instantiates the configured drivers
+func (c *Config) createDrivers() ([]core.Driver, error) {
 	driverInstances := make([]core.Driver, 0, len(c.Drivers))
 
 	for _, driverConfig := range c.Drivers {
@@ -98,6 +265,18 @@ func (c *Config) CreateLogger() (Logger, error) {
 			driverConfig.Options["min_level"] = driverConfig.MinLevel
 		}
 
+		if _, ok := driverConfig.Options["max_level"]; !ok && driverConfig.MaxLevel != "" {
+			driverConfig.Options["max_level"] = driverConfig.MaxLevel
+		}
+
+		if _, ok := driverConfig.Options["levels"]; !ok && len(driverConfig.Levels) > 0 {
+			driverConfig.Options["levels"] = driverConfig.Levels
+		}
+
+		if _, ok := driverConfig.Options["exclude_levels"]; !ok && len(driverConfig.ExcludeLevels) > 0 {
+			driverConfig.Options["exclude_levels"] = driverConfig.ExcludeLevels
+		}
+
 		driver, err := drivers.Create(driverConfig.Type, driverConfig.Options)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create driver '%s': %w", driverConfig.Type, err)
@@ -106,7 +285,7 @@ func (c *Config) CreateLogger() (Logger, error) {
 		driverInstances = append(driverInstances, driver)
 	}
 
-	return core.NewLogger(driverInstances...), nil
+	return driverInstances, nil
 }
 
 // SaveToFile saves the configuration to a file
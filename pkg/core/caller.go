@@ -0,0 +1,90 @@
+package core
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// callerDepth is the number of frames from logger.log up to the user's call
+// site: the exported logging method and the call site itself
+const callerDepth = 2
+
+// defaultStacktraceDepth is the default frame limit for recorded stack traces
+const defaultStacktraceDepth = 32
+
+// callerPathTrimmer shortens the file paths recorded for callers
+type callerPathTrimmer struct {
+	short    bool
+	prefixes []string
+}
+
+// trim applies the configured prefix trimming and shortening to a path
+func (t callerPathTrimmer) trim(file string) string {
+	for _, prefix := range t.prefixes {
+		if strings.HasPrefix(file, prefix) {
+			file = strings.TrimPrefix(file[len(prefix):], "/")
+			break
+		}
+	}
+
+	if t.short {
+		dir, name := filepath.Split(file)
+		if dir = filepath.Base(strings.TrimSuffix(dir, "/")); dir != "." && dir != "/" && dir != "" {
+			return dir + "/" + name
+		}
+		return name
+	}
+
+	return file
+}
+
+// caller returns the frame skip levels above the function that calls it
+func (l *logger) caller(skip int) *Frame {
+	pc, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return nil
+	}
+
+	frame := &Frame{
+		File: l.callerPaths.trim(file),
+		Line: line,
+	}
+
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		frame.Function = fn.Name()
+	}
+
+	return frame
+}
+
+// stacktrace returns up to stacktraceDepth frames starting skip levels above
+// the function that calls it. Frames inside the Go runtime are left out.
+func (l *logger) stacktrace(skip int) []Frame {
+	depth := l.stacktraceDepth
+	if depth <= 0 {
+		depth = defaultStacktraceDepth
+	}
+
+	// Over-allocate so that filtered runtime frames do not eat into the limit
+	pcs := make([]uintptr, depth+8)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	stack := make([]Frame, 0, depth)
+	for len(stack) < depth {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") && frame.Function != "" {
+			stack = append(stack, Frame{
+				Function: frame.Function,
+				File:     l.callerPaths.trim(frame.File),
+				Line:     frame.Line,
+			})
+		}
+		if !more {
+			break
+		}
+	}
+
+	return stack
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// currentLine returns the line number of its call site
+func currentLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+func TestLoggerAddCaller(t *testing.T) {
+	mockDriver := &MockDriver{}
+	logger := NewLoggerWithOptions(WithDrivers(mockDriver), AddCaller())
+	tx := logger.NewTransaction("tx-caller")
+
+	tests := []struct {
+		name string
+		log  func() int
+	}{
+		{"Info", func() int { logger.Info("message"); return currentLine() }},
+		{"Log", func() int { logger.Log(Warning, "message"); return currentLine() }},
+		{"Err", func() int { logger.Err(nil, "message"); return currentLine() }},
+		{"Transaction.Debug", func() int { tx.Debug("message"); return currentLine() }},
+		{"Transaction.Log", func() int { tx.Log(Notice, "message"); return currentLine() }},
+		{"Transaction.Err", func() int { tx.Err(nil, "message"); return currentLine() }},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wantLine := test.log()
+
+			caller := mockDriver.Logs[i].Caller
+			if caller == nil {
+				t.Fatal("Expected caller to be recorded")
+			}
+			if !strings.HasSuffix(caller.File, "/core/caller_test.go") {
+				t.Errorf("Caller.File = %q, want caller_test.go", caller.File)
+			}
+			if caller.Line != wantLine {
+				t.Errorf("Caller.Line = %d, want %d", caller.Line, wantLine)
+			}
+			if !strings.Contains(caller.Function, "TestLoggerAddCaller") {
+				t.Errorf("Caller.Function = %q, want test function", caller.Function)
+			}
+		})
+	}
+}
+
+func TestLoggerCallerDisabled(t *testing.T) {
+	mockDriver := &MockDriver{}
+	logger := NewLogger(mockDriver)
+
+	logger.Info("message")
+
+	if mockDriver.Logs[0].Caller != nil {
+		t.Errorf("Caller = %+v, want nil", mockDriver.Logs[0].Caller)
+	}
+}
+
+func TestLoggerAddCallerSkip(t *testing.T) {
+	mockDriver := &MockDriver{}
+	logger := NewLoggerWithOptions(WithDrivers(mockDriver), AddCaller(), AddCallerSkip(1))
+
+	// logWrapped stands in for an application helper that wraps the logger
+	logWrapped := func(msg string) {
+		logger.Info(msg)
+	}
+
+	logWrapped("message")
+	wantLine := currentLine() - 1
+
+	if got := mockDriver.Logs[0].Caller.Line; got != wantLine {
+		t.Errorf("Caller.Line = %d, want %d", got, wantLine)
+	}
+}
+
+func TestCallerPathTrimmer(t *testing.T) {
+	tests := []struct {
+		name     string
+		trimmer  callerPathTrimmer
+		file     string
+		expected string
+	}{
+		{"no trimming", callerPathTrimmer{}, "/src/app/pkg/core/logger.go", "/src/app/pkg/core/logger.go"},
+		{"short", callerPathTrimmer{short: true}, "/src/app/pkg/core/logger.go", "core/logger.go"},
+		{"short without directory", callerPathTrimmer{short: true}, "logger.go", "logger.go"},
+		{"prefix", callerPathTrimmer{prefixes: []string{"/src/app"}}, "/src/app/pkg/core/logger.go", "pkg/core/logger.go"},
+		{"first matching prefix", callerPathTrimmer{prefixes: []string{"/other", "/src/"}}, "/src/app/main.go", "app/main.go"},
+		{"prefix not matching", callerPathTrimmer{prefixes: []string{"/other"}}, "/src/app/main.go", "/src/app/main.go"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.trimmer.trim(test.file); got != test.expected {
+				t.Errorf("trim(%q) = %q, want %q", test.file, got, test.expected)
+			}
+		})
+	}
+}
+
+func TestLoggerAddStacktrace(t *testing.T) {
+	mockDriver := &MockDriver{}
+	logger := NewLoggerWithOptions(WithDrivers(mockDriver), AddStacktrace(Warning))
+
+	logger.Info("below threshold")
+	logger.Warning("at threshold")
+	wantLine := currentLine() - 1
+	logger.NewTransaction("tx").Error("above threshold")
+
+	if len(mockDriver.Logs[0].Stack) != 0 {
+		t.Errorf("Expected no stack below threshold, got %d frames", len(mockDriver.Logs[0].Stack))
+	}
+
+	for _, log := range mockDriver.Logs[1:] {
+		if len(log.Stack) == 0 {
+			t.Fatalf("Expected stack for %v entry", log.Level)
+		}
+		if !strings.Contains(log.Stack[0].Function, "TestLoggerAddStacktrace") {
+			t.Errorf("Stack[0].Function = %q, want test function", log.Stack[0].Function)
+		}
+		for _, frame := range log.Stack {
+			if strings.HasPrefix(frame.Function, "runtime.") {
+				t.Errorf("Stack contains runtime frame %v", frame)
+			}
+		}
+	}
+
+	if got := mockDriver.Logs[1].Stack[0].Line; got != wantLine {
+		t.Errorf("Stack[0].Line = %d, want %d", got, wantLine)
+	}
+}
+
+func TestLoggerStacktraceDepth(t *testing.T) {
+	mockDriver := &MockDriver{}
+	logger := NewLoggerWithOptions(WithDrivers(mockDriver), AddStacktrace(Error), WithStacktraceDepth(1))
+
+	logger.Error("limited")
+
+	if got := len(mockDriver.Logs[0].Stack); got != 1 {
+		t.Errorf("Stack length = %d, want 1", got)
+	}
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+)
+
+// Frame is a single function call in a stack trace
+type Frame struct {
+	// Function is the fully qualified function name
+	Function string
+
+	// File is the absolute path of the source file
+	File string
+
+	// Line is the line number in File
+	Line int
+}
+
+// String returns the frame as "function (file:line)"
+func (f Frame) String() string {
+	return fmt.Sprintf("%s (%s:%d)", f.Function, f.File, f.Line)
+}
+
+// ErrorCause is one error in an error's Unwrap chain
+type ErrorCause struct {
+	// Type is the concrete Go type of the error, e.g. "*fs.PathError"
+	Type string
+
+	// Message is the result of calling Error on the error
+	Message string
+}
+
+// ErrorInfo describes a Go error attached to a log entry
+type ErrorInfo struct {
+	// Message is the result of calling Error on the attached error
+	Message string
+
+	// Chain lists the attached error followed by everything errors.Unwrap returns
+	Chain []ErrorCause
+
+	// Stack is the stack trace recorded by the error, if it carries one
+	Stack []Frame
+}
+
+// stackTracer is implemented by errors that record the program counters of
+// the call site where they were created
+type stackTracer interface {
+	Callers() []uintptr
+}
+
+// NewErrorInfo captures the message, the unwrap chain and, if available, the
+// stack trace of an error. It returns nil for a nil error.
+func NewErrorInfo(err error) *ErrorInfo {
+	if err == nil {
+		return nil
+	}
+
+	info := &ErrorInfo{
+		Message: err.Error(),
+	}
+
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		info.Chain = append(info.Chain, ErrorCause{
+			Type:    fmt.Sprintf("%T", e),
+			Message: e.Error(),
+		})
+
+		// The innermost error carrying a stack is closest to the origin
+		if pcs := errorCallers(e); len(pcs) > 0 {
+			info.Stack = framesFromPCs(pcs)
+		}
+	}
+
+	return info
+}
+
+// errorCallers extracts program counters from errors that expose them either
+// through a Callers method or through a StackTrace method returning a slice
+// of uintptr-based frames, as github.com/pkg/errors does.
+func errorCallers(err error) []uintptr {
+	if tracer, ok := err.(stackTracer); ok {
+		return tracer.Callers()
+	}
+
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil
+	}
+
+	out := method.Type().Out(0)
+	if out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	trace := method.Call(nil)[0]
+	pcs := make([]uintptr, trace.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(trace.Index(i).Uint())
+	}
+
+	return pcs
+}
+
+// framesFromPCs resolves program counters returned by runtime.Callers into frames
+func framesFromPCs(pcs []uintptr) []Frame {
+	frames := runtime.CallersFrames(pcs)
+
+	var result []Frame
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" || frame.File != "" {
+			result = append(result, Frame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+			})
+		}
+		if !more {
+			break
+		}
+	}
+
+	return result
+}
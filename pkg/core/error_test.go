@@ -0,0 +1,109 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// tracedError records its call site the way stack-aware error packages do
+type tracedError struct {
+	msg string
+	pcs []uintptr
+}
+
+func newTracedError(msg string) *tracedError {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(2, pcs)
+	return &tracedError{msg: msg, pcs: pcs[:n]}
+}
+
+func (e *tracedError) Error() string      { return e.msg }
+func (e *tracedError) Callers() []uintptr { return e.pcs }
+
+// pkgErrorsFrame and pkgErrorsStack mirror the types used by github.com/pkg/errors
+type pkgErrorsFrame uintptr
+type pkgErrorsStack []pkgErrorsFrame
+
+type pkgError struct {
+	msg   string
+	stack pkgErrorsStack
+}
+
+func (e *pkgError) Error() string { return e.msg }
+
+func (e *pkgError) StackTrace() pkgErrorsStack { return e.stack }
+
+func TestNewErrorInfoNil(t *testing.T) {
+	if info := NewErrorInfo(nil); info != nil {
+		t.Errorf("NewErrorInfo(nil) = %+v, want nil", info)
+	}
+}
+
+func TestNewErrorInfoChain(t *testing.T) {
+	_, openErr := os.Open("/definitely/not/here")
+	err := fmt.Errorf("loading config: %w", openErr)
+
+	info := NewErrorInfo(err)
+
+	if info.Message != err.Error() {
+		t.Errorf("Message = %q, want %q", info.Message, err.Error())
+	}
+
+	wantTypes := []string{"*fmt.wrapError", "*fs.PathError", "syscall.Errno"}
+	if len(info.Chain) != len(wantTypes) {
+		t.Fatalf("Chain length = %d, want %d: %+v", len(info.Chain), len(wantTypes), info.Chain)
+	}
+	for i, want := range wantTypes {
+		if info.Chain[i].Type != want {
+			t.Errorf("Chain[%d].Type = %q, want %q", i, info.Chain[i].Type, want)
+		}
+	}
+
+	var pathErr *fs.PathError
+	errors.As(err, &pathErr)
+	if info.Chain[1].Message != pathErr.Error() {
+		t.Errorf("Chain[1].Message = %q, want %q", info.Chain[1].Message, pathErr.Error())
+	}
+
+	if len(info.Stack) != 0 {
+		t.Errorf("Expected no stack for plain errors, got %d frames", len(info.Stack))
+	}
+}
+
+func TestNewErrorInfoStack(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"callers method", fmt.Errorf("wrapped: %w", newTracedError("traced"))},
+		{"pkg/errors style", func() error {
+			traced := newTracedError("traced")
+			stack := make(pkgErrorsStack, len(traced.pcs))
+			for i, pc := range traced.pcs {
+				stack[i] = pkgErrorsFrame(pc)
+			}
+			return &pkgError{msg: "traced", stack: stack}
+		}()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info := NewErrorInfo(test.err)
+
+			if len(info.Stack) == 0 {
+				t.Fatal("Expected stack frames")
+			}
+			if !strings.Contains(info.Stack[0].Function, "TestNewErrorInfoStack") {
+				t.Errorf("Stack[0].Function = %q, want test function", info.Stack[0].Function)
+			}
+			if !strings.HasSuffix(info.Stack[0].File, "error_test.go") || info.Stack[0].Line == 0 {
+				t.Errorf("Stack[0] = %v, want location in error_test.go", info.Stack[0])
+			}
+		})
+	}
+}
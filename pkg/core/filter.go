@@ -0,0 +1,113 @@
+package core
+
+// LevelFilter decides which levels a driver accepts. A filter combines an
+// optional minimum and maximum level, an optional set of accepted levels or
+// ranges, and a set of excluded levels. A nil filter accepts every level.
+type LevelFilter struct {
+	min     Level
+	max     Level
+	hasMin  bool
+	hasMax  bool
+	ranges  []levelRange
+	exclude map[Level]bool
+}
+
+// levelRange is an inclusive range of levels
+type levelRange struct {
+	from Level
+	to   Level
+}
+
+// LevelFilterOption represents an option for a level filter
+type LevelFilterOption func(*LevelFilter)
+
+// MinLevel rejects levels below the given level
+func MinLevel(level Level) LevelFilterOption {
+	return func(f *LevelFilter) {
+		f.min = level
+		f.hasMin = true
+	}
+}
+
+// MaxLevel rejects levels above the given level
+func MaxLevel(level Level) LevelFilterOption {
+	return func(f *LevelFilter) {
+		f.max = level
+		f.hasMax = true
+	}
+}
+
+// OnlyLevels accepts only the given levels. It can be combined with
+// LevelRange; a level is accepted if it matches any of them.
+func OnlyLevels(levels ...Level) LevelFilterOption {
+	return func(f *LevelFilter) {
+		for _, level := range levels {
+			f.ranges = append(f.ranges, levelRange{from: level, to: level})
+		}
+	}
+}
+
+// LevelRange accepts only levels between from and to, inclusive
+func LevelRange(from, to Level) LevelFilterOption {
+	return func(f *LevelFilter) {
+		if from > to {
+			from, to = to, from
+		}
+		f.ranges = append(f.ranges, levelRange{from: from, to: to})
+	}
+}
+
+// ExcludeLevels rejects the given levels even if other rules accept them
+func ExcludeLevels(levels ...Level) LevelFilterOption {
+	return func(f *LevelFilter) {
+		if f.exclude == nil {
+			f.exclude = make(map[Level]bool)
+		}
+		for _, level := range levels {
+			f.exclude[level] = true
+		}
+	}
+}
+
+// NewLevelFilter creates a level filter from options. Without options the
+// filter accepts every level.
+func NewLevelFilter(options ...LevelFilterOption) *LevelFilter {
+	filter := &LevelFilter{}
+
+	for _, option := range options {
+		option(filter)
+	}
+
+	return filter
+}
+
+// Allows reports whether an entry at the given level passes the filter
+func (f *LevelFilter) Allows(level Level) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.hasMin && level < f.min {
+		return false
+	}
+
+	if f.hasMax && level > f.max {
+		return false
+	}
+
+	if f.exclude[level] {
+		return false
+	}
+
+	if len(f.ranges) == 0 {
+		return true
+	}
+
+	for _, r := range f.ranges {
+		if level >= r.from && level <= r.to {
+			return true
+		}
+	}
+
+	return false
+}
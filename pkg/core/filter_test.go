@@ -0,0 +1,76 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestLevelFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   *LevelFilter
+		accepted []Level
+		rejected []Level
+	}{
+		{
+			name:     "nil filter",
+			filter:   nil,
+			accepted: []Level{Trace, Debug, Error, Panic},
+		},
+		{
+			name:     "no options",
+			filter:   NewLevelFilter(),
+			accepted: []Level{Trace, Info, Panic},
+		},
+		{
+			name:     "min level",
+			filter:   NewLevelFilter(MinLevel(Warning)),
+			accepted: []Level{Warning, Error, Panic},
+			rejected: []Level{Trace, Info, Notice},
+		},
+		{
+			name:     "max level",
+			filter:   NewLevelFilter(MaxLevel(Info)),
+			accepted: []Level{Trace, Debug, Info},
+			rejected: []Level{Notice, Warning, Error},
+		},
+		{
+			name:     "exact level",
+			filter:   NewLevelFilter(OnlyLevels(Warning)),
+			accepted: []Level{Warning},
+			rejected: []Level{Info, Error},
+		},
+		{
+			name:     "range",
+			filter:   NewLevelFilter(LevelRange(Info, Debug)),
+			accepted: []Level{Debug, Info, Level(15)},
+			rejected: []Level{Trace, Notice},
+		},
+		{
+			name:     "range and exact level",
+			filter:   NewLevelFilter(LevelRange(Debug, Info), OnlyLevels(Critical)),
+			accepted: []Level{Debug, Info, Critical},
+			rejected: []Level{Warning, Error},
+		},
+		{
+			name:     "exclusion",
+			filter:   NewLevelFilter(MinLevel(Info), ExcludeLevels(Notice, Warning)),
+			accepted: []Level{Info, Error},
+			rejected: []Level{Debug, Notice, Warning},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, level := range test.accepted {
+				if !test.filter.Allows(level) {
+					t.Errorf("Allows(%v) = false, want true", level)
+				}
+			}
+			for _, level := range test.rejected {
+				if test.filter.Allows(level) {
+					t.Errorf("Allows(%v) = true, want false", level)
+				}
+			}
+		})
+	}
+}
@@ -2,51 +2,222 @@ package core
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 )
 
 // Level represents the severity level of a log message
 type Level int
 
+// The built-in levels are spaced apart so that custom levels registered
+// with RegisterLevel can sort between them. Before Trace, Notice, Critical,
+// Fatal and Panic were added, Debug, Info, Warning and Error were 0 to 3;
+// levels stored as numbers with those values must be converted.
 const (
+	// Trace is used for very fine-grained diagnostic messages
+	Trace Level = 0
 	// Debug is used for development-time messages and debugging
-	Debug Level = iota
+	Debug Level = 10
 	// Info is used for general information about system operation
-	Info
+	Info Level = 20
+	// Notice is used for normal but significant events
+	Notice Level = 30
 	// Warning is used for non-critical issues that might need attention
-	Warning
+	Warning Level = 40
 	// Error is used for error conditions
-	Error
+	Error Level = 50
+	// Critical is used for conditions that require immediate attention
+	Critical Level = 60
+	// Fatal is used for errors after which the process cannot continue.
+	// Logging at this level closes all drivers and exits the process.
+	Fatal Level = 70
+	// Panic is used for unrecoverable programming errors.
+	// Logging at this level closes all drivers and then panics.
+	Panic Level = 80
 )
 
+// LevelDefinition describes a level known to the level registry
+type LevelDefinition struct {
+	// Level is the numeric value that determines how the level sorts
+	Level Level
+
+	// Name is the canonical name returned by Level.String
+	Name string
+
+	// Aliases are additional names accepted by ParseLevel
+	Aliases []string
+
+	// Color holds the ANSI SGR parameters used by the console driver, e.g. "35" or "1;33"
+	Color string
+}
+
+// levelRegistry holds the built-in and user-defined levels
+var levelRegistry = struct {
+	sync.RWMutex
+	byLevel map[Level]LevelDefinition
+	byName  map[string]Level
+}{
+	byLevel: make(map[Level]LevelDefinition),
+	byName:  make(map[string]Level),
+}
+
+func init() {
+	builtin := []LevelDefinition{
+		{Level: Trace, Name: "TRACE", Color: "90"},
+		{Level: Debug, Name: "DEBUG", Color: "34"},
+		{Level: Info, Name: "INFO", Color: "32"},
+		{Level: Notice, Name: "NOTICE", Color: "36"},
+		{Level: Warning, Name: "WARNING", Aliases: []string{"WARN"}, Color: "33"},
+		{Level: Error, Name: "ERROR", Aliases: []string{"ERR"}, Color: "31"},
+		{Level: Critical, Name: "CRITICAL", Aliases: []string{"CRIT"}, Color: "35"},
+		{Level: Fatal, Name: "FATAL", Color: "1;31"},
+		{Level: Panic, Name: "PANIC", Color: "1;97;41"},
+	}
+
+	for _, def := range builtin {
+		if err := RegisterLevel(def); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterLevel adds a level to the registry so that it is recognised by
+// Level.String, ParseLevel and the console driver. Registering an identical
+// definition twice is a no-op; reusing a value or name is an error, as is
+// registering a value again with different aliases or color.
+func RegisterLevel(def LevelDefinition) error {
+	if strings.TrimSpace(def.Name) == "" {
+		return fmt.Errorf("%w: level name is required", ErrInvalidLevel)
+	}
+
+	names := append([]string{def.Name}, def.Aliases...)
+
+	levelRegistry.Lock()
+	defer levelRegistry.Unlock()
+
+	if existing, ok := levelRegistry.byLevel[def.Level]; ok {
+		if existing.Name != def.Name {
+			return fmt.Errorf("%w: value %d is used by %s", ErrLevelExists, def.Level, existing.Name)
+		}
+		if !sameAliases(existing.Aliases, def.Aliases) || existing.Color != def.Color {
+			return fmt.Errorf("%w: %s is registered with different aliases or color", ErrLevelExists, def.Name)
+		}
+		return nil
+	}
+
+	for _, name := range names {
+		if level, ok := levelRegistry.byName[strings.ToUpper(name)]; ok {
+			return fmt.Errorf("%w: name %s is used by level %d", ErrLevelExists, name, level)
+		}
+	}
+
+	def.Aliases = append([]string(nil), def.Aliases...)
+	levelRegistry.byLevel[def.Level] = def
+	for _, name := range names {
+		levelRegistry.byName[strings.ToUpper(name)] = def.Level
+	}
+
+	return nil
+}
+
+// sameAliases reports whether two alias lists hold the same names, ignoring
+// order and case as ParseLevel does
+func sameAliases(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	normalize := func(aliases []string) []string {
+		names := make([]string, len(aliases))
+		for i, alias := range aliases {
+			names[i] = strings.ToUpper(alias)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	a, b = normalize(a), normalize(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Levels returns the definitions of all registered levels ordered by severity
+func Levels() []LevelDefinition {
+	levelRegistry.RLock()
+	defer levelRegistry.RUnlock()
+
+	defs := make([]LevelDefinition, 0, len(levelRegistry.byLevel))
+	for _, def := range levelRegistry.byLevel {
+		defs = append(defs, def)
+	}
+
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Level < defs[j].Level
+	})
+
+	return defs
+}
+
 // String returns the string representation of the log level
 func (l Level) String() string {
-	switch l {
-	case Debug:
-		return "DEBUG"
-	case Info:
-		return "INFO"
-	case Warning:
-		return "WARNING"
-	case Error:
-		return "ERROR"
-	default:
+	levelRegistry.RLock()
+	def, ok := levelRegistry.byLevel[l]
+	levelRegistry.RUnlock()
+
+	if !ok {
 		return fmt.Sprintf("UNKNOWN(%d)", l)
 	}
+
+	return def.Name
+}
+
+// Color returns the ANSI SGR parameters registered for the level, or an
+// empty string if the level has no color
+func (l Level) Color() string {
+	levelRegistry.RLock()
+	defer levelRegistry.RUnlock()
+
+	return levelRegistry.byLevel[l].Color
+}
+
+// SyslogSeverity returns the RFC 5424 severity (0-7) that corresponds to the
+// level. Custom levels take the severity of the built-in level below them.
+func (l Level) SyslogSeverity() int {
+	switch {
+	case l >= Panic:
+		return 0 // Emergency
+	case l >= Fatal:
+		return 1 // Alert
+	case l >= Critical:
+		return 2 // Critical
+	case l >= Error:
+		return 3 // Error
+	case l >= Warning:
+		return 4 // Warning
+	case l >= Notice:
+		return 5 // Notice
+	case l >= Info:
+		return 6 // Informational
+	default:
+		return 7 // Debug
+	}
 }
 
 // ParseLevel converts a string to a Level
 func ParseLevel(levelStr string) (Level, error) {
-	switch strings.ToUpper(levelStr) {
-	case "DEBUG":
-		return Debug, nil
-	case "INFO":
-		return Info, nil
-	case "WARNING", "WARN":
-		return Warning, nil
-	case "ERROR", "ERR":
-		return Error, nil
-	default:
+	levelRegistry.RLock()
+	level, ok := levelRegistry.byName[strings.ToUpper(levelStr)]
+	levelRegistry.RUnlock()
+
+	if !ok {
 		return Info, fmt.Errorf("unknown log level: %s", levelStr)
 	}
+
+	return level, nil
 }
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -9,10 +10,15 @@ func TestLevelString(t *testing.T) {
 		level    Level
 		expected string
 	}{
+		{Trace, "TRACE"},
 		{Debug, "DEBUG"},
 		{Info, "INFO"},
+		{Notice, "NOTICE"},
 		{Warning, "WARNING"},
 		{Error, "ERROR"},
+		{Critical, "CRITICAL"},
+		{Fatal, "FATAL"},
+		{Panic, "PANIC"},
 		{Level(99), "UNKNOWN(99)"},
 	}
 
@@ -31,10 +37,14 @@ func TestParseLevel(t *testing.T) {
 		expected Level
 		wantErr  bool
 	}{
+		{"TRACE", Trace, false},
+		{"trace", Trace, false},
 		{"DEBUG", Debug, false},
 		{"debug", Debug, false},
 		{"INFO", Info, false},
 		{"info", Info, false},
+		{"NOTICE", Notice, false},
+		{"notice", Notice, false},
 		{"WARNING", Warning, false},
 		{"warning", Warning, false},
 		{"WARN", Warning, false},
@@ -43,6 +53,12 @@ func TestParseLevel(t *testing.T) {
 		{"error", Error, false},
 		{"ERR", Error, false},
 		{"err", Error, false},
+		{"CRITICAL", Critical, false},
+		{"crit", Critical, false},
+		{"FATAL", Fatal, false},
+		{"fatal", Fatal, false},
+		{"PANIC", Panic, false},
+		{"panic", Panic, false},
 		{"invalid", Info, true},
 		{"", Info, true},
 	}
@@ -64,3 +80,127 @@ func TestParseLevel(t *testing.T) {
 		})
 	}
 }
+
+func TestLevelOrdering(t *testing.T) {
+	levels := []Level{Trace, Debug, Info, Notice, Warning, Error, Critical, Fatal, Panic}
+
+	for i := 1; i < len(levels); i++ {
+		if levels[i-1] >= levels[i] {
+			t.Errorf("%v should sort below %v", levels[i-1], levels[i])
+		}
+	}
+}
+
+func TestLevelSyslogSeverity(t *testing.T) {
+	tests := []struct {
+		level    Level
+		expected int
+	}{
+		{Trace, 7},
+		{Debug, 7},
+		{Info, 6},
+		{Notice, 5},
+		{Warning, 4},
+		{Error, 3},
+		{Critical, 2},
+		{Fatal, 1},
+		{Panic, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.level.String(), func(t *testing.T) {
+			if got := test.level.SyslogSeverity(); got != test.expected {
+				t.Errorf("Level.SyslogSeverity() = %d, want %d", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	audit := Level(45)
+	err := RegisterLevel(LevelDefinition{
+		Level:   audit,
+		Name:    "AUDIT",
+		Aliases: []string{"AUD"},
+		Color:   "1;35",
+	})
+	if err != nil {
+		t.Fatalf("RegisterLevel() error = %v", err)
+	}
+
+	if got := audit.String(); got != "AUDIT" {
+		t.Errorf("Level.String() = %q, want %q", got, "AUDIT")
+	}
+	if got := audit.Color(); got != "1;35" {
+		t.Errorf("Level.Color() = %q, want %q", got, "1;35")
+	}
+	if got := audit.SyslogSeverity(); got != Warning.SyslogSeverity() {
+		t.Errorf("Level.SyslogSeverity() = %d, want %d", got, Warning.SyslogSeverity())
+	}
+	if !(audit > Warning && audit < Error) {
+		t.Errorf("AUDIT should sort between WARNING and ERROR")
+	}
+
+	for _, name := range []string{"AUDIT", "audit", "aud"} {
+		got, err := ParseLevel(name)
+		if err != nil || got != audit {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", name, got, err, audit)
+		}
+	}
+
+	// Registering the same definition again is a no-op
+	if err := RegisterLevel(LevelDefinition{Level: audit, Name: "AUDIT", Aliases: []string{"aud"}, Color: "1;35"}); err != nil {
+		t.Errorf("RegisterLevel() of identical level error = %v", err)
+	}
+
+	// A conflicting definition of the same level is rejected
+	conflicts := []LevelDefinition{
+		{Level: audit, Name: "AUDIT", Color: "1;35"},
+		{Level: audit, Name: "AUDIT", Aliases: []string{"AUD", "A"}, Color: "1;35"},
+		{Level: audit, Name: "AUDIT", Aliases: []string{"AUD"}, Color: "31"},
+	}
+	for _, def := range conflicts {
+		if err := RegisterLevel(def); !errors.Is(err, ErrLevelExists) {
+			t.Errorf("RegisterLevel(%+v) error = %v, want %v", def, err, ErrLevelExists)
+		}
+	}
+	if got := audit.Color(); got != "1;35" {
+		t.Errorf("Level.Color() after conflict = %q, want %q", got, "1;35")
+	}
+
+	found := false
+	for _, def := range Levels() {
+		if def.Level == audit {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Levels() does not include registered level")
+	}
+}
+
+func TestRegisterLevelConflicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		def     LevelDefinition
+		wantErr error
+	}{
+		{"empty name", LevelDefinition{Level: 46, Name: ""}, ErrInvalidLevel},
+		{"existing value", LevelDefinition{Level: Error, Name: "OOPS"}, ErrLevelExists},
+		{"existing name", LevelDefinition{Level: 47, Name: "info"}, ErrLevelExists},
+		{"existing alias", LevelDefinition{Level: 48, Name: "SECURITY", Aliases: []string{"WARN"}}, ErrLevelExists},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := RegisterLevel(test.def)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("RegisterLevel() error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+
+	if got := Level(48).String(); got != "UNKNOWN(48)" {
+		t.Errorf("Rejected level was registered as %q", got)
+	}
+}
@@ -2,13 +2,17 @@ package core
 
 import (
 	"errors"
+	"fmt"
+	"os"
 	"time"
 )
 
 // Errors
 var (
-	ErrDriverNotFound = errors.New("driver not found")
-	ErrInvalidLevel   = errors.New("invalid log level")
+	ErrDriverNotFound    = errors.New("driver not found")
+	ErrProcessorNotFound = errors.New("processor not found")
+	ErrInvalidLevel      = errors.New("invalid log level")
+	ErrLevelExists       = errors.New("log level already registered")
 )
 
 // Attributes represents additional metadata for log entries
@@ -30,6 +34,15 @@ type LogEntry struct {
 
 	// TransactionID is an optional identifier for grouping related logs
 	TransactionID string
+
+	// Error describes the Go error attached with Err, if any
+	Error *ErrorInfo
+
+	// Caller is the call site of the log call, recorded when AddCaller is enabled
+	Caller *Frame
+
+	// Stack is the goroutine stack at the log call, recorded when AddStacktrace is enabled
+	Stack []Frame
 }
 
 // Driver defines the interface for log drivers
@@ -42,9 +55,28 @@ type Driver interface {
 	Close() error
 }
 
+// LevelEnabler is implemented by drivers that can tell in advance whether
+// they would accept an entry at a level. Drivers that do not implement it
+// are assumed to accept every level.
+type LevelEnabler interface {
+	// Enabled reports whether the driver would accept an entry at the level
+	Enabled(level Level) bool
+}
+
+// exit terminates the process after a Fatal entry; replaced in tests
+var exit = os.Exit
+
 // logger implements the Logger interface
 type logger struct {
-	drivers []Driver
+	drivers         []Driver
+	addCaller       bool
+	callerSkip      int
+	callerPaths     callerPathTrimmer
+	addStacktrace   bool
+	stacktraceLevel Level
+	stacktraceDepth int
+	processors      []Processor
+	errorHandler    ErrorHandler
 }
 
 // NewLogger creates a new logger with the specified drivers
@@ -54,46 +86,237 @@ func NewLogger(drivers ...Driver) *logger {
 	}
 }
 
+// NewLoggerWithOptions creates a new logger with options
+func NewLoggerWithOptions(options ...LoggerOption) *logger {
+	l := &logger{
+		stacktraceDepth: defaultStacktraceDepth,
+	}
+
+	for _, option := range options {
+		option(l)
+	}
+
+	return l
+}
+
+// Trace logs a message at Trace level
+func (l *logger) Trace(msg string, attrs ...Attributes) error {
+	return l.log(Trace, "", msg, nil, attrs)
+}
+
 // Debug logs a message at Debug level
 func (l *logger) Debug(msg string, attrs ...Attributes) error {
-	return l.Log(Debug, msg, attrs...)
+	return l.log(Debug, "", msg, nil, attrs)
 }
 
 // Info logs a message at Info level
 func (l *logger) Info(msg string, attrs ...Attributes) error {
-	return l.Log(Info, msg, attrs...)
+	return l.log(Info, "", msg, nil, attrs)
+}
+
+// Notice logs a message at Notice level
+func (l *logger) Notice(msg string, attrs ...Attributes) error {
+	return l.log(Notice, "", msg, nil, attrs)
 }
 
 // Warning logs a message at Warning level
 func (l *logger) Warning(msg string, attrs ...Attributes) error {
-	return l.Log(Warning, msg, attrs...)
+	return l.log(Warning, "", msg, nil, attrs)
 }
 
 // Error logs a message at Error level
 func (l *logger) Error(msg string, attrs ...Attributes) error {
-	return l.Log(Error, msg, attrs...)
+	return l.log(Error, "", msg, nil, attrs)
+}
+
+// Critical logs a message at Critical level
+func (l *logger) Critical(msg string, attrs ...Attributes) error {
+	return l.log(Critical, "", msg, nil, attrs)
+}
+
+// Fatal logs a message at Fatal level, closes all drivers and exits the process
+func (l *logger) Fatal(msg string, attrs ...Attributes) error {
+	return l.log(Fatal, "", msg, nil, attrs)
+}
+
+// Panic logs a message at Panic level, closes all drivers and panics
+func (l *logger) Panic(msg string, attrs ...Attributes) error {
+	return l.log(Panic, "", msg, nil, attrs)
+}
+
+// Err logs a message at Error level with the error's message, unwrap chain
+// and stack trace attached. If msg is empty the error message is used.
+func (l *logger) Err(err error, msg string, attrs ...Attributes) error {
+	return l.log(Error, "", msg, err, attrs)
 }
 
 // Log logs a message at the specified level
 func (l *logger) Log(level Level, msg string, attrs ...Attributes) error {
+	return l.log(level, "", msg, nil, attrs)
+}
+
+// Tracef logs a formatted message at Trace level
+func (l *logger) Tracef(format string, args ...interface{}) error {
+	if !l.wants(Trace) {
+		return nil
+	}
+	return l.log(Trace, "", fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Debugf logs a formatted message at Debug level
+func (l *logger) Debugf(format string, args ...interface{}) error {
+	if !l.wants(Debug) {
+		return nil
+	}
+	return l.log(Debug, "", fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Infof logs a formatted message at Info level
+func (l *logger) Infof(format string, args ...interface{}) error {
+	if !l.wants(Info) {
+		return nil
+	}
+	return l.log(Info, "", fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Noticef logs a formatted message at Notice level
+func (l *logger) Noticef(format string, args ...interface{}) error {
+	if !l.wants(Notice) {
+		return nil
+	}
+	return l.log(Notice, "", fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Warningf logs a formatted message at Warning level
+func (l *logger) Warningf(format string, args ...interface{}) error {
+	if !l.wants(Warning) {
+		return nil
+	}
+	return l.log(Warning, "", fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Errorf logs a formatted message at Error level
+func (l *logger) Errorf(format string, args ...interface{}) error {
+	if !l.wants(Error) {
+		return nil
+	}
+	return l.log(Error, "", fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Criticalf logs a formatted message at Critical level
+func (l *logger) Criticalf(format string, args ...interface{}) error {
+	if !l.wants(Critical) {
+		return nil
+	}
+	return l.log(Critical, "", fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Fatalf logs a formatted message at Fatal level, closes all drivers and exits the process
+func (l *logger) Fatalf(format string, args ...interface{}) error {
+	if !l.wants(Fatal) {
+		return nil
+	}
+	return l.log(Fatal, "", fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Panicf logs a formatted message at Panic level, closes all drivers and panics
+func (l *logger) Panicf(format string, args ...interface{}) error {
+	if !l.wants(Panic) {
+		return nil
+	}
+	return l.log(Panic, "", fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Logf logs a formatted message at the specified level. The message is only
+// formatted if a driver accepts the level.
+func (l *logger) Logf(level Level, format string, args ...interface{}) error {
+	if !l.wants(level) {
+		return nil
+	}
+	return l.log(level, "", fmt.Sprintf(format, args...), nil, nil)
+}
+
+// LogFn logs the message returned by fn at the specified level. fn is only
+// called if a driver accepts the level.
+func (l *logger) LogFn(level Level, fn func() string, attrs ...Attributes) error {
+	if !l.wants(level) {
+		return nil
+	}
+	return l.log(level, "", fn(), nil, attrs)
+}
+
+// Enabled reports whether any driver would accept an entry at the level
+func (l *logger) Enabled(level Level) bool {
+	for _, driver := range l.drivers {
+		enabler, ok := driver.(LevelEnabler)
+		if !ok || enabler.Enabled(level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// wants reports whether a message at the level needs to be built. Fatal and
+// Panic messages are always built because they terminate the process.
+func (l *logger) wants(level Level) bool {
+	return level >= Fatal || l.Enabled(level)
+}
+
+// log builds an entry and dispatches it. Every exported logging method of
+// the logger and of transactions calls log directly, so the user's call site
+// is always callerDepth frames above it.
+func (l *logger) log(level Level, txID string, msg string, err error, attrs []Attributes) error {
 	entry := &LogEntry{
-		Timestamp: time.Now(),
-		Level:     level,
-		Message:   msg,
+		Timestamp:     time.Now(),
+		Level:         level,
+		Message:       msg,
+		TransactionID: txID,
+		Error:         NewErrorInfo(err),
+	}
+
+	if msg == "" && err != nil {
+		entry.Message = err.Error()
 	}
 
 	if len(attrs) > 0 {
 		entry.Attrs = attrs[0]
 	}
 
-	var lastErr error
-	for _, driver := range l.drivers {
-		if err := driver.Log(entry); err != nil {
-			lastErr = err
+	if l.addCaller {
+		entry.Caller = l.caller(callerDepth + l.callerSkip)
+	}
+
+	if l.addStacktrace && level >= l.stacktraceLevel {
+		entry.Stack = l.stacktrace(callerDepth + l.callerSkip)
+	}
+
+	return l.dispatch(entry)
+}
+
+// dispatch runs the processors and sends the entry to every driver. If any
+// driver fails, the returned *MultiError lists each failure. Fatal and Panic
+// entries additionally close the drivers and terminate the process, even if
+// a processor dropped them.
+func (l *logger) dispatch(entry *LogEntry) error {
+	var errs *MultiError
+	if l.process(entry) {
+		for i, driver := range l.drivers {
+			errs = addDriverError(errs, i, driver, driver.Log(entry))
 		}
 	}
+	err := l.handleError(errs, entry)
 
-	return lastErr
+	switch entry.Level {
+	case Fatal:
+		l.Close()
+		exit(1)
+	case Panic:
+		l.Close()
+		panic(entry.Message)
+	}
+
+	return err
 }
 
 // NewTransaction creates a new transaction with the specified ID
@@ -101,14 +324,27 @@ func (l *logger) NewTransaction(txID string) Transaction {
 	return newTransaction(txID, l)
 }
 
-// Close closes all drivers
+// Close closes all drivers. If any driver fails, the returned *MultiError
+// lists each failure.
 func (l *logger) Close() error {
-	var lastErr error
-	for _, driver := range l.drivers {
-		if err := driver.Close(); err != nil {
-			lastErr = err
-		}
+	var errs *MultiError
+	for i, driver := range l.drivers {
+		errs = addDriverError(errs, i, driver, driver.Close())
+	}
+
+	return l.handleError(errs, nil)
+}
+
+// handleError passes driver failures to the error handler and returns them.
+// It returns a nil error, not a nil *MultiError, when no driver failed.
+func (l *logger) handleError(errs *MultiError, entry *LogEntry) error {
+	if errs == nil {
+		return nil
+	}
+
+	if l.errorHandler != nil {
+		l.errorHandler(errs, entry)
 	}
 
-	return lastErr
+	return errs
 }
@@ -2,6 +2,8 @@ package core
 
 import (
 	"errors"
+	"fmt"
+	"os"
 	"testing"
 )
 
@@ -40,10 +42,13 @@ func TestLoggerLevels(t *testing.T) {
 		logFunc  func(msg string, attrs ...Attributes) error
 		expected Level
 	}{
+		{"Trace", logger.Trace, Trace},
 		{"Debug", logger.Debug, Debug},
 		{"Info", logger.Info, Info},
+		{"Notice", logger.Notice, Notice},
 		{"Warning", logger.Warning, Warning},
 		{"Error", logger.Error, Error},
+		{"Critical", logger.Critical, Critical},
 	}
 
 	for i, test := range tests {
@@ -170,3 +175,210 @@ func TestLoggerClose(t *testing.T) {
 		t.Errorf("Second driver not closed")
 	}
 }
+
+func TestLoggerFatal(t *testing.T) {
+	mockDriver := &MockDriver{}
+	logger := NewLogger(mockDriver)
+
+	exitCode := -1
+	exit = func(code int) { exitCode = code }
+	defer func() { exit = os.Exit }()
+
+	logger.Fatal("fatal message")
+
+	if len(mockDriver.Logs) != 1 || mockDriver.Logs[0].Level != Fatal {
+		t.Fatalf("Expected one Fatal log, got %+v", mockDriver.Logs)
+	}
+	if !mockDriver.Closed {
+		t.Error("Driver not closed before exit")
+	}
+	if exitCode != 1 {
+		t.Errorf("Exit code = %d, want 1", exitCode)
+	}
+}
+
+func TestLoggerPanic(t *testing.T) {
+	mockDriver := &MockDriver{}
+	logger := NewLogger(mockDriver)
+
+	defer func() {
+		r := recover()
+		if r != "panic message" {
+			t.Errorf("Recovered %v, want %q", r, "panic message")
+		}
+		if len(mockDriver.Logs) != 1 || mockDriver.Logs[0].Level != Panic {
+			t.Errorf("Expected one Panic log, got %+v", mockDriver.Logs)
+		}
+		if !mockDriver.Closed {
+			t.Error("Driver not closed before panic")
+		}
+	}()
+
+	logger.Panic("panic message")
+	t.Error("Panic() returned without panicking")
+}
+
+func TestLoggerErr(t *testing.T) {
+	mockDriver := &MockDriver{}
+	logger := NewLogger(mockDriver)
+
+	cause := errors.New("connection refused")
+	err := fmt.Errorf("query failed: %w", cause)
+
+	if logErr := logger.Err(err, "failed to load user", Attributes{"user": "42"}); logErr != nil {
+		t.Errorf("logger.Err() error = %v", logErr)
+	}
+	if logErr := logger.Err(cause, ""); logErr != nil {
+		t.Errorf("logger.Err() error = %v", logErr)
+	}
+
+	if len(mockDriver.Logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %d", len(mockDriver.Logs))
+	}
+
+	log := mockDriver.Logs[0]
+	if log.Level != Error || log.Message != "failed to load user" || log.Attrs["user"] != "42" {
+		t.Errorf("Log incorrect: %+v", log)
+	}
+	if log.Error == nil || log.Error.Message != err.Error() || len(log.Error.Chain) != 2 {
+		t.Errorf("Log error incorrect: %+v", log.Error)
+	}
+
+	// An empty message falls back to the error message
+	if mockDriver.Logs[1].Message != "connection refused" {
+		t.Errorf("Log message = %q, want %q", mockDriver.Logs[1].Message, "connection refused")
+	}
+}
+
+// filteringDriver is a mock driver that only accepts levels at or above min
+type filteringDriver struct {
+	MockDriver
+	min Level
+}
+
+func (d *filteringDriver) Log(entry *LogEntry) error {
+	if entry.Level < d.min {
+		return nil
+	}
+	return d.MockDriver.Log(entry)
+}
+
+func (d *filteringDriver) Enabled(level Level) bool {
+	return level >= d.min
+}
+
+func TestLoggerFormatted(t *testing.T) {
+	mockDriver := &MockDriver{}
+	logger := NewLogger(mockDriver)
+
+	tests := []struct {
+		name     string
+		logFunc  func(format string, args ...interface{}) error
+		expected Level
+	}{
+		{"Tracef", logger.Tracef, Trace},
+		{"Debugf", logger.Debugf, Debug},
+		{"Infof", logger.Infof, Info},
+		{"Noticef", logger.Noticef, Notice},
+		{"Warningf", logger.Warningf, Warning},
+		{"Errorf", logger.Errorf, Error},
+		{"Criticalf", logger.Criticalf, Critical},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.logFunc("%s #%d", "message", i); err != nil {
+				t.Errorf("logger.%s() error = %v", test.name, err)
+			}
+
+			log := mockDriver.Logs[i]
+			if log.Level != test.expected {
+				t.Errorf("Log level = %v, want %v", log.Level, test.expected)
+			}
+			if want := fmt.Sprintf("message #%d", i); log.Message != want {
+				t.Errorf("Log message = %q, want %q", log.Message, want)
+			}
+		})
+	}
+}
+
+func TestLoggerEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		drivers  []Driver
+		level    Level
+		expected bool
+	}{
+		{"no drivers", nil, Error, false},
+		{"driver without enabler", []Driver{&MockDriver{}}, Trace, true},
+		{"filtered out", []Driver{&filteringDriver{min: Warning}}, Info, false},
+		{"accepted", []Driver{&filteringDriver{min: Warning}}, Error, true},
+		{"any driver accepts", []Driver{&filteringDriver{min: Error}, &filteringDriver{min: Debug}}, Info, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logger := NewLogger(test.drivers...)
+			if got := logger.Enabled(test.level); got != test.expected {
+				t.Errorf("Enabled(%v) = %v, want %v", test.level, got, test.expected)
+			}
+			if got := logger.NewTransaction("tx").Enabled(test.level); got != test.expected {
+				t.Errorf("Transaction.Enabled(%v) = %v, want %v", test.level, got, test.expected)
+			}
+		})
+	}
+}
+
+func TestLoggerLazyMessages(t *testing.T) {
+	driver := &filteringDriver{min: Info}
+	logger := NewLogger(driver)
+
+	calls := 0
+	expensive := func() string {
+		calls++
+		return "expensive"
+	}
+
+	logger.LogFn(Debug, expensive)
+	logger.Logf(Debug, "%s", stringerFunc(expensive))
+	logger.Debugf("%s", stringerFunc(expensive))
+	logger.NewTransaction("tx").LogFn(Debug, expensive)
+
+	if calls != 0 {
+		t.Errorf("Message built %d times for filtered level", calls)
+	}
+
+	logger.LogFn(Info, expensive, Attributes{"key": "value"})
+	logger.NewTransaction("tx").Infof("%s", stringerFunc(expensive))
+
+	if calls != 2 {
+		t.Errorf("Message built %d times, want 2", calls)
+	}
+	if len(driver.Logs) != 2 || driver.Logs[0].Message != "expensive" || driver.Logs[0].Attrs["key"] != "value" {
+		t.Errorf("Logs incorrect: %+v", driver.Logs)
+	}
+	if driver.Logs[1].TransactionID != "tx" {
+		t.Errorf("Log transaction ID = %q, want %q", driver.Logs[1].TransactionID, "tx")
+	}
+}
+
+func TestLoggerFatalfAlwaysExits(t *testing.T) {
+	logger := NewLogger(&filteringDriver{min: Panic})
+
+	exited := false
+	exit = func(code int) { exited = true }
+	defer func() { exit = os.Exit }()
+
+	logger.Fatalf("shutting down: %s", "reason")
+
+	if !exited {
+		t.Error("Fatalf() did not exit when no driver accepts Fatal")
+	}
+}
+
+// stringerFunc calls a function when formatted with %s
+type stringerFunc func() string
+
+func (f stringerFunc) String() string {
+	return f()
+}
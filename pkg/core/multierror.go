@@ -0,0 +1,106 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Named is implemented by drivers that report a name for error messages.
+// Drivers that do not implement it are identified by their Go type.
+type Named interface {
+	// Name returns a short name for the driver, e.g. "console"
+	Name() string
+}
+
+// DriverError is the failure of a single driver
+type DriverError struct {
+	// Index is the position of the driver in the logger's driver list
+	Index int
+
+	// Name identifies the driver, see Named
+	Name string
+
+	// Err is the error returned by the driver
+	Err error
+}
+
+// Error returns the driver error prefixed with the driver's index and name
+func (e *DriverError) Error() string {
+	return fmt.Sprintf("driver %d (%s): %v", e.Index, e.Name, e.Err)
+}
+
+// Unwrap returns the error returned by the driver
+func (e *DriverError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError is returned when one or more drivers fail to log an entry or to
+// close. errors.Is and errors.As match against each driver's error.
+type MultiError struct {
+	Errors []*DriverError
+}
+
+// Error lists the failing drivers
+func (e *MultiError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d drivers failed: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// Is reports whether any driver error matches target
+func (e *MultiError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first driver error that matches target
+func (e *MultiError) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addDriverError records a failure of the driver at index. errs is only
+// allocated once a driver fails, so it stays nil when every driver succeeds.
+func addDriverError(errs *MultiError, index int, driver Driver, err error) *MultiError {
+	if err == nil {
+		return errs
+	}
+
+	if errs == nil {
+		errs = &MultiError{}
+	}
+	errs.Errors = append(errs.Errors, &DriverError{
+		Index: index,
+		Name:  DriverName(driver),
+		Err:   err,
+	})
+
+	return errs
+}
+
+// DriverName returns the driver's name if it implements Named and its Go
+// type otherwise
+func DriverName(driver Driver) string {
+	if named, ok := driver.(Named); ok {
+		return named.Name()
+	}
+
+	return fmt.Sprintf("%T", driver)
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+// failingDriver returns a fixed error from Log and Close
+type failingDriver struct {
+	name string
+	err  error
+}
+
+func (d *failingDriver) Log(entry *LogEntry) error { return d.err }
+func (d *failingDriver) Close() error              { return d.err }
+func (d *failingDriver) Name() string              { return d.name }
+
+func TestLoggerMultiError(t *testing.T) {
+	errTimeout := errors.New("timeout")
+	pathErr := &fs.PathError{Op: "write", Path: "/var/log/app.log", Err: errors.New("disk full")}
+
+	logger := NewLogger(
+		&failingDriver{name: "http", err: errTimeout},
+		&MockDriver{},
+		&failingDriver{name: "text_file", err: pathErr},
+	)
+
+	err := logger.Info("message")
+
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("Expected a *MultiError, got %T", err)
+	}
+	if len(multi.Errors) != 2 {
+		t.Fatalf("Expected 2 driver errors, got %d", len(multi.Errors))
+	}
+
+	first, second := multi.Errors[0], multi.Errors[1]
+	if first.Index != 0 || first.Name != "http" || second.Index != 2 || second.Name != "text_file" {
+		t.Errorf("Unexpected driver errors: %v, %v", first, second)
+	}
+
+	if !errors.Is(err, errTimeout) {
+		t.Error("Expected errors.Is to find the first driver's error")
+	}
+
+	var gotPathErr *fs.PathError
+	if !errors.As(err, &gotPathErr) || gotPathErr.Path != "/var/log/app.log" {
+		t.Error("Expected errors.As to find the third driver's error")
+	}
+
+	expected := "2 drivers failed: driver 0 (http): timeout; driver 2 (text_file): write /var/log/app.log: disk full"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestLoggerMultiErrorSingleDriver(t *testing.T) {
+	logger := NewLogger(&MockDriver{}, &MockDriver{ShouldError: true})
+
+	err := logger.Close()
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	// Drivers without a Name method are identified by their type
+	if !strings.HasPrefix(err.Error(), "driver 1 (*core.MockDriver): ") {
+		t.Errorf("Unexpected error message: %q", err.Error())
+	}
+}
+
+func TestLoggerNoErrorIsNil(t *testing.T) {
+	logger := NewLogger(&MockDriver{})
+
+	// A nil *MultiError must not leak into a non-nil error interface
+	if err := logger.Info("message"); err != nil {
+		t.Errorf("Expected nil error, got %v", err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Errorf("Expected nil error, got %v", err)
+	}
+}
+
+func TestLoggerErrorHandler(t *testing.T) {
+	var handled []error
+	var entries []*LogEntry
+
+	logger := NewLoggerWithOptions(
+		WithDrivers(&MockDriver{}, &MockDriver{ShouldError: true}),
+		WithErrorHandler(func(err error, entry *LogEntry) {
+			handled = append(handled, err)
+			entries = append(entries, entry)
+		}),
+	)
+
+	returned := logger.NewTransaction("tx-1").Warning("lost")
+	logger.Close()
+
+	if len(handled) != 2 {
+		t.Fatalf("Expected 2 handled errors, got %d", len(handled))
+	}
+	if handled[0] != returned {
+		t.Error("Expected the handler to receive the returned error")
+	}
+	if entries[0] == nil || entries[0].Message != "lost" || entries[0].TransactionID != "tx-1" {
+		t.Errorf("Expected the failed entry, got %+v", entries[0])
+	}
+	if entries[1] != nil {
+		t.Errorf("Expected no entry for Close, got %+v", entries[1])
+	}
+}
@@ -0,0 +1,79 @@
+package core
+
+// LoggerOption represents an option for the logger
+type LoggerOption func(*logger)
+
+// WithDrivers adds drivers that receive every log entry
+func WithDrivers(drivers ...Driver) LoggerOption {
+	return func(l *logger) {
+		l.drivers = append(l.drivers, drivers...)
+	}
+}
+
+// WithProcessors adds processors that run on every entry before the drivers
+func WithProcessors(processors ...Processor) LoggerOption {
+	return func(l *logger) {
+		l.processors = append(l.processors, processors...)
+	}
+}
+
+// AddCaller records the file, line and function of the log call on each entry
+func AddCaller() LoggerOption {
+	return func(l *logger) {
+		l.addCaller = true
+	}
+}
+
+// AddCallerSkip skips additional stack frames when recording the caller.
+// Helpers that wrap the logger should add one frame per wrapping layer.
+func AddCallerSkip(skip int) LoggerOption {
+	return func(l *logger) {
+		l.callerSkip += skip
+	}
+}
+
+// ShortCallerPaths shortens caller file paths to the last directory and file
+// name, e.g. "core/logger.go"
+func ShortCallerPaths() LoggerOption {
+	return func(l *logger) {
+		l.callerPaths.short = true
+	}
+}
+
+// TrimCallerPrefixes removes the first matching prefix from caller file paths,
+// e.g. the module root, leaving the path relative to it
+func TrimCallerPrefixes(prefixes ...string) LoggerOption {
+	return func(l *logger) {
+		l.callerPaths.prefixes = append(l.callerPaths.prefixes, prefixes...)
+	}
+}
+
+// AddStacktrace records the goroutine stack on entries at or above the given level
+func AddStacktrace(level Level) LoggerOption {
+	return func(l *logger) {
+		l.addStacktrace = true
+		l.stacktraceLevel = level
+	}
+}
+
+// WithStacktraceDepth limits the number of frames recorded by AddStacktrace
+func WithStacktraceDepth(depth int) LoggerOption {
+	return func(l *logger) {
+		if depth > 0 {
+			l.stacktraceDepth = depth
+		}
+	}
+}
+
+// ErrorHandler is called with the *MultiError of every log call or Close in
+// which a driver failed. entry is the entry that could not be logged, or nil
+// when closing.
+type ErrorHandler func(err error, entry *LogEntry)
+
+// WithErrorHandler sets a handler for driver failures, e.g. to count them or
+// to write the entry to a fallback sink. The errors are still returned.
+func WithErrorHandler(handler ErrorHandler) LoggerOption {
+	return func(l *logger) {
+		l.errorHandler = handler
+	}
+}
@@ -0,0 +1,39 @@
+package core
+
+// Processor enriches, mutates or drops log entries before they reach the
+// drivers. Processors run in order after the entry has been built.
+type Processor interface {
+	// Process modifies the entry in place and returns false to drop it
+	Process(entry *LogEntry) bool
+}
+
+// ProcessorFunc adapts an ordinary function to the Processor interface
+type ProcessorFunc func(entry *LogEntry) bool
+
+// Process calls f(entry)
+func (f ProcessorFunc) Process(entry *LogEntry) bool {
+	return f(entry)
+}
+
+// process runs the processor chain and reports whether the entry survived.
+// The attributes are copied first so that processors never modify the map
+// passed in by the caller.
+func (l *logger) process(entry *LogEntry) bool {
+	if len(l.processors) == 0 {
+		return true
+	}
+
+	attrs := make(Attributes, len(entry.Attrs))
+	for k, v := range entry.Attrs {
+		attrs[k] = v
+	}
+	entry.Attrs = attrs
+
+	for _, processor := range l.processors {
+		if !processor.Process(entry) {
+			return false
+		}
+	}
+
+	return true
+}
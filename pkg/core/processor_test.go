@@ -0,0 +1,64 @@
+package core
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLoggerProcessors(t *testing.T) {
+	mockDriver := &MockDriver{}
+
+	enrich := ProcessorFunc(func(entry *LogEntry) bool {
+		entry.Attrs["host"] = "web-1"
+		return true
+	})
+	dropNoise := ProcessorFunc(func(entry *LogEntry) bool {
+		return entry.Message != "noise"
+	})
+
+	logger := NewLoggerWithOptions(WithDrivers(mockDriver), WithProcessors(enrich, dropNoise))
+
+	attrs := Attributes{"user": "42"}
+	logger.Info("kept", attrs)
+	logger.Info("noise")
+	logger.NewTransaction("tx").Warning("kept in transaction")
+
+	if len(mockDriver.Logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %d", len(mockDriver.Logs))
+	}
+
+	for _, log := range mockDriver.Logs {
+		if log.Attrs["host"] != "web-1" {
+			t.Errorf("Log %q not enriched: %+v", log.Message, log.Attrs)
+		}
+	}
+
+	if mockDriver.Logs[0].Attrs["user"] != "42" {
+		t.Errorf("Caller attribute lost: %+v", mockDriver.Logs[0].Attrs)
+	}
+	if _, modified := attrs["host"]; modified {
+		t.Error("Processor modified the caller's attributes")
+	}
+	if mockDriver.Logs[1].TransactionID != "tx" {
+		t.Errorf("Log transaction ID = %q, want %q", mockDriver.Logs[1].TransactionID, "tx")
+	}
+}
+
+func TestLoggerProcessorsDroppedFatal(t *testing.T) {
+	mockDriver := &MockDriver{}
+	dropAll := ProcessorFunc(func(entry *LogEntry) bool { return false })
+	logger := NewLoggerWithOptions(WithDrivers(mockDriver), WithProcessors(dropAll))
+
+	exited := false
+	exit = func(code int) { exited = true }
+	defer func() { exit = os.Exit }()
+
+	logger.Fatal("dropped but fatal")
+
+	if len(mockDriver.Logs) != 0 {
+		t.Errorf("Expected dropped entry, got %d logs", len(mockDriver.Logs))
+	}
+	if !exited || !mockDriver.Closed {
+		t.Error("Fatal entry dropped by a processor must still close drivers and exit")
+	}
+}
@@ -1,26 +1,80 @@
 package core
 
 import (
-	"time"
+	"fmt"
 )
 
 // Transaction represents a group of related log entries
 type Transaction interface {
+	// Trace logs a message at Trace level
+	Trace(msg string, attrs ...Attributes) error
+
 	// Debug logs a message at Debug level
 	Debug(msg string, attrs ...Attributes) error
 
 	// Info logs a message at Info level
 	Info(msg string, attrs ...Attributes) error
 
+	// Notice logs a message at Notice level
+	Notice(msg string, attrs ...Attributes) error
+
 	// Warning logs a message at Warning level
 	Warning(msg string, attrs ...Attributes) error
 
 	// Error logs a message at Error level
 	Error(msg string, attrs ...Attributes) error
 
+	// Critical logs a message at Critical level
+	Critical(msg string, attrs ...Attributes) error
+
+	// Fatal logs a message at Fatal level, closes all drivers and exits the process
+	Fatal(msg string, attrs ...Attributes) error
+
+	// Panic logs a message at Panic level, closes all drivers and panics
+	Panic(msg string, attrs ...Attributes) error
+
+	// Err logs a message at Error level with an error attached
+	Err(err error, msg string, attrs ...Attributes) error
+
 	// Log logs a message at the specified level
 	Log(level Level, msg string, attrs ...Attributes) error
 
+	// Tracef logs a formatted message at Trace level
+	Tracef(format string, args ...interface{}) error
+
+	// Debugf logs a formatted message at Debug level
+	Debugf(format string, args ...interface{}) error
+
+	// Infof logs a formatted message at Info level
+	Infof(format string, args ...interface{}) error
+
+	// Noticef logs a formatted message at Notice level
+	Noticef(format string, args ...interface{}) error
+
+	// Warningf logs a formatted message at Warning level
+	Warningf(format string, args ...interface{}) error
+
+	// Errorf logs a formatted message at Error level
+	Errorf(format string, args ...interface{}) error
+
+	// Criticalf logs a formatted message at Critical level
+	Criticalf(format string, args ...interface{}) error
+
+	// Fatalf logs a formatted message at Fatal level, closes all drivers and exits the process
+	Fatalf(format string, args ...interface{}) error
+
+	// Panicf logs a formatted message at Panic level, closes all drivers and panics
+	Panicf(format string, args ...interface{}) error
+
+	// Logf logs a formatted message at the specified level
+	Logf(level Level, format string, args ...interface{}) error
+
+	// LogFn logs the message returned by fn, which is only called if a driver accepts the level
+	LogFn(level Level, fn func() string, attrs ...Attributes) error
+
+	// Enabled reports whether any driver would accept an entry at the level
+	Enabled(level Level) bool
+
 	// ID returns the transaction ID
 	ID() string
 }
@@ -39,47 +93,152 @@ func newTransaction(id string, logger *logger) Transaction {
 	}
 }
 
+// Trace logs a message at Trace level
+func (t *transaction) Trace(msg string, attrs ...Attributes) error {
+	return t.logger.log(Trace, t.id, msg, nil, attrs)
+}
+
 // Debug logs a message at Debug level
 func (t *transaction) Debug(msg string, attrs ...Attributes) error {
-	return t.Log(Debug, msg, attrs...)
+	return t.logger.log(Debug, t.id, msg, nil, attrs)
 }
 
 // Info logs a message at Info level
 func (t *transaction) Info(msg string, attrs ...Attributes) error {
-	return t.Log(Info, msg, attrs...)
+	return t.logger.log(Info, t.id, msg, nil, attrs)
+}
+
+// Notice logs a message at Notice level
+func (t *transaction) Notice(msg string, attrs ...Attributes) error {
+	return t.logger.log(Notice, t.id, msg, nil, attrs)
 }
 
 // Warning logs a message at Warning level
 func (t *transaction) Warning(msg string, attrs ...Attributes) error {
-	return t.Log(Warning, msg, attrs...)
+	return t.logger.log(Warning, t.id, msg, nil, attrs)
 }
 
 // Error logs a message at Error level
 func (t *transaction) Error(msg string, attrs ...Attributes) error {
-	return t.Log(Error, msg, attrs...)
+	return t.logger.log(Error, t.id, msg, nil, attrs)
+}
+
+// Critical logs a message at Critical level
+func (t *transaction) Critical(msg string, attrs ...Attributes) error {
+	return t.logger.log(Critical, t.id, msg, nil, attrs)
+}
+
+// Fatal logs a message at Fatal level, closes all drivers and exits the process
+func (t *transaction) Fatal(msg string, attrs ...Attributes) error {
+	return t.logger.log(Fatal, t.id, msg, nil, attrs)
+}
+
+// Panic logs a message at Panic level, closes all drivers and panics
+func (t *transaction) Panic(msg string, attrs ...Attributes) error {
+	return t.logger.log(Panic, t.id, msg, nil, attrs)
+}
+
+// Err logs a message at Error level with an error attached
+func (t *transaction) Err(err error, msg string, attrs ...Attributes) error {
+	return t.logger.log(Error, t.id, msg, err, attrs)
 }
 
 // Log logs a message at the specified level
 func (t *transaction) Log(level Level, msg string, attrs ...Attributes) error {
-	entry := &LogEntry{
-		Timestamp:     time.Now(),
-		Level:         level,
-		Message:       msg,
-		TransactionID: t.id,
+	return t.logger.log(level, t.id, msg, nil, attrs)
+}
+
+// Tracef logs a formatted message at Trace level
+func (t *transaction) Tracef(format string, args ...interface{}) error {
+	if !t.logger.wants(Trace) {
+		return nil
+	}
+	return t.logger.log(Trace, t.id, fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Debugf logs a formatted message at Debug level
+func (t *transaction) Debugf(format string, args ...interface{}) error {
+	if !t.logger.wants(Debug) {
+		return nil
 	}
+	return t.logger.log(Debug, t.id, fmt.Sprintf(format, args...), nil, nil)
+}
 
-	if len(attrs) > 0 {
-		entry.Attrs = attrs[0]
+// Infof logs a formatted message at Info level
+func (t *transaction) Infof(format string, args ...interface{}) error {
+	if !t.logger.wants(Info) {
+		return nil
 	}
+	return t.logger.log(Info, t.id, fmt.Sprintf(format, args...), nil, nil)
+}
 
-	var lastErr error
-	for _, driver := range t.logger.drivers {
-		if err := driver.Log(entry); err != nil {
-			lastErr = err
-		}
+// Noticef logs a formatted message at Notice level
+func (t *transaction) Noticef(format string, args ...interface{}) error {
+	if !t.logger.wants(Notice) {
+		return nil
 	}
+	return t.logger.log(Notice, t.id, fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Warningf logs a formatted message at Warning level
+func (t *transaction) Warningf(format string, args ...interface{}) error {
+	if !t.logger.wants(Warning) {
+		return nil
+	}
+	return t.logger.log(Warning, t.id, fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Errorf logs a formatted message at Error level
+func (t *transaction) Errorf(format string, args ...interface{}) error {
+	if !t.logger.wants(Error) {
+		return nil
+	}
+	return t.logger.log(Error, t.id, fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Criticalf logs a formatted message at Critical level
+func (t *transaction) Criticalf(format string, args ...interface{}) error {
+	if !t.logger.wants(Critical) {
+		return nil
+	}
+	return t.logger.log(Critical, t.id, fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Fatalf logs a formatted message at Fatal level, closes all drivers and exits the process
+func (t *transaction) Fatalf(format string, args ...interface{}) error {
+	if !t.logger.wants(Fatal) {
+		return nil
+	}
+	return t.logger.log(Fatal, t.id, fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Panicf logs a formatted message at Panic level, closes all drivers and panics
+func (t *transaction) Panicf(format string, args ...interface{}) error {
+	if !t.logger.wants(Panic) {
+		return nil
+	}
+	return t.logger.log(Panic, t.id, fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Logf logs a formatted message at the specified level
+func (t *transaction) Logf(level Level, format string, args ...interface{}) error {
+	if !t.logger.wants(level) {
+		return nil
+	}
+	return t.logger.log(level, t.id, fmt.Sprintf(format, args...), nil, nil)
+}
+
+// LogFn logs the message returned by fn, which is only called if a driver accepts the level
+func (t *transaction) LogFn(level Level, fn func() string, attrs ...Attributes) error {
+	if !t.logger.wants(level) {
+		return nil
+	}
+	return t.logger.log(level, t.id, fn(), nil, attrs)
+}
 
-	return lastErr
+// Enabled reports whether any driver would accept an entry at the level
+func (t *transaction) Enabled(level Level) bool {
+	return t.logger.Enabled(level)
 }
 
 // ID returns the transaction ID
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -23,10 +24,13 @@ func TestTransaction(t *testing.T) {
 		logFunc  func(msg string, attrs ...Attributes) error
 		expected Level
 	}{
+		{"Trace", tx.Trace, Trace},
 		{"Debug", tx.Debug, Debug},
 		{"Info", tx.Info, Info},
+		{"Notice", tx.Notice, Notice},
 		{"Warning", tx.Warning, Warning},
 		{"Error", tx.Error, Error},
+		{"Critical", tx.Critical, Critical},
 	}
 
 	for i, test := range tests {
@@ -137,3 +141,22 @@ func TestMultipleTransactions(t *testing.T) {
 		t.Errorf("Log 3 incorrect: %+v", log)
 	}
 }
+
+func TestTransactionErr(t *testing.T) {
+	mockDriver := &MockDriver{}
+	logger := NewLogger(mockDriver)
+
+	tx := logger.NewTransaction("tx-err")
+	if err := tx.Err(errors.New("boom"), "step failed"); err != nil {
+		t.Errorf("transaction.Err() error = %v", err)
+	}
+
+	if len(mockDriver.Logs) != 1 {
+		t.Fatalf("Expected 1 log, got %d", len(mockDriver.Logs))
+	}
+
+	log := mockDriver.Logs[0]
+	if log.TransactionID != "tx-err" || log.Level != Error || log.Error == nil || log.Error.Message != "boom" {
+		t.Errorf("Log incorrect: %+v", log)
+	}
+}
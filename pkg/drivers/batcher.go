@@ -0,0 +1,240 @@
+package drivers
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+	"github.com/MaoDaGreith/logging/pkg/internal/optparse"
+)
+
+// ErrQueueFull is returned when a batching driver drops an entry because its
+// queue is full
+var ErrQueueFull = errors.New("queue is full, entry dropped")
+
+// Batching defaults
+const (
+	defaultBatchSize = 100
+	defaultLinger    = time.Second
+	defaultQueueSize = 10000
+)
+
+// BatchConfig configures how a network driver groups entries
+type BatchConfig struct {
+	// Size is the maximum number of entries sent at once
+	Size int
+
+	// Linger is how long the first entry of a batch waits for more entries
+	Linger time.Duration
+
+	// QueueSize bounds the number of entries waiting to be sent
+	QueueSize int
+}
+
+// withDefaults replaces zero values with the defaults
+func (c BatchConfig) withDefaults() BatchConfig {
+	if c.Size <= 0 {
+		c.Size = defaultBatchSize
+	}
+	if c.Linger <= 0 {
+		c.Linger = defaultLinger
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = defaultQueueSize
+	}
+
+	return c
+}
+
+// newBatchConfig reads the batch_size, linger and queue_size options
+func newBatchConfig(options map[string]interface{}) (BatchConfig, error) {
+	var config BatchConfig
+	var err error
+
+	if config.Size, err = optparse.Int(options["batch_size"], defaultBatchSize); err != nil {
+		return config, fmt.Errorf("invalid batch_size: %w", err)
+	}
+	if config.Linger, err = optparse.Duration(options["linger"], defaultLinger); err != nil {
+		return config, fmt.Errorf("invalid linger: %w", err)
+	}
+	if config.QueueSize, err = optparse.Int(options["queue_size"], defaultQueueSize); err != nil {
+		return config, fmt.Errorf("invalid queue_size: %w", err)
+	}
+
+	return config.withDefaults(), nil
+}
+
+// batcher queues entries and passes them to a send function in batches from
+// a background goroutine. A batch is sent when it is full, when its first
+// entry has waited for the linger time, on Flush and on Close.
+type batcher struct {
+	config  BatchConfig
+	send    func(batch []*core.LogEntry) error
+	queue   chan *core.LogEntry
+	flushes chan chan error
+	done    chan struct{}
+
+	// mu guards closed against concurrent sends to the queue
+	mu     sync.RWMutex
+	closed bool
+
+	errMu    sync.Mutex
+	asyncErr error
+}
+
+// newBatcher starts a batcher calling send for each batch
+func newBatcher(config BatchConfig, send func(batch []*core.LogEntry) error) *batcher {
+	config = config.withDefaults()
+
+	b := &batcher{
+		config:  config,
+		send:    send,
+		queue:   make(chan *core.LogEntry, config.QueueSize),
+		flushes: make(chan chan error),
+		done:    make(chan struct{}),
+	}
+
+	go b.run()
+
+	return b
+}
+
+// add queues an entry without blocking. It fails if the queue is full and
+// reports, once, a send error from the background goroutine.
+func (b *batcher) add(entry *core.LogEntry) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return fmt.Errorf("driver is closed")
+	}
+
+	select {
+	case b.queue <- entry:
+	default:
+		return ErrQueueFull
+	}
+
+	return b.takeError()
+}
+
+// flush sends the queued entries and waits for the result
+func (b *batcher) flush() error {
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return nil
+	}
+
+	result := make(chan error)
+	b.flushes <- result
+	b.mu.RUnlock()
+
+	return <-result
+}
+
+// close sends the queued entries and stops the background goroutine. It
+// returns the last send error that was not yet reported.
+func (b *batcher) close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.queue)
+	b.mu.Unlock()
+
+	<-b.done
+
+	return b.takeError()
+}
+
+// run collects batches until the queue is closed
+func (b *batcher) run() {
+	defer close(b.done)
+
+	batch := make([]*core.LogEntry, 0, b.config.Size)
+	timer := time.NewTimer(b.config.Linger)
+	timer.Stop()
+
+	sendBatch := func() error {
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		err := b.send(batch)
+		batch = make([]*core.LogEntry, 0, b.config.Size)
+		return err
+	}
+
+	for {
+		select {
+		case entry, ok := <-b.queue:
+			if !ok {
+				b.setError(sendBatch())
+				return
+			}
+
+			batch = append(batch, entry)
+			if len(batch) == 1 {
+				timer.Reset(b.config.Linger)
+			}
+			if len(batch) >= b.config.Size {
+				b.setError(sendBatch())
+			}
+
+		case <-timer.C:
+			b.setError(sendBatch())
+
+		case result := <-b.flushes:
+			// Take what is already queued into the flush
+			for drained := false; !drained; {
+				select {
+				case entry := <-b.queue:
+					batch = append(batch, entry)
+					if len(batch) >= b.config.Size {
+						b.setError(sendBatch())
+					}
+				default:
+					drained = true
+				}
+			}
+
+			err := sendBatch()
+			if err == nil {
+				err = b.takeError()
+			}
+			result <- err
+		}
+	}
+}
+
+// setError records a send error from the background goroutine
+func (b *batcher) setError(err error) {
+	if err == nil {
+		return
+	}
+
+	b.errMu.Lock()
+	defer b.errMu.Unlock()
+	b.asyncErr = err
+}
+
+// takeError returns and clears the last recorded send error
+func (b *batcher) takeError() error {
+	b.errMu.Lock()
+	defer b.errMu.Unlock()
+
+	err := b.asyncErr
+	b.asyncErr = nil
+	return err
+}
@@ -0,0 +1,158 @@
+package drivers
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// batchRecorder collects the batches passed to a batcher
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]string
+	err     error
+	block   chan struct{}
+}
+
+func (r *batchRecorder) send(batch []*core.LogEntry) error {
+	if r.block != nil {
+		<-r.block
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	messages := make([]string, len(batch))
+	for i, entry := range batch {
+		messages[i] = entry.Message
+	}
+	r.batches = append(r.batches, messages)
+	return r.err
+}
+
+func (r *batchRecorder) sizes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sizes := make([]int, len(r.batches))
+	for i, batch := range r.batches {
+		sizes[i] = len(batch)
+	}
+	return sizes
+}
+
+func TestBatcherSendsFullBatches(t *testing.T) {
+	recorder := &batchRecorder{}
+	b := newBatcher(BatchConfig{Size: 2, Linger: time.Hour}, recorder.send)
+
+	for _, message := range []string{"a", "b", "c", "d", "e"} {
+		if err := b.add(&core.LogEntry{Message: message}); err != nil {
+			t.Fatalf("add() error = %v", err)
+		}
+	}
+
+	if err := b.close(); err != nil {
+		t.Fatalf("close() error = %v", err)
+	}
+
+	sizes := recorder.sizes()
+	if len(sizes) != 3 || sizes[0] != 2 || sizes[1] != 2 || sizes[2] != 1 {
+		t.Errorf("batch sizes = %v, want [2 2 1]", sizes)
+	}
+}
+
+func TestBatcherLinger(t *testing.T) {
+	recorder := &batchRecorder{}
+	b := newBatcher(BatchConfig{Size: 100, Linger: 20 * time.Millisecond}, recorder.send)
+	defer b.close()
+
+	b.add(&core.LogEntry{Message: "a"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(recorder.sizes()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("batch was not sent after the linger time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBatcherFlush(t *testing.T) {
+	recorder := &batchRecorder{}
+	b := newBatcher(BatchConfig{Size: 100, Linger: time.Hour}, recorder.send)
+	defer b.close()
+
+	b.add(&core.LogEntry{Message: "a"})
+	b.add(&core.LogEntry{Message: "b"})
+
+	if err := b.flush(); err != nil {
+		t.Fatalf("flush() error = %v", err)
+	}
+
+	sizes := recorder.sizes()
+	if len(sizes) != 1 || sizes[0] != 2 {
+		t.Errorf("batch sizes = %v, want [2]", sizes)
+	}
+}
+
+func TestBatcherReportsSendErrors(t *testing.T) {
+	recorder := &batchRecorder{err: errors.New("unavailable")}
+	b := newBatcher(BatchConfig{Size: 1, Linger: time.Hour}, recorder.send)
+
+	b.add(&core.LogEntry{Message: "a"})
+	if err := b.flush(); err == nil || err.Error() != "unavailable" {
+		t.Errorf("flush() error = %v, want unavailable", err)
+	}
+
+	// Errors are reported once
+	if err := b.flush(); err != nil {
+		t.Errorf("second flush() error = %v, want nil", err)
+	}
+
+	b.add(&core.LogEntry{Message: "b"})
+	if err := b.close(); err == nil {
+		t.Error("close() error = nil, want the send error")
+	}
+	if err := b.add(&core.LogEntry{Message: "c"}); err == nil {
+		t.Error("add() after close error = nil, want an error")
+	}
+}
+
+func TestBatcherQueueFull(t *testing.T) {
+	recorder := &batchRecorder{block: make(chan struct{})}
+	b := newBatcher(BatchConfig{Size: 1, Linger: time.Hour, QueueSize: 2}, recorder.send)
+
+	// The first entry is taken by the blocked send, two fill the queue
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = b.add(&core.LogEntry{Message: "a"})
+	}
+	if !errors.Is(err, ErrQueueFull) {
+		t.Errorf("add() error = %v, want ErrQueueFull", err)
+	}
+
+	close(recorder.block)
+	if err := b.close(); err != nil {
+		t.Errorf("close() error = %v", err)
+	}
+}
+
+func TestNewBatchConfig(t *testing.T) {
+	config, err := newBatchConfig(map[string]interface{}{
+		"batch_size": 10,
+		"linger":     "250ms",
+	})
+	if err != nil {
+		t.Fatalf("newBatchConfig() error = %v", err)
+	}
+	if config.Size != 10 || config.Linger != 250*time.Millisecond || config.QueueSize != defaultQueueSize {
+		t.Errorf("config = %+v", config)
+	}
+
+	if _, err := newBatchConfig(map[string]interface{}{"batch_size": "many"}); err == nil {
+		t.Error("newBatchConfig() with invalid batch_size error = nil")
+	}
+}
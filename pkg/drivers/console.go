@@ -21,7 +21,7 @@ func init() {
 type ConsoleDriver struct {
 	stdout     io.Writer
 	stderr     io.Writer
-	minLevel   core.Level
+	filter     *core.LevelFilter
 	timeFormat string
 	colorized  bool
 }
@@ -29,10 +29,17 @@ type ConsoleDriver struct {
 // ConsoleDriverOption represents an option for the console driver
 type ConsoleDriverOption func(*ConsoleDriver)
 
-// WithMinLevel sets the minimum log level to output
+// WithMinLevel sets the minimum log level to output, replacing any level filter
 func WithMinLevel(level core.Level) ConsoleDriverOption {
 	return func(d *ConsoleDriver) {
-		d.minLevel = level
+		d.filter = core.NewLevelFilter(core.MinLevel(level))
+	}
+}
+
+// WithLevelFilter sets the filter that decides which levels are output
+func WithLevelFilter(filter *core.LevelFilter) ConsoleDriverOption {
+	return func(d *ConsoleDriver) {
+		d.filter = filter
 	}
 }
 
@@ -69,7 +76,6 @@ func NewConsoleDriverWithOptions(options ...ConsoleDriverOption) *ConsoleDriver
 	driver := &ConsoleDriver{
 		stdout:     os.Stdout,
 		stderr:     os.Stderr,
-		minLevel:   core.Debug,
 		timeFormat: time.RFC3339,
 		colorized:  true,
 	}
@@ -86,16 +92,15 @@ func NewConsoleDriver(options map[string]interface{}) (core.Driver, error) {
 	driver := &ConsoleDriver{
 		stdout:     os.Stdout,
 		stderr:     os.Stderr,
-		minLevel:   core.Debug,
 		timeFormat: time.RFC3339,
 		colorized:  true,
 	}
 
-	if levelStr, ok := options["min_level"].(string); ok {
-		if level, err := core.ParseLevel(levelStr); err == nil {
-			driver.minLevel = level
-		}
+	filter, err := newLevelFilter(options)
+	if err != nil {
+		return nil, err
 	}
+	driver.filter = filter
 
 	if format, ok := options["time_format"].(string); ok {
 		driver.timeFormat = format
@@ -110,7 +115,7 @@ func NewConsoleDriver(options map[string]interface{}) (core.Driver, error) {
 
 // Log writes a log entry to the console
 func (d *ConsoleDriver) Log(entry *core.LogEntry) error {
-	if entry.Level < d.minLevel {
+	if !d.filter.Allows(entry.Level) {
 		return nil
 	}
 
@@ -129,6 +134,16 @@ func (d *ConsoleDriver) Log(entry *core.LogEntry) error {
 	return err
 }
 
+// Name returns the driver name used in configuration
+func (d *ConsoleDriver) Name() string {
+	return ConsoleDriverName
+}
+
+// Enabled reports whether the driver accepts entries at the level
+func (d *ConsoleDriver) Enabled(level core.Level) bool {
+	return d.filter.Allows(level)
+}
+
 // Close is a no-op for the console driver
 func (d *ConsoleDriver) Close() error {
 	return nil
@@ -164,30 +179,42 @@ func (d *ConsoleDriver) format(entry *core.LogEntry) string {
 		txID = fmt.Sprintf(" (tx: %s)", entry.TransactionID)
 	}
 
-	return fmt.Sprintf("%s [%s]%s%s %s", timestamp, levelStr, txID, attrsStr, message)
+	// Format attached error and stack trace as indented lines below the message
+	details := ""
+	if entry.Error != nil {
+		details += formatErrorChain(entry.Error, "    ")
+	}
+
+	if len(entry.Stack) > 0 {
+		stack := formatStack(entry.Stack, "    ")
+		if d.colorized {
+			// Dim the stack so that it stands apart from the message
+			stack = colorDim + stack + colorReset
+		}
+		details += stack
+	}
+
+	// Format call site
+	caller := ""
+	if entry.Caller != nil {
+		caller = fmt.Sprintf(" (caller: %s)", formatCallerFunction(entry.Caller))
+	}
+
+	return fmt.Sprintf("%s [%s]%s%s%s %s%s", timestamp, levelStr, txID, caller, attrsStr, message, details)
 }
 
-// ANSI color codes
+// ANSI escape sequences
 const (
-	colorReset  = "\033[0m"
-	colorRed    = "\033[31m"
-	colorGreen  = "\033[32m"
-	colorYellow = "\033[33m"
-	colorBlue   = "\033[34m"
+	colorReset = "\033[0m"
+	colorDim   = "\033[2m"
 )
 
-// colorizeLevel adds ANSI color codes to log level
+// colorizeLevel adds the ANSI color registered for the level to the level string
 func (d *ConsoleDriver) colorizeLevel(level core.Level, levelStr string) string {
-	switch level {
-	case core.Debug:
-		return colorBlue + levelStr + colorReset
-	case core.Info:
-		return colorGreen + levelStr + colorReset
-	case core.Warning:
-		return colorYellow + levelStr + colorReset
-	case core.Error:
-		return colorRed + levelStr + colorReset
-	default:
+	color := level.Color()
+	if color == "" {
 		return levelStr
 	}
+
+	return "\033[" + color + "m" + levelStr + colorReset
 }
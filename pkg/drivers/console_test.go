@@ -3,6 +3,7 @@ package drivers
 import (
 	"bytes"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,6 +32,12 @@ func TestConsoleDriver(t *testing.T) {
 		txID      string
 		expectOut bool
 	}{
+		{
+			name:      "trace level",
+			level:     core.Trace,
+			message:   "trace message",
+			expectOut: false,
+		},
 		{
 			name:      "debug level",
 			level:     core.Debug,
@@ -49,12 +56,24 @@ func TestConsoleDriver(t *testing.T) {
 			message:   "warning message",
 			expectOut: true,
 		},
+		{
+			name:      "notice level",
+			level:     core.Notice,
+			message:   "notice message",
+			expectOut: true,
+		},
 		{
 			name:      "error level",
 			level:     core.Error,
 			message:   "error message",
 			expectOut: true,
 		},
+		{
+			name:      "critical level",
+			level:     core.Critical,
+			message:   "critical message",
+			expectOut: true,
+		},
 		{
 			name:      "with attributes",
 			level:     core.Info,
@@ -145,7 +164,7 @@ func TestConsoleDriver(t *testing.T) {
 // Regular expressions for output validation
 var (
 	timeRegex    = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}[-+]\d{2}:\d{2}`)
-	levelRegex   = regexp.MustCompile(`\[(DEBUG|INFO|WARNING|ERROR)\]`)
+	levelRegex   = regexp.MustCompile(`\[(TRACE|DEBUG|INFO|NOTICE|WARNING|ERROR|CRITICAL|FATAL|PANIC)\]`)
 	messageRegex = regexp.MustCompile(`message`)
 	attrsRegex   = regexp.MustCompile(`\[key=value\]`)
 	txIDRegex    = regexp.MustCompile(`\(tx: tx-123\)`)
@@ -193,3 +212,111 @@ func TestConsoleDriverClose(t *testing.T) {
 		t.Errorf("Close() error = %v", err)
 	}
 }
+
+func TestConsoleDriverColorizeLevel(t *testing.T) {
+	driver := NewConsoleDriverWithOptions()
+
+	levels := []core.Level{
+		core.Trace, core.Debug, core.Info, core.Notice, core.Warning,
+		core.Error, core.Critical, core.Fatal, core.Panic,
+	}
+
+	for _, level := range levels {
+		t.Run(level.String(), func(t *testing.T) {
+			got := driver.colorizeLevel(level, level.String())
+			if got == level.String() {
+				t.Errorf("colorizeLevel(%v) returned uncolored string", level)
+			}
+		})
+	}
+}
+
+func TestConsoleDriverCustomLevel(t *testing.T) {
+	security := core.Level(55)
+	if err := core.RegisterLevel(core.LevelDefinition{Level: security, Name: "SECURITY", Color: "1;35"}); err != nil {
+		t.Fatalf("RegisterLevel() error = %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	driver := NewConsoleDriverWithOptions(WithStdout(&stdout), WithStderr(&stderr))
+
+	err := driver.Log(&core.LogEntry{
+		Timestamp: time.Now(),
+		Level:     security,
+		Message:   "security message",
+	})
+	if err != nil {
+		t.Errorf("Log() error = %v", err)
+	}
+
+	// Custom levels above Error go to stderr like other error levels
+	want := "\033[1;35mSECURITY" + colorReset
+	if !bytes.Contains(stderr.Bytes(), []byte(want)) {
+		t.Errorf("Output %q does not contain colorized custom level", stderr.String())
+	}
+}
+
+func TestConsoleDriverErrorChain(t *testing.T) {
+	var stderr bytes.Buffer
+	driver := NewConsoleDriverWithOptions(WithStderr(&stderr), WithColorized(false))
+
+	err := driver.Log(&core.LogEntry{
+		Timestamp: time.Now(),
+		Level:     core.Error,
+		Message:   "request failed",
+		Error: &core.ErrorInfo{
+			Message: "fetch: timeout",
+			Chain: []core.ErrorCause{
+				{Type: "*fmt.wrapError", Message: "fetch: timeout"},
+				{Type: "*errors.errorString", Message: "timeout"},
+			},
+		},
+	})
+	if err != nil {
+		t.Errorf("Log() error = %v", err)
+	}
+
+	want := "request failed\n    error: *fmt.wrapError: fetch: timeout\n    caused by: *errors.errorString: timeout\n"
+	if !strings.HasSuffix(stderr.String(), want) {
+		t.Errorf("Output = %q, want suffix %q", stderr.String(), want)
+	}
+}
+
+func TestConsoleDriverCaller(t *testing.T) {
+	var stdout bytes.Buffer
+	driver := NewConsoleDriverWithOptions(WithStdout(&stdout), WithColorized(false))
+
+	err := driver.Log(&core.LogEntry{
+		Timestamp: time.Now(),
+		Level:     core.Info,
+		Message:   "message",
+		Caller:    &core.Frame{File: "app/main.go", Line: 42, Function: "main.main"},
+	})
+	if err != nil {
+		t.Errorf("Log() error = %v", err)
+	}
+
+	if !strings.Contains(stdout.String(), "[INFO] (caller: app/main.go:42 main.main) message") {
+		t.Errorf("Output %q does not contain caller", stdout.String())
+	}
+}
+
+func TestConsoleDriverStack(t *testing.T) {
+	var stderr bytes.Buffer
+	driver := NewConsoleDriverWithOptions(WithStderr(&stderr))
+
+	err := driver.Log(&core.LogEntry{
+		Timestamp: time.Now(),
+		Level:     core.Error,
+		Message:   "message",
+		Stack:     []core.Frame{{Function: "main.handler", File: "/app/main.go", Line: 42}},
+	})
+	if err != nil {
+		t.Errorf("Log() error = %v", err)
+	}
+
+	want := "message" + colorDim + "\n    stack:\n        main.handler (/app/main.go:42)" + colorReset + "\n"
+	if !strings.HasSuffix(stderr.String(), want) {
+		t.Errorf("Output = %q, want suffix %q", stderr.String(), want)
+	}
+}
@@ -0,0 +1,191 @@
+package drivers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+	"github.com/MaoDaGreith/logging/pkg/internal/optparse"
+)
+
+// DedupeDriverName is the name to use in configuration
+const DedupeDriverName = "dedupe"
+
+func init() {
+	Register(DedupeDriverName, NewDedupeDriver)
+}
+
+// defaultDedupeTimeout is how long repeats are held back when no timeout is configured
+const defaultDedupeTimeout = 10 * time.Second
+
+// DedupeDriver wraps another driver and collapses identical consecutive
+// entries, like syslog's "last message repeated". The first entry is passed
+// through; identical entries that follow are counted and reported as a single
+// "<message> (repeated N times)" entry once a different entry arrives, the
+// timeout passes or the driver is closed. Entries are identical when their
+// level, message, transaction ID and attributes are equal.
+type DedupeDriver struct {
+	next    core.Driver
+	filter  *core.LevelFilter
+	timeout time.Duration
+	mu      sync.Mutex
+	last    *core.LogEntry
+	repeats int
+	timer   *time.Timer
+	gen     int
+	closed  bool
+}
+
+// NewDedupeDriver creates a new deduplicating driver from a map of options.
+// The wrapped driver is described by the "driver" option.
+func NewDedupeDriver(options map[string]interface{}) (core.Driver, error) {
+	filter, err := newLevelFilter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := optparse.Duration(options["timeout"], defaultDedupeTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeout: %w", err)
+	}
+
+	next, err := createChild(options["driver"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid driver: %w", err)
+	}
+
+	driver := NewDedupeDriverWithTimeout(next, timeout)
+	driver.filter = filter
+	return driver, nil
+}
+
+// NewDedupeDriverWithTimeout wraps next in a deduplicating driver. Repeats
+// are held back for at most timeout; a non-positive timeout uses the default.
+func NewDedupeDriverWithTimeout(next core.Driver, timeout time.Duration) *DedupeDriver {
+	if timeout <= 0 {
+		timeout = defaultDedupeTimeout
+	}
+
+	return &DedupeDriver{
+		next:    next,
+		timeout: timeout,
+	}
+}
+
+// Log passes the entry to the wrapped driver unless it repeats the previous one
+func (d *DedupeDriver) Log(entry *core.LogEntry) error {
+	// Entries the wrapped driver would discard must not break up a run
+	if !d.Enabled(entry.Level) {
+		return nil
+	}
+
+	// The lock is held while forwarding so that a summary always precedes
+	// the entry that ended the run
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return fmt.Errorf("driver is closed")
+	}
+
+	if d.last != nil && sameEntry(d.last, entry) {
+		d.last = entry
+		d.repeats++
+		if d.repeats == 1 {
+			gen := d.gen
+			d.timer = time.AfterFunc(d.timeout, func() { d.expire(gen) })
+		}
+		return nil
+	}
+
+	errs := &core.MultiError{}
+	addChildError(errs, 0, d.next, d.flush())
+
+	d.last = entry
+	addChildError(errs, 0, d.next, d.next.Log(entry))
+
+	return errorOrNil(errs)
+}
+
+// flush emits the summary of the current run, if any, and forgets the run.
+// The caller must hold d.mu.
+func (d *DedupeDriver) flush() error {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	d.gen++
+
+	last, repeats := d.last, d.repeats
+	d.last = nil
+	d.repeats = 0
+
+	if repeats == 0 {
+		return nil
+	}
+
+	summary := *last
+	summary.Message = fmt.Sprintf("%s (repeated %d times)", last.Message, repeats)
+	return d.next.Log(&summary)
+}
+
+// expire flushes the run started in generation gen once the timeout passed
+func (d *DedupeDriver) expire(gen int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// The run already ended or the driver was closed
+	if gen != d.gen || d.closed {
+		return
+	}
+
+	d.flush()
+}
+
+// Name returns the driver name used in configuration
+func (d *DedupeDriver) Name() string {
+	return DedupeDriverName
+}
+
+// Enabled reports whether the driver and the wrapped driver accept the level
+func (d *DedupeDriver) Enabled(level core.Level) bool {
+	return d.filter.Allows(level) && driverEnabled(d.next, level)
+}
+
+// Close emits the pending summary and closes the wrapped driver. The
+// returned *core.MultiError lists every failure.
+func (d *DedupeDriver) Close() error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	flushErr := d.flush()
+	d.closed = true
+	d.mu.Unlock()
+
+	errs := &core.MultiError{}
+	addChildError(errs, 0, d.next, flushErr)
+	addChildError(errs, 0, d.next, d.next.Close())
+
+	return errorOrNil(errs)
+}
+
+// sameEntry reports whether two entries are repeats of each other
+func sameEntry(a, b *core.LogEntry) bool {
+	if a.Level != b.Level || a.Message != b.Message || a.TransactionID != b.TransactionID {
+		return false
+	}
+
+	if len(a.Attrs) != len(b.Attrs) {
+		return false
+	}
+	for key, value := range a.Attrs {
+		if other, ok := b.Attrs[key]; !ok || other != value {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,145 @@
+package drivers
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+func TestDedupeDriver(t *testing.T) {
+	next := &recordingDriver{}
+	driver := NewDedupeDriverWithTimeout(next, time.Hour)
+
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "starting"})
+	for i := 0; i < 4; i++ {
+		driver.Log(&core.LogEntry{Level: core.Warning, Message: "retrying", Attrs: core.Attributes{"host": "db1"}})
+	}
+	driver.Log(&core.LogEntry{Level: core.Warning, Message: "retrying", Attrs: core.Attributes{"host": "db2"}})
+	driver.Log(&core.LogEntry{Level: core.Error, Message: "retrying", Attrs: core.Attributes{"host": "db2"}})
+	driver.Log(&core.LogEntry{Level: core.Error, Message: "retrying", Attrs: core.Attributes{"host": "db2"}})
+
+	if err := driver.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	expected := []string{
+		"starting",
+		"retrying",
+		"retrying (repeated 3 times)",
+		"retrying",
+		"retrying",
+		"retrying (repeated 1 times)",
+	}
+	if got := next.messages(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	// The summary keeps the level and attributes of the repeated entry
+	summary := next.entries[2]
+	if summary.Level != core.Warning || summary.Attrs["host"] != "db1" {
+		t.Errorf("Unexpected summary entry: %+v", summary)
+	}
+
+	if !next.closed {
+		t.Error("Expected the wrapped driver to be closed")
+	}
+
+	if err := driver.Log(&core.LogEntry{Level: core.Info, Message: "late"}); err == nil {
+		t.Error("Expected an error when logging to a closed driver")
+	}
+}
+
+func TestDedupeDriverTimeout(t *testing.T) {
+	next := &recordingDriver{}
+	driver := NewDedupeDriverWithTimeout(next, 20*time.Millisecond)
+	defer driver.Close()
+
+	for i := 0; i < 3; i++ {
+		driver.Log(&core.LogEntry{Level: core.Info, Message: "tick"})
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(next.messages()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	expected := []string{"tick", "tick (repeated 2 times)"}
+	if got := next.messages(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+
+	// After the flush the run starts over
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "tick"})
+	if got := len(next.messages()); got != 3 {
+		t.Errorf("Expected the entry after the flush to pass, got %v", next.messages())
+	}
+}
+
+func TestDedupeDriverSkipsDisabledLevels(t *testing.T) {
+	next := &recordingDriver{minLevel: core.Info, hasMinLevel: true}
+	driver := NewDedupeDriverWithTimeout(next, time.Hour)
+
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "same"})
+	driver.Log(&core.LogEntry{Level: core.Debug, Message: "noise"})
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "same"})
+	driver.Close()
+
+	expected := []string{"same", "same (repeated 1 times)"}
+	if got := next.messages(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestDedupeDriverFromOptions(t *testing.T) {
+	driver, err := Create(DedupeDriverName, map[string]interface{}{
+		"timeout": "2s",
+		"driver": map[string]interface{}{
+			"type":      ConsoleDriverName,
+			"min_level": "warning",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+	defer driver.Close()
+
+	dedupe := driver.(*DedupeDriver)
+	if dedupe.timeout != 2*time.Second {
+		t.Errorf("Expected timeout 2s, got %v", dedupe.timeout)
+	}
+	if dedupe.Enabled(core.Info) || !dedupe.Enabled(core.Error) {
+		t.Error("Expected the wrapped driver's level filter to apply")
+	}
+
+	if _, err := Create(DedupeDriverName, map[string]interface{}{}); err == nil {
+		t.Error("Expected an error without a wrapped driver")
+	}
+	if _, err := Create(DedupeDriverName, map[string]interface{}{"timeout": "soon"}); err == nil {
+		t.Error("Expected an error for an invalid timeout")
+	}
+}
+
+func TestDedupeDriverCollectsErrors(t *testing.T) {
+	next := &recordingDriver{}
+	driver := NewDedupeDriverWithTimeout(next, time.Hour)
+
+	driver.Log(&core.LogEntry{Message: "retrying"})
+	driver.Log(&core.LogEntry{Message: "retrying"})
+
+	// Both the summary of the run and the entry ending it fail
+	next.logErr = errors.New("disk full")
+
+	var multi *core.MultiError
+	if err := driver.Log(&core.LogEntry{Message: "done"}); !errors.As(err, &multi) || len(multi.Errors) != 2 {
+		t.Fatalf("Log() error = %v, want two failures", err)
+	}
+
+	driver.Log(&core.LogEntry{Message: "done"})
+	next.closeErr = errors.New("close failed")
+	if err := driver.Close(); !errors.As(err, &multi) || len(multi.Errors) != 2 {
+		t.Errorf("Close() error = %v, want the summary and close failures", err)
+	}
+}
@@ -1,6 +1,8 @@
 package drivers
 
 import (
+	"fmt"
+
 	"github.com/MaoDaGreith/logging/pkg/core"
 )
 
@@ -23,3 +25,112 @@ func Create(name string, options map[string]interface{}) (core.Driver, error) {
 
 	return nil, core.ErrDriverNotFound
 }
+
+// levelOptionKeys are the level filter keys that may be given next to the
+// type of a nested driver, as they can for top-level drivers
+var levelOptionKeys = []string{"min_level", "max_level", "levels", "exclude_levels"}
+
+// createChild instantiates a nested driver for the wrapping drivers. The
+// specification is a map with a "type", optional level filter keys and
+// "options", mirroring a driver entry in the configuration file.
+func createChild(spec interface{}) (core.Driver, error) {
+	fields, ok := spec.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected driver configuration, got %T", spec)
+	}
+
+	driverType, ok := fields["type"].(string)
+	if !ok || driverType == "" {
+		return nil, fmt.Errorf("driver type is required")
+	}
+
+	options := make(map[string]interface{})
+	switch nested := fields["options"].(type) {
+	case nil:
+	case map[string]interface{}:
+		for k, v := range nested {
+			options[k] = v
+		}
+	default:
+		return nil, fmt.Errorf("driver '%s': expected options map, got %T", driverType, nested)
+	}
+
+	for _, key := range levelOptionKeys {
+		if value, ok := fields[key]; ok {
+			if _, set := options[key]; !set {
+				options[key] = value
+			}
+		}
+	}
+
+	driver, err := Create(driverType, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create driver '%s': %w", driverType, err)
+	}
+
+	return driver, nil
+}
+
+// driverEnabled reports whether a wrapped driver accepts a level. Drivers
+// that do not implement core.LevelEnabler are assumed to accept every level.
+func driverEnabled(driver core.Driver, level core.Level) bool {
+	if enabler, ok := driver.(core.LevelEnabler); ok {
+		return enabler.Enabled(level)
+	}
+
+	return true
+}
+
+// createChildren instantiates a list of nested drivers, see createChild
+func createChildren(specs interface{}) ([]core.Driver, error) {
+	list, ok := specs.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a list of driver configurations, got %T", specs)
+	}
+
+	children := make([]core.Driver, 0, len(list))
+	for i, spec := range list {
+		child, err := createChild(spec)
+		if err != nil {
+			closeAll(children)
+			return nil, fmt.Errorf("driver %d: %w", i, err)
+		}
+		children = append(children, child)
+	}
+
+	return children, nil
+}
+
+// closeAll closes every driver and returns a *core.MultiError listing those
+// that failed, or nil
+func closeAll(drivers []core.Driver) error {
+	errs := &core.MultiError{}
+	for i, driver := range drivers {
+		addChildError(errs, i, driver, driver.Close())
+	}
+
+	return errorOrNil(errs)
+}
+
+// addChildError records a failure of the child driver at index. Nil errors
+// are ignored.
+func addChildError(errs *core.MultiError, index int, driver core.Driver, err error) {
+	if err == nil {
+		return
+	}
+
+	errs.Errors = append(errs.Errors, &core.DriverError{
+		Index: index,
+		Name:  core.DriverName(driver),
+		Err:   err,
+	})
+}
+
+// errorOrNil returns errs, or nil if it holds no errors
+func errorOrNil(errs *core.MultiError) error {
+	if len(errs.Errors) == 0 {
+		return nil
+	}
+
+	return errs
+}
@@ -86,6 +86,8 @@ func TestTextFileDriverRegistration(t *testing.T) {
 }
 
 func TestDriverCreationWithInvalidOptions(t *testing.T) {
+	tempDir := t.TempDir()
+
 	tests := []struct {
 		name        string
 		driverType  string
@@ -116,7 +118,7 @@ func TestDriverCreationWithInvalidOptions(t *testing.T) {
 			name:       "json file with invalid min level",
 			driverType: "json_file",
 			options: map[string]interface{}{
-				"file_path": "test.json",
+				"file_path": tempDir + "/test.json",
 				"min_level": "invalid",
 			},
 			expectError: false, // File drivers ignore invalid min level
@@ -125,7 +127,7 @@ func TestDriverCreationWithInvalidOptions(t *testing.T) {
 			name:       "text file with invalid time format",
 			driverType: "text_file",
 			options: map[string]interface{}{
-				"file_path":   "test.log",
+				"file_path":   tempDir + "/test.log",
 				"time_format": 123, // Invalid type
 			},
 			expectError: false, // Text driver ignores invalid time format
@@ -148,6 +150,8 @@ func TestDriverCreationWithInvalidOptions(t *testing.T) {
 				}
 				if driver == nil {
 					t.Error("Expected non-nil driver")
+				} else {
+					driver.Close()
 				}
 			}
 		})
@@ -0,0 +1,372 @@
+package drivers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// ElasticsearchDriverName is the name to use in configuration
+const ElasticsearchDriverName = "elasticsearch"
+
+func init() {
+	Register(ElasticsearchDriverName, NewElasticsearchDriver)
+}
+
+// defaultElasticsearchIndex creates one index per day
+const defaultElasticsearchIndex = "logs-%Y.%m.%d"
+
+// ecsVersion is the Elastic Common Schema version of the documents
+const ecsVersion = "8.11.0"
+
+// ElasticsearchConfig configures an ElasticsearchDriver
+type ElasticsearchConfig struct {
+	// HTTP configures the bulk requests; URL is the cluster address, e.g.
+	// http://localhost:9200, or its _bulk endpoint
+	HTTP HTTPConfig
+
+	// Batch configures how entries are grouped into bulk requests
+	Batch BatchConfig
+
+	// Index is the index name pattern. %Y, %y, %m, %d and %H are replaced
+	// with the entry's UTC timestamp, %% with a percent sign.
+	Index string
+
+	// APIKey is sent as "Authorization: ApiKey <key>"
+	APIKey string
+
+	// Pipeline is the ingest pipeline applied to the documents
+	Pipeline string
+}
+
+// ElasticsearchDriver indexes entries in Elasticsearch or OpenSearch with the
+// _bulk API, as documents using Elastic Common Schema field names. Items the
+// cluster rejects temporarily (429 or 5xx) are retried on their own; the
+// others are reported as failed.
+type ElasticsearchDriver struct {
+	config  ElasticsearchConfig
+	filter  *core.LevelFilter
+	sender  *httpSender
+	batcher *batcher
+}
+
+// NewElasticsearchDriver creates a new Elasticsearch driver from a map of options
+func NewElasticsearchDriver(options map[string]interface{}) (core.Driver, error) {
+	filter, err := newLevelFilter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	config := ElasticsearchConfig{}
+	if config.HTTP, err = newHTTPConfig(options); err != nil {
+		return nil, err
+	}
+	if config.Batch, err = newBatchConfig(options); err != nil {
+		return nil, err
+	}
+	if index, ok := options["index"].(string); ok {
+		config.Index = index
+	}
+	if apiKey, ok := options["api_key"].(string); ok {
+		config.APIKey = apiKey
+	}
+	if pipeline, ok := options["pipeline"].(string); ok {
+		config.Pipeline = pipeline
+	}
+
+	driver, err := NewElasticsearchDriverWithConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	driver.filter = filter
+	return driver, nil
+}
+
+// NewElasticsearchDriverWithConfig creates an Elasticsearch driver and starts
+// its background goroutine
+func NewElasticsearchDriverWithConfig(config ElasticsearchConfig) (*ElasticsearchDriver, error) {
+	if config.Index == "" {
+		config.Index = defaultElasticsearchIndex
+	}
+	if _, err := formatIndexName(config.Index, time.Now()); err != nil {
+		return nil, err
+	}
+
+	endpoint, err := url.Parse(config.HTTP.URL)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid url %q", config.HTTP.URL)
+	}
+	if !strings.HasSuffix(endpoint.Path, "/_bulk") {
+		endpoint.Path = strings.TrimSuffix(endpoint.Path, "/") + "/_bulk"
+	}
+	if config.Pipeline != "" {
+		query := endpoint.Query()
+		query.Set("pipeline", config.Pipeline)
+		endpoint.RawQuery = query.Encode()
+	}
+	config.HTTP.URL = endpoint.String()
+
+	if config.APIKey != "" {
+		headers := make(map[string]string, len(config.HTTP.Headers)+1)
+		for key, value := range config.HTTP.Headers {
+			headers[key] = value
+		}
+		headers["Authorization"] = "ApiKey " + config.APIKey
+		config.HTTP.Headers = headers
+	}
+
+	sender, err := newHTTPSender(config.HTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	driver := &ElasticsearchDriver{
+		config: config,
+		sender: sender,
+	}
+	driver.batcher = newBatcher(config.Batch, driver.send)
+
+	return driver, nil
+}
+
+// Log queues the entry for the next bulk request
+func (d *ElasticsearchDriver) Log(entry *core.LogEntry) error {
+	if !d.filter.Allows(entry.Level) {
+		return nil
+	}
+
+	return d.batcher.add(entry)
+}
+
+// Flush indexes the queued entries and waits for the request to finish
+func (d *ElasticsearchDriver) Flush() error {
+	return d.batcher.flush()
+}
+
+// Close indexes the queued entries and stops the background goroutine
+func (d *ElasticsearchDriver) Close() error {
+	return d.batcher.close()
+}
+
+// Name returns the driver name
+func (d *ElasticsearchDriver) Name() string {
+	return ElasticsearchDriverName
+}
+
+// Enabled reports whether the driver accepts entries at the level
+func (d *ElasticsearchDriver) Enabled(level core.Level) bool {
+	return d.filter.Allows(level)
+}
+
+// bulkResponse is the part of a _bulk response the driver reads
+type bulkResponse struct {
+	Errors bool                  `json:"errors"`
+	Items  []map[string]bulkItem `json:"items"`
+}
+
+// bulkItem is the result of one action of a bulk request
+type bulkItem struct {
+	Status int `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+// failure describes a rejected item
+func (i bulkItem) failure() string {
+	if i.Error == nil {
+		return fmt.Sprintf("status %d", i.Status)
+	}
+	return fmt.Sprintf("%s: %s", i.Error.Type, i.Error.Reason)
+}
+
+// send indexes one batch, retrying the items rejected with 429 or 5xx
+func (d *ElasticsearchDriver) send(batch []*core.LogEntry) error {
+	pending := batch
+	rejected := 0
+	reason := ""
+
+	for attempt := 0; ; attempt++ {
+		items, err := d.bulk(pending)
+		if err != nil {
+			return fmt.Errorf("failed to index %d entries: %w", len(pending), err)
+		}
+
+		var retry []*core.LogEntry
+		for i, item := range items {
+			if item.Status >= 200 && item.Status < 300 {
+				continue
+			}
+
+			if (item.Status == 429 || item.Status >= 500) && attempt < d.sender.config.Retry.MaxRetries {
+				retry = append(retry, pending[i])
+				continue
+			}
+
+			rejected++
+			if reason == "" {
+				reason = item.failure()
+			}
+		}
+
+		if len(retry) == 0 {
+			break
+		}
+
+		pending = retry
+		d.sender.sleep(d.sender.config.Retry.backoff(attempt))
+	}
+
+	if rejected > 0 {
+		return fmt.Errorf("%d of %d entries rejected, first: %s", rejected, len(batch), reason)
+	}
+
+	return nil
+}
+
+// bulk sends one _bulk request and returns the result of each item
+func (d *ElasticsearchDriver) bulk(entries []*core.LogEntry) ([]bulkItem, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+
+	for _, entry := range entries {
+		index, _ := formatIndexName(d.config.Index, entry.Timestamp)
+		action := map[string]map[string]string{"create": {"_index": index}}
+		if err := encoder.Encode(action); err != nil {
+			return nil, err
+		}
+		if err := encoder.Encode(newECSDocument(entry)); err != nil {
+			return nil, fmt.Errorf("failed to encode entry: %w", err)
+		}
+	}
+
+	body, err := d.sender.send(buf.Bytes(), "application/x-ndjson")
+	if err != nil {
+		return nil, err
+	}
+
+	var response bulkResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("invalid bulk response: %w", err)
+	}
+
+	items := make([]bulkItem, len(entries))
+	if !response.Errors {
+		for i := range items {
+			items[i].Status = 201
+		}
+		return items, nil
+	}
+
+	if len(response.Items) != len(entries) {
+		return nil, fmt.Errorf("bulk response has %d items for %d entries", len(response.Items), len(entries))
+	}
+	for i, result := range response.Items {
+		// Each item is keyed by its action, here always "create"
+		for _, item := range result {
+			items[i] = item
+		}
+	}
+
+	return items, nil
+}
+
+// newECSDocument maps an entry onto Elastic Common Schema fields
+func newECSDocument(entry *core.LogEntry) map[string]interface{} {
+	log := map[string]interface{}{
+		"level": strings.ToLower(entry.Level.String()),
+	}
+	if entry.Caller != nil {
+		log["origin"] = map[string]interface{}{
+			"file":     map[string]interface{}{"name": entry.Caller.File, "line": entry.Caller.Line},
+			"function": entry.Caller.Function,
+		}
+	}
+
+	document := map[string]interface{}{
+		"@timestamp": entry.Timestamp.UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+		"message":    entry.Message,
+		"log":        log,
+		"ecs":        map[string]string{"version": ecsVersion},
+	}
+
+	if entry.TransactionID != "" {
+		document["transaction"] = map[string]string{"id": entry.TransactionID}
+	}
+
+	// labels holds flat keyword values; dots would be read as nested objects
+	if len(entry.Attrs) > 0 {
+		labels := make(map[string]string, len(entry.Attrs))
+		for key, value := range entry.Attrs {
+			labels[strings.ReplaceAll(key, ".", "_")] = value
+		}
+		document["labels"] = labels
+	}
+
+	stack := entry.Stack
+	if entry.Error != nil {
+		errorField := map[string]interface{}{"message": entry.Error.Message}
+		if len(entry.Error.Chain) > 0 {
+			errorField["type"] = entry.Error.Chain[0].Type
+		}
+		if len(entry.Error.Stack) > 0 {
+			stack = entry.Error.Stack
+		}
+		document["error"] = errorField
+	}
+	if len(stack) > 0 {
+		errorField, ok := document["error"].(map[string]interface{})
+		if !ok {
+			errorField = map[string]interface{}{}
+			document["error"] = errorField
+		}
+		errorField["stack_trace"] = strings.Join(formatFrames(stack), "\n")
+	}
+
+	return document
+}
+
+// formatIndexName expands the date directives of an index pattern with the
+// UTC time t
+func formatIndexName(pattern string, t time.Time) (string, error) {
+	t = t.UTC()
+
+	var builder strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' {
+			builder.WriteByte(pattern[i])
+			continue
+		}
+
+		i++
+		if i == len(pattern) {
+			return "", fmt.Errorf("invalid index pattern %q: trailing %%", pattern)
+		}
+
+		switch pattern[i] {
+		case 'Y':
+			builder.WriteString(strconv.Itoa(t.Year()))
+		case 'y':
+			builder.WriteString(fmt.Sprintf("%02d", t.Year()%100))
+		case 'm':
+			builder.WriteString(fmt.Sprintf("%02d", int(t.Month())))
+		case 'd':
+			builder.WriteString(fmt.Sprintf("%02d", t.Day()))
+		case 'H':
+			builder.WriteString(fmt.Sprintf("%02d", t.Hour()))
+		case '%':
+			builder.WriteByte('%')
+		default:
+			return "", fmt.Errorf("invalid index pattern %q: unknown directive %%%c", pattern, pattern[i])
+		}
+	}
+
+	return builder.String(), nil
+}
@@ -0,0 +1,273 @@
+package drivers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// bulkAction is one action and document of a received bulk request
+type bulkAction struct {
+	index    string
+	document map[string]interface{}
+}
+
+// fakeElasticsearch records bulk requests and answers each document with the
+// status returned by status, called with the document message and the number
+// of times that message was received
+type fakeElasticsearch struct {
+	*httptest.Server
+	mu       sync.Mutex
+	paths    []string
+	headers  []http.Header
+	requests [][]bulkAction
+	seen     map[string]int
+	status   func(message string, seen int) int
+}
+
+func newFakeElasticsearch(t *testing.T, status func(message string, seen int) int) *fakeElasticsearch {
+	t.Helper()
+
+	f := &fakeElasticsearch{seen: make(map[string]int), status: status}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+func (f *fakeElasticsearch) handle(w http.ResponseWriter, r *http.Request) {
+	var actions []bulkAction
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 1<<20), 1<<20)
+	for scanner.Scan() {
+		var action map[string]map[string]string
+		if err := json.Unmarshal(scanner.Bytes(), &action); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !scanner.Scan() {
+			http.Error(w, "missing document", http.StatusBadRequest)
+			return
+		}
+		var document map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &document); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		actions = append(actions, bulkAction{index: action["create"]["_index"], document: document})
+	}
+
+	f.mu.Lock()
+	f.paths = append(f.paths, r.URL.RequestURI())
+	f.headers = append(f.headers, r.Header.Clone())
+	f.requests = append(f.requests, actions)
+
+	hasErrors := false
+	items := make([]string, len(actions))
+	for i, action := range actions {
+		message, _ := action.document["message"].(string)
+		f.seen[message]++
+		status := 201
+		if f.status != nil {
+			status = f.status(message, f.seen[message])
+		}
+
+		if status < 300 {
+			items[i] = fmt.Sprintf(`{"create":{"_index":%q,"status":%d}}`, action.index, status)
+			continue
+		}
+		hasErrors = true
+		items[i] = fmt.Sprintf(`{"create":{"_index":%q,"status":%d,"error":{"type":"error_%d","reason":"rejected %s"}}}`, action.index, status, status, message)
+	}
+	f.mu.Unlock()
+
+	fmt.Fprintf(w, `{"took":1,"errors":%v,"items":[%s]}`, hasErrors, strings.Join(items, ","))
+}
+
+func (f *fakeElasticsearch) received() [][]bulkAction {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([][]bulkAction(nil), f.requests...)
+}
+
+func newTestElasticsearchDriver(t *testing.T, options map[string]interface{}) *ElasticsearchDriver {
+	t.Helper()
+
+	options["initial_backoff"] = "1ms"
+	options["linger"] = "1h"
+	driver, err := NewElasticsearchDriver(options)
+	if err != nil {
+		t.Fatalf("NewElasticsearchDriver() error = %v", err)
+	}
+	t.Cleanup(func() { driver.Close() })
+
+	return driver.(*ElasticsearchDriver)
+}
+
+func TestElasticsearchDriverECSDocuments(t *testing.T) {
+	server := newFakeElasticsearch(t, nil)
+	driver := newTestElasticsearchDriver(t, map[string]interface{}{
+		"url":      server.URL,
+		"api_key":  "a2V5",
+		"pipeline": "logs",
+	})
+
+	day := time.Date(2024, 5, 1, 23, 30, 0, 0, time.UTC)
+	driver.Log(&core.LogEntry{
+		Timestamp:     day,
+		Level:         core.Error,
+		Message:       "charge failed",
+		TransactionID: "tx-1",
+		Attrs:         core.Attributes{"user.id": "42"},
+		Caller:        &core.Frame{Function: "main.charge", File: "/app/main.go", Line: 12},
+		Error: &core.ErrorInfo{
+			Message: "card declined",
+			Chain:   []core.ErrorCause{{Type: "*errors.errorString", Message: "card declined"}},
+			Stack:   []core.Frame{{Function: "main.charge", File: "/app/main.go", Line: 10}},
+		},
+	})
+	driver.Log(&core.LogEntry{Timestamp: day.Add(time.Hour), Level: core.Info, Message: "next day"})
+
+	if err := driver.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	requests := server.received()
+	if len(requests) != 1 || len(requests[0]) != 2 {
+		t.Fatalf("requests = %+v, want one request with two documents", requests)
+	}
+	if server.paths[0] != "/_bulk?pipeline=logs" {
+		t.Errorf("path = %q", server.paths[0])
+	}
+	if got := server.headers[0].Get("Authorization"); got != "ApiKey a2V5" {
+		t.Errorf("Authorization = %q", got)
+	}
+
+	first, second := requests[0][0], requests[0][1]
+	if first.index != "logs-2024.05.01" || second.index != "logs-2024.05.02" {
+		t.Errorf("indices = %q, %q", first.index, second.index)
+	}
+
+	got, _ := json.Marshal(first.document)
+	want := `{"@timestamp":"2024-05-01T23:30:00.000Z","ecs":{"version":"8.11.0"},` +
+		`"error":{"message":"card declined","stack_trace":"main.charge (/app/main.go:10)","type":"*errors.errorString"},` +
+		`"labels":{"user_id":"42"},` +
+		`"log":{"level":"error","origin":{"file":{"line":12,"name":"/app/main.go"},"function":"main.charge"}},` +
+		`"message":"charge failed","transaction":{"id":"tx-1"}}`
+	if string(got) != want {
+		t.Errorf("document =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestElasticsearchDriverRetriesFailedItems(t *testing.T) {
+	server := newFakeElasticsearch(t, func(message string, seen int) int {
+		switch {
+		case message == "busy" && seen == 1:
+			return 429
+		case message == "unavailable" && seen < 3:
+			return 503
+		case message == "invalid":
+			return 400
+		}
+		return 201
+	})
+	driver := newTestElasticsearchDriver(t, map[string]interface{}{"url": server.URL + "/"})
+
+	for _, message := range []string{"ok", "busy", "invalid", "unavailable"} {
+		driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: message})
+	}
+
+	err := driver.Flush()
+	if err == nil || !strings.Contains(err.Error(), "1 of 4 entries rejected") || !strings.Contains(err.Error(), "rejected invalid") {
+		t.Errorf("Flush() error = %v, want the invalid entry reported", err)
+	}
+
+	requests := server.received()
+	if len(requests) != 3 {
+		t.Fatalf("got %d requests, want 3", len(requests))
+	}
+	if len(requests[1]) != 2 || len(requests[2]) != 1 || requests[2][0].document["message"] != "unavailable" {
+		t.Errorf("retries = %+v, want only the retryable items", requests[1:])
+	}
+}
+
+func TestElasticsearchDriverRetriesExhausted(t *testing.T) {
+	server := newFakeElasticsearch(t, func(string, int) int { return 429 })
+	driver := newTestElasticsearchDriver(t, map[string]interface{}{
+		"url":         server.URL,
+		"max_retries": 2,
+	})
+
+	driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: "busy"})
+
+	err := driver.Flush()
+	if err == nil || !strings.Contains(err.Error(), "1 of 1 entries rejected") {
+		t.Errorf("Flush() error = %v", err)
+	}
+	if got := len(server.received()); got != 3 {
+		t.Errorf("got %d requests, want 3", got)
+	}
+}
+
+func TestElasticsearchDriverRequestFailure(t *testing.T) {
+	server := newHTTPServer(t, 401)
+	driver := newTestElasticsearchDriver(t, map[string]interface{}{"url": server.URL})
+
+	driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: "denied"})
+
+	err := driver.Flush()
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Flush() error = %v, want the status", err)
+	}
+}
+
+func TestFormatIndexName(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600*5))
+
+	tests := map[string]string{
+		"logs-%Y.%m.%d":  "logs-2024.01.01",
+		"logs-%y%m%d-%H": "logs-240101-22",
+		"logs":           "logs",
+		"100%%":          "100%",
+	}
+	for pattern, want := range tests {
+		got, err := formatIndexName(pattern, at)
+		if err != nil || got != want {
+			t.Errorf("formatIndexName(%q) = %q, %v, want %q", pattern, got, err, want)
+		}
+	}
+
+	for _, pattern := range []string{"logs-%Q", "logs-%"} {
+		if _, err := formatIndexName(pattern, at); err == nil {
+			t.Errorf("formatIndexName(%q) error = nil", pattern)
+		}
+	}
+}
+
+func TestNewElasticsearchDriverInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+	}{
+		{name: "missing url", options: map[string]interface{}{}},
+		{name: "url", options: map[string]interface{}{"url": "localhost:9200"}},
+		{name: "index", options: map[string]interface{}{"url": "http://localhost:9200", "index": "logs-%x"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if driver, err := NewElasticsearchDriver(test.options); err == nil {
+				driver.Close()
+				t.Error("NewElasticsearchDriver() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package drivers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+	"github.com/MaoDaGreith/logging/pkg/internal/optparse"
+)
+
+// FailoverDriverName is the name to use in configuration
+const FailoverDriverName = "failover"
+
+func init() {
+	Register(FailoverDriverName, NewFailoverDriver)
+}
+
+// defaultFailoverBackoff is how long a failed child is skipped by default
+const defaultFailoverBackoff = 30 * time.Second
+
+// FailoverDriver writes each entry to the first of an ordered list of drivers
+// that succeeds. A driver that fails is skipped for the backoff period, so a
+// full disk or an unreachable server is not retried on every entry. When all
+// drivers are in backoff, they are tried anyway rather than dropping the entry.
+type FailoverDriver struct {
+	children []*failoverChild
+	filter   *core.LevelFilter
+	backoff  time.Duration
+	mu       sync.Mutex
+	now      func() time.Time
+	closed   bool
+}
+
+// failoverChild tracks the health of one driver of the chain
+type failoverChild struct {
+	driver   core.Driver
+	retryAt  time.Time
+	failures int
+	lastErr  error
+}
+
+// NewFailoverDriver creates a new failover driver from a map of options. The
+// drivers are described, in order of preference, by the "drivers" option.
+func NewFailoverDriver(options map[string]interface{}) (core.Driver, error) {
+	filter, err := newLevelFilter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	backoff, err := optparse.Duration(options["backoff"], defaultFailoverBackoff)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backoff: %w", err)
+	}
+
+	children, err := createChildren(options["drivers"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid drivers: %w", err)
+	}
+	if len(children) == 0 {
+		return nil, fmt.Errorf("at least one driver is required")
+	}
+
+	driver := NewFailoverDriverWithBackoff(backoff, children...)
+	driver.filter = filter
+	return driver, nil
+}
+
+// NewFailoverDriverWithBackoff creates a failover chain of the given drivers,
+// in order of preference. A non-positive backoff uses the default.
+func NewFailoverDriverWithBackoff(backoff time.Duration, children ...core.Driver) *FailoverDriver {
+	if backoff <= 0 {
+		backoff = defaultFailoverBackoff
+	}
+
+	driver := &FailoverDriver{
+		backoff: backoff,
+		now:     time.Now,
+	}
+	for _, child := range children {
+		driver.children = append(driver.children, &failoverChild{driver: child})
+	}
+
+	return driver
+}
+
+// Log writes the entry to the first healthy driver that accepts it. If every
+// driver fails, the returned *core.MultiError lists each failure.
+func (d *FailoverDriver) Log(entry *core.LogEntry) error {
+	if !d.filter.Allows(entry.Level) {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return fmt.Errorf("driver is closed")
+	}
+
+	// Decide which drivers are in backoff up front, so that a driver failing
+	// in the first pass is not tried again in the second
+	now := d.now()
+	inBackoff := make([]bool, len(d.children))
+	for i, child := range d.children {
+		inBackoff[i] = now.Before(child.retryAt)
+	}
+
+	errs := &core.MultiError{}
+
+	// Healthy drivers first, then those in backoff as a last resort
+	for _, backoffPass := range []bool{false, true} {
+		for i, child := range d.children {
+			if inBackoff[i] != backoffPass || !driverEnabled(child.driver, entry.Level) {
+				continue
+			}
+
+			err := child.driver.Log(entry)
+			if err == nil {
+				child.retryAt = time.Time{}
+				return nil
+			}
+
+			child.retryAt = now.Add(d.backoff)
+			child.failures++
+			child.lastErr = err
+			addChildError(errs, i, child.driver, err)
+		}
+	}
+
+	return errorOrNil(errs)
+}
+
+// Name returns the driver name used in configuration
+func (d *FailoverDriver) Name() string {
+	return FailoverDriverName
+}
+
+// Enabled reports whether the driver and any of its drivers accept the level
+func (d *FailoverDriver) Enabled(level core.Level) bool {
+	if !d.filter.Allows(level) {
+		return false
+	}
+
+	for _, child := range d.children {
+		if driverEnabled(child.driver, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Close closes every driver of the chain. The returned *core.MultiError lists
+// the drivers that failed to write entries since the driver was created and
+// those that failed to close; a driver can appear in both.
+func (d *FailoverDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+
+	errs := &core.MultiError{}
+	for i, child := range d.children {
+		if child.failures > 0 {
+			addChildError(errs, i, child.driver, fmt.Errorf("%d failed writes, last: %w", child.failures, child.lastErr))
+		}
+		if err := child.driver.Close(); err != nil {
+			addChildError(errs, i, child.driver, fmt.Errorf("close failed: %w", err))
+		}
+	}
+
+	return errorOrNil(errs)
+}
@@ -0,0 +1,166 @@
+package drivers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+func TestFailoverDriver(t *testing.T) {
+	errDiskFull := errors.New("disk full")
+	primary := &recordingDriver{logErr: errDiskFull}
+	secondary := &recordingDriver{}
+
+	driver := NewFailoverDriverWithBackoff(time.Minute, primary, secondary)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	driver.now = func() time.Time { return now }
+
+	if err := driver.Log(&core.LogEntry{Level: core.Info, Message: "first"}); err != nil {
+		t.Fatalf("Expected the secondary driver to take the entry, got %v", err)
+	}
+
+	// The primary driver is skipped during the backoff, even once it recovers
+	primary.mu.Lock()
+	primary.logErr = nil
+	primary.mu.Unlock()
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "second"})
+
+	if got := secondary.messages(); len(got) != 2 {
+		t.Fatalf("Expected 2 entries on the secondary driver, got %v", got)
+	}
+
+	// After the backoff the primary driver is tried again
+	now = now.Add(time.Minute)
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "third"})
+
+	if got := primary.messages(); len(got) != 1 || got[0] != "third" {
+		t.Fatalf("Expected the primary driver to be used again, got %v", got)
+	}
+
+	err := driver.Close()
+	var multi *core.MultiError
+	if !errors.As(err, &multi) || len(multi.Errors) != 1 || multi.Errors[0].Index != 0 {
+		t.Fatalf("Expected Close to report the primary driver, got %v", err)
+	}
+	if !errors.Is(err, errDiskFull) || !strings.Contains(err.Error(), "1 failed writes") {
+		t.Errorf("Unexpected Close error: %v", err)
+	}
+	if !primary.closed || !secondary.closed {
+		t.Error("Expected every driver to be closed")
+	}
+}
+
+func TestFailoverDriverAllFailing(t *testing.T) {
+	errFirst := errors.New("first failed")
+	errSecond := errors.New("second failed")
+	first := &recordingDriver{logErr: errFirst}
+	second := &recordingDriver{logErr: errSecond}
+
+	driver := NewFailoverDriverWithBackoff(time.Minute, first, second)
+	defer driver.Close()
+
+	err := driver.Log(&core.LogEntry{Level: core.Error, Message: "lost"})
+	if !errors.Is(err, errFirst) || !errors.Is(err, errSecond) {
+		t.Fatalf("Expected both errors, got %v", err)
+	}
+
+	// Each driver is tried once, not again as a last resort after failing
+	var multi *core.MultiError
+	if !errors.As(err, &multi) || len(multi.Errors) != 2 {
+		t.Fatalf("Expected one error per driver, got %v", err)
+	}
+	if first.calls != 1 || second.calls != 1 {
+		t.Errorf("Expected one call per driver, got %d and %d", first.calls, second.calls)
+	}
+
+	// With every driver in backoff they are still tried in order
+	second.mu.Lock()
+	second.logErr = nil
+	second.mu.Unlock()
+
+	if err := driver.Log(&core.LogEntry{Level: core.Error, Message: "saved"}); err != nil {
+		t.Fatalf("Expected the entry to be written, got %v", err)
+	}
+	if got := second.messages(); len(got) != 1 || got[0] != "saved" {
+		t.Errorf("Expected the second driver to take the entry, got %v", got)
+	}
+}
+
+func TestFailoverDriverCloseReportsWritesAndClose(t *testing.T) {
+	errDiskFull := errors.New("disk full")
+	errClose := errors.New("close failed")
+	primary := &recordingDriver{logErr: errDiskFull, closeErr: errClose}
+	secondary := &recordingDriver{}
+
+	driver := NewFailoverDriverWithBackoff(time.Minute, primary, secondary)
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "entry"})
+
+	err := driver.Close()
+	var multi *core.MultiError
+	if !errors.As(err, &multi) || len(multi.Errors) != 2 {
+		t.Fatalf("Expected the failed writes and the close error, got %v", err)
+	}
+	if !errors.Is(err, errDiskFull) || !errors.Is(err, errClose) {
+		t.Errorf("Unexpected Close error: %v", err)
+	}
+}
+
+func TestFailoverDriverSkipsDisabledLevels(t *testing.T) {
+	errorsOnly := &recordingDriver{minLevel: core.Error, hasMinLevel: true}
+	everything := &recordingDriver{}
+
+	driver := NewFailoverDriverWithBackoff(time.Minute, errorsOnly, everything)
+	defer driver.Close()
+
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "info"})
+	driver.Log(&core.LogEntry{Level: core.Error, Message: "error"})
+
+	if got := errorsOnly.messages(); len(got) != 1 || got[0] != "error" {
+		t.Errorf("Unexpected entries on the first driver: %v", got)
+	}
+	if got := everything.messages(); len(got) != 1 || got[0] != "info" {
+		t.Errorf("Unexpected entries on the second driver: %v", got)
+	}
+}
+
+func TestFailoverDriverFromOptions(t *testing.T) {
+	dir := t.TempDir()
+
+	driver, err := Create(FailoverDriverName, map[string]interface{}{
+		"backoff": "5s",
+		"drivers": []interface{}{
+			map[string]interface{}{
+				"type":    JSONFileDriverName,
+				"options": map[string]interface{}{"file_path": dir + "/app.json"},
+			},
+			map[string]interface{}{
+				"type":      ConsoleDriverName,
+				"min_level": "error",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+	defer driver.Close()
+
+	failover := driver.(*FailoverDriver)
+	if failover.backoff != 5*time.Second || len(failover.children) != 2 {
+		t.Errorf("Unexpected driver: backoff %v, %d drivers", failover.backoff, len(failover.children))
+	}
+
+	invalid := []map[string]interface{}{
+		{},
+		{"drivers": []interface{}{}},
+		{"drivers": []interface{}{map[string]interface{}{"type": "unknown"}}},
+		{"drivers": []interface{}{map[string]interface{}{"type": ConsoleDriverName}}, "backoff": "later"},
+	}
+	for _, options := range invalid {
+		if _, err := Create(FailoverDriverName, options); err == nil {
+			t.Errorf("Expected an error for %v", options)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package drivers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+	"github.com/MaoDaGreith/logging/pkg/internal/optparse"
+)
+
+// newLevelFilter builds the level filter shared by all drivers from the
+// min_level, max_level, levels and exclude_levels options. Entries in levels
+// may be single levels ("warning") or inclusive ranges ("debug..info").
+func newLevelFilter(options map[string]interface{}) (*core.LevelFilter, error) {
+	var filterOptions []core.LevelFilterOption
+
+	// An invalid min_level has always been ignored, so keep accepting it
+	if levelStr, ok := options["min_level"].(string); ok {
+		if level, err := core.ParseLevel(levelStr); err == nil {
+			filterOptions = append(filterOptions, core.MinLevel(level))
+		}
+	}
+
+	if levelStr, ok := options["max_level"].(string); ok && levelStr != "" {
+		level, err := core.ParseLevel(levelStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_level: %w", err)
+		}
+		filterOptions = append(filterOptions, core.MaxLevel(level))
+	}
+
+	levels, err := optparse.StringSlice(options["levels"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid levels: %w", err)
+	}
+	for _, spec := range levels {
+		option, err := parseLevelSpec(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid levels: %w", err)
+		}
+		filterOptions = append(filterOptions, option)
+	}
+
+	excluded, err := optparse.StringSlice(options["exclude_levels"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid exclude_levels: %w", err)
+	}
+	for _, levelStr := range excluded {
+		level, err := core.ParseLevel(levelStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude_levels: %w", err)
+		}
+		filterOptions = append(filterOptions, core.ExcludeLevels(level))
+	}
+
+	return core.NewLevelFilter(filterOptions...), nil
+}
+
+// parseLevelSpec parses a single level or a "from..to" range
+func parseLevelSpec(spec string) (core.LevelFilterOption, error) {
+	if from, to, ok := strings.Cut(spec, ".."); ok {
+		fromLevel, err := core.ParseLevel(strings.TrimSpace(from))
+		if err != nil {
+			return nil, err
+		}
+		toLevel, err := core.ParseLevel(strings.TrimSpace(to))
+		if err != nil {
+			return nil, err
+		}
+		return core.LevelRange(fromLevel, toLevel), nil
+	}
+
+	level, err := core.ParseLevel(strings.TrimSpace(spec))
+	if err != nil {
+		return nil, err
+	}
+
+	return core.OnlyLevels(level), nil
+}
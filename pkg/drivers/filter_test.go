@@ -0,0 +1,162 @@
+package drivers
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+func TestNewLevelFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  map[string]interface{}
+		accepted []core.Level
+		rejected []core.Level
+		wantErr  bool
+	}{
+		{
+			name:     "no options",
+			options:  map[string]interface{}{},
+			accepted: []core.Level{core.Trace, core.Panic},
+		},
+		{
+			name:     "invalid min level is ignored",
+			options:  map[string]interface{}{"min_level": "invalid"},
+			accepted: []core.Level{core.Trace},
+		},
+		{
+			name:     "max level",
+			options:  map[string]interface{}{"max_level": "info"},
+			accepted: []core.Level{core.Debug, core.Info},
+			rejected: []core.Level{core.Warning},
+		},
+		{
+			name:     "levels from yaml list",
+			options:  map[string]interface{}{"levels": []interface{}{"warning"}},
+			accepted: []core.Level{core.Warning},
+			rejected: []core.Level{core.Info, core.Error},
+		},
+		{
+			name:     "levels with range",
+			options:  map[string]interface{}{"levels": []string{"debug..info", "critical"}},
+			accepted: []core.Level{core.Debug, core.Info, core.Critical},
+			rejected: []core.Level{core.Trace, core.Warning},
+		},
+		{
+			name:     "comma-separated exclusions",
+			options:  map[string]interface{}{"min_level": "info", "exclude_levels": "notice, warning"},
+			accepted: []core.Level{core.Info, core.Error},
+			rejected: []core.Level{core.Notice, core.Warning},
+		},
+		{
+			name:    "invalid max level",
+			options: map[string]interface{}{"max_level": "loud"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid range",
+			options: map[string]interface{}{"levels": []interface{}{"debug..loud"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid levels type",
+			options: map[string]interface{}{"levels": 3},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := newLevelFilter(tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newLevelFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for _, level := range tt.accepted {
+				if !filter.Allows(level) {
+					t.Errorf("Allows(%v) = false, want true", level)
+				}
+			}
+			for _, level := range tt.rejected {
+				if filter.Allows(level) {
+					t.Errorf("Allows(%v) = true, want false", level)
+				}
+			}
+		})
+	}
+}
+
+func TestConsoleDriverLevelFilter(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	driver := NewConsoleDriverWithOptions(
+		WithStdout(&stdout),
+		WithStderr(&stderr),
+		WithLevelFilter(core.NewLevelFilter(core.OnlyLevels(core.Warning))),
+	)
+
+	for _, level := range []core.Level{core.Info, core.Warning, core.Error} {
+		err := driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: level, Message: "message"})
+		if err != nil {
+			t.Errorf("Log() error = %v", err)
+		}
+	}
+
+	if stdout.Len() == 0 {
+		t.Error("Expected warning output")
+	}
+	if bytes.Contains(stdout.Bytes(), []byte("INFO")) {
+		t.Error("Expected info entry to be filtered")
+	}
+	if stderr.Len() != 0 {
+		t.Error("Expected error entry to be filtered")
+	}
+}
+
+func TestDriverCreationWithInvalidLevelFilter(t *testing.T) {
+	tempDir := t.TempDir()
+
+	for _, driverType := range []string{"console", "json_file", "text_file"} {
+		t.Run(driverType, func(t *testing.T) {
+			driver, err := Create(driverType, map[string]interface{}{
+				"file_path": tempDir + "/test." + driverType,
+				"levels":    []interface{}{"nonsense"},
+			})
+			if err == nil {
+				t.Error("Expected error but got none")
+			}
+			if driver != nil {
+				t.Error("Expected nil driver when error occurs")
+			}
+		})
+	}
+}
+
+func TestDriversEnabled(t *testing.T) {
+	tempDir := t.TempDir()
+
+	for _, driverType := range []string{"console", "json_file", "text_file"} {
+		t.Run(driverType, func(t *testing.T) {
+			driver, err := Create(driverType, map[string]interface{}{
+				"file_path": tempDir + "/enabled." + driverType,
+				"min_level": "warning",
+			})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			defer driver.Close()
+
+			enabler, ok := driver.(core.LevelEnabler)
+			if !ok {
+				t.Fatal("Driver does not implement core.LevelEnabler")
+			}
+			if enabler.Enabled(core.Info) {
+				t.Error("Enabled(Info) = true, want false")
+			}
+			if !enabler.Enabled(core.Error) {
+				t.Error("Enabled(Error) = false, want true")
+			}
+		})
+	}
+}
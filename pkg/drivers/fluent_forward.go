@@ -0,0 +1,615 @@
+package drivers
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+	"github.com/MaoDaGreith/logging/pkg/internal/optparse"
+)
+
+// FluentForwardDriverName is the name to use in configuration
+const FluentForwardDriverName = "fluent_forward"
+
+func init() {
+	Register(FluentForwardDriverName, NewFluentForwardDriver)
+}
+
+// Forward protocol modes
+const (
+	// FluentMessage sends each entry as its own [tag, time, record] message
+	FluentMessage = "message"
+	// FluentForward sends the entries of a tag as an array of [time, record]
+	FluentForward = "forward"
+	// FluentPackedForward sends the entries of a tag as one binary stream of
+	// [time, record] values
+	FluentPackedForward = "packed_forward"
+)
+
+// Forward protocol defaults
+const (
+	defaultFluentTag        = "app"
+	defaultFluentTimeout    = 5 * time.Second
+	defaultFluentAckTimeout = 30 * time.Second
+)
+
+// FluentForwardConfig configures a FluentForwardDriver
+type FluentForwardConfig struct {
+	// Network is tcp, tcp+tls or unix
+	Network string
+
+	// Address is the host:port or socket path of the forward input
+	Address string
+
+	// Mode is FluentMessage, FluentForward or FluentPackedForward
+	Mode string
+
+	// Tag is the tag of entries without a TagAttribute value
+	Tag string
+
+	// TagAttribute names the attribute holding the tag of an entry
+	TagAttribute string
+
+	// RequireAck makes the server acknowledge every message, which is
+	// resent until it is
+	RequireAck bool
+
+	// AckTimeout bounds the wait for an acknowledgement
+	AckTimeout time.Duration
+
+	// SharedKey enables the handshake of a secured forward input
+	SharedKey string
+
+	// Username and Password are sent in the handshake when the input
+	// requires user authentication
+	Username string
+	Password string
+
+	// Hostname identifies the client in the handshake; defaults to the name
+	// of the machine
+	Hostname string
+
+	// TLSConfig is used by the tcp+tls network
+	TLSConfig *tls.Config
+
+	// Timeout bounds connecting, the handshake and writing a message
+	Timeout time.Duration
+
+	Batch BatchConfig
+	Retry RetryConfig
+}
+
+// FluentForwardDriver sends batches of entries to a Fluentd or Fluent Bit
+// forward input from a background goroutine. Entries are MessagePack
+// records with the time as an EventTime. A failed batch is resent on a new
+// connection; with RequireAck this gives at-least-once delivery, without it
+// entries written to a connection the server already dropped are lost.
+type FluentForwardDriver struct {
+	config  FluentForwardConfig
+	filter  *core.LevelFilter
+	batcher *batcher
+	sleep   func(time.Duration)
+
+	// conn is only used by the background goroutine
+	conn *fluentConn
+}
+
+// fluentConn is a connection to a forward input
+type fluentConn struct {
+	net.Conn
+	reader *bufio.Reader
+
+	// keepalive is false when the server closes the connection after each
+	// message
+	keepalive bool
+}
+
+// fluentMessage is an encoded message and the chunk ID acknowledging it
+type fluentMessage struct {
+	data  []byte
+	chunk string
+}
+
+// NewFluentForwardDriver creates a new Fluent forward driver from a map of
+// options
+func NewFluentForwardDriver(options map[string]interface{}) (core.Driver, error) {
+	filter, err := newLevelFilter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	config := FluentForwardConfig{Network: "tcp"}
+	if network, ok := options["network"].(string); ok {
+		config.Network = network
+	}
+	if address, ok := options["address"].(string); ok {
+		config.Address = address
+	}
+	if mode, ok := options["mode"].(string); ok {
+		config.Mode = mode
+	}
+	if tag, ok := options["tag"].(string); ok {
+		config.Tag = tag
+	}
+	if attribute, ok := options["tag_attribute"].(string); ok {
+		config.TagAttribute = attribute
+	}
+	if key, ok := options["shared_key"].(string); ok {
+		config.SharedKey = key
+	}
+	if username, ok := options["username"].(string); ok {
+		config.Username = username
+	}
+	if password, ok := options["password"].(string); ok {
+		config.Password = password
+	}
+	if hostname, ok := options["hostname"].(string); ok {
+		config.Hostname = hostname
+	}
+
+	if config.RequireAck, err = optparse.Bool(options["require_ack"], false); err != nil {
+		return nil, fmt.Errorf("invalid require_ack: %w", err)
+	}
+	if config.AckTimeout, err = optparse.Duration(options["ack_timeout"], defaultFluentAckTimeout); err != nil {
+		return nil, fmt.Errorf("invalid ack_timeout: %w", err)
+	}
+	if config.Timeout, err = optparse.Duration(options["timeout"], defaultFluentTimeout); err != nil {
+		return nil, fmt.Errorf("invalid timeout: %w", err)
+	}
+	if config.Retry.MaxRetries, err = optparse.Int(options["max_retries"], defaultMaxRetries); err != nil {
+		return nil, fmt.Errorf("invalid max_retries: %w", err)
+	}
+	if config.Retry.InitialBackoff, err = optparse.Duration(options["initial_backoff"], defaultInitialBackoff); err != nil {
+		return nil, fmt.Errorf("invalid initial_backoff: %w", err)
+	}
+	if config.Retry.MaxBackoff, err = optparse.Duration(options["max_backoff"], defaultMaxBackoff); err != nil {
+		return nil, fmt.Errorf("invalid max_backoff: %w", err)
+	}
+	if config.Batch, err = newBatchConfig(options); err != nil {
+		return nil, err
+	}
+
+	if config.Network == "tcp+tls" {
+		if config.TLSConfig, err = newTLSConfig(options); err != nil {
+			return nil, err
+		}
+	}
+
+	driver, err := NewFluentForwardDriverWithConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	driver.filter = filter
+
+	return driver, nil
+}
+
+// NewFluentForwardDriverWithConfig creates a Fluent forward driver and starts
+// its background goroutine, which connects on the first batch
+func NewFluentForwardDriverWithConfig(config FluentForwardConfig) (*FluentForwardDriver, error) {
+	switch config.Network {
+	case "":
+		config.Network = "tcp"
+	case "tcp", "tcp+tls", "unix":
+	default:
+		return nil, fmt.Errorf("unknown network: %s", config.Network)
+	}
+
+	if config.Address == "" {
+		return nil, fmt.Errorf("address is required")
+	}
+
+	switch config.Mode {
+	case "":
+		config.Mode = FluentForward
+	case FluentMessage, FluentForward, FluentPackedForward:
+	default:
+		return nil, fmt.Errorf("unknown mode: %s", config.Mode)
+	}
+
+	if config.Tag == "" {
+		config.Tag = defaultFluentTag
+	}
+	if config.Hostname == "" {
+		config.Hostname, _ = os.Hostname()
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultFluentTimeout
+	}
+	if config.AckTimeout <= 0 {
+		config.AckTimeout = defaultFluentAckTimeout
+	}
+	if config.TLSConfig == nil && config.Network == "tcp+tls" {
+		config.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	config.Retry = config.Retry.withDefaults()
+
+	driver := &FluentForwardDriver{
+		config: config,
+		sleep:  time.Sleep,
+	}
+	driver.batcher = newBatcher(config.Batch, driver.send)
+
+	return driver, nil
+}
+
+// Log queues the entry for the next batch
+func (d *FluentForwardDriver) Log(entry *core.LogEntry) error {
+	if !d.filter.Allows(entry.Level) {
+		return nil
+	}
+
+	return d.batcher.add(entry)
+}
+
+// Flush sends the queued entries and waits for them to be written, and
+// acknowledged when RequireAck is set
+func (d *FluentForwardDriver) Flush() error {
+	return d.batcher.flush()
+}
+
+// Close sends the queued entries, stops the background goroutine and closes
+// the connection
+func (d *FluentForwardDriver) Close() error {
+	err := d.batcher.close()
+	d.disconnect()
+	return err
+}
+
+// Name returns the driver name
+func (d *FluentForwardDriver) Name() string {
+	return FluentForwardDriverName
+}
+
+// Enabled reports whether the driver accepts entries at the level
+func (d *FluentForwardDriver) Enabled(level core.Level) bool {
+	return d.filter.Allows(level)
+}
+
+// send writes the messages of one batch, retrying on a new connection from
+// the first message that was not written or acknowledged
+func (d *FluentForwardDriver) send(batch []*core.LogEntry) error {
+	pending, err := d.encode(batch)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		for err = nil; err == nil && len(pending) > 0; {
+			if err = d.write(pending[0]); err == nil {
+				pending = pending[1:]
+			}
+		}
+		if err == nil {
+			if d.conn != nil && !d.conn.keepalive {
+				d.disconnect()
+			}
+			return nil
+		}
+
+		d.disconnect()
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) || attempt >= d.config.Retry.MaxRetries {
+			return fmt.Errorf("failed to send %d entries: %w", len(batch), err)
+		}
+		d.sleep(d.config.Retry.backoff(attempt))
+	}
+}
+
+// write writes one message, connecting first if needed, and waits for its
+// acknowledgement
+func (d *FluentForwardDriver) write(message fluentMessage) error {
+	if d.conn == nil {
+		conn, err := d.connect()
+		if err != nil {
+			return err
+		}
+		d.conn = conn
+	}
+
+	d.conn.SetWriteDeadline(time.Now().Add(d.config.Timeout))
+	if _, err := d.conn.Write(message.data); err != nil {
+		return fmt.Errorf("failed to write: %w", err)
+	}
+
+	if message.chunk == "" {
+		return nil
+	}
+
+	d.conn.SetReadDeadline(time.Now().Add(d.config.AckTimeout))
+	response, err := readMsgpack(d.conn.reader)
+	if err != nil {
+		return fmt.Errorf("failed to read ack: %w", err)
+	}
+	if ack, _ := response.(map[string]interface{}); msgpackText(ack["ack"]) != message.chunk {
+		return fmt.Errorf("unexpected ack response: %v", response)
+	}
+
+	return nil
+}
+
+// connect dials the forward input and performs the handshake when a shared
+// key is configured
+func (d *FluentForwardDriver) connect() (*fluentConn, error) {
+	dialer := &net.Dialer{Timeout: d.config.Timeout}
+
+	var conn net.Conn
+	var err error
+	if d.config.Network == "tcp+tls" {
+		var tlsConn *tls.Conn
+		if tlsConn, err = tls.DialWithDialer(dialer, "tcp", d.config.Address, d.config.TLSConfig); err == nil {
+			conn = tlsConn
+		}
+	} else {
+		conn, err = dialer.Dial(d.config.Network, d.config.Address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	c := &fluentConn{Conn: conn, reader: bufio.NewReader(conn), keepalive: true}
+	if d.config.SharedKey != "" {
+		if err := d.handshake(c); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// handshake answers the HELO of a secured input with a PING proving the
+// shared key, and checks that the PONG proves it as well. Authentication
+// failures are permanent.
+func (d *FluentForwardDriver) handshake(c *fluentConn) error {
+	c.SetDeadline(time.Now().Add(d.config.Timeout))
+	defer c.SetDeadline(time.Time{})
+
+	helo, err := readMsgpack(c.reader)
+	if err != nil {
+		return fmt.Errorf("failed to read HELO: %w", err)
+	}
+	fields, _ := helo.([]interface{})
+	if len(fields) < 2 || fields[0] != "HELO" {
+		return &permanentError{fmt.Errorf("unexpected handshake message: %v", helo)}
+	}
+	options, _ := fields[1].(map[string]interface{})
+	nonce := msgpackText(options["nonce"])
+	authSalt := msgpackText(options["auth"])
+	if keepalive, ok := options["keepalive"].(bool); ok {
+		c.keepalive = keepalive
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+	sharedKeySalt := hex.EncodeToString(salt)
+
+	var passwordDigest string
+	if authSalt != "" {
+		passwordDigest = fluentDigest(authSalt, d.config.Username, d.config.Password)
+	}
+
+	var ping msgpackBuffer
+	ping.arrayHeader(6)
+	ping.string("PING")
+	ping.string(d.config.Hostname)
+	ping.string(sharedKeySalt)
+	ping.string(fluentDigest(sharedKeySalt, d.config.Hostname, nonce, d.config.SharedKey))
+	ping.string(d.config.Username)
+	ping.string(passwordDigest)
+	if _, err := c.Write(ping); err != nil {
+		return fmt.Errorf("failed to write PING: %w", err)
+	}
+
+	pong, err := readMsgpack(c.reader)
+	if err != nil {
+		return fmt.Errorf("failed to read PONG: %w", err)
+	}
+	fields, _ = pong.([]interface{})
+	if len(fields) < 5 || fields[0] != "PONG" {
+		return &permanentError{fmt.Errorf("unexpected handshake message: %v", pong)}
+	}
+	if ok, _ := fields[1].(bool); !ok {
+		return &permanentError{fmt.Errorf("authentication failed: %s", msgpackText(fields[2]))}
+	}
+	if msgpackText(fields[4]) != fluentDigest(sharedKeySalt, msgpackText(fields[3]), nonce, d.config.SharedKey) {
+		return &permanentError{fmt.Errorf("server failed the shared key check")}
+	}
+
+	return nil
+}
+
+// disconnect closes the connection, if any
+func (d *FluentForwardDriver) disconnect() {
+	if d.conn == nil {
+		return
+	}
+
+	d.conn.Close()
+	d.conn = nil
+}
+
+// encode builds the messages of a batch in the configured mode. Entries are
+// grouped by tag, keeping the order of each tag's entries.
+func (d *FluentForwardDriver) encode(batch []*core.LogEntry) ([]fluentMessage, error) {
+	var tags []string
+	groups := make(map[string][]*core.LogEntry)
+	for _, entry := range batch {
+		tag := d.config.Tag
+		if d.config.TagAttribute != "" && entry.Attrs[d.config.TagAttribute] != "" {
+			tag = entry.Attrs[d.config.TagAttribute]
+		}
+		if _, ok := groups[tag]; !ok {
+			tags = append(tags, tag)
+		}
+		groups[tag] = append(groups[tag], entry)
+	}
+
+	var messages []fluentMessage
+	for _, tag := range tags {
+		entries := groups[tag]
+
+		if d.config.Mode == FluentMessage {
+			for _, entry := range entries {
+				message, err := d.message(tag, []*core.LogEntry{entry})
+				if err != nil {
+					return nil, err
+				}
+				messages = append(messages, message)
+			}
+			continue
+		}
+
+		message, err := d.message(tag, entries)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+// message encodes one message of the configured mode. The option map holds
+// the number of entries in the forward modes and the chunk ID to
+// acknowledge.
+func (d *FluentForwardDriver) message(tag string, entries []*core.LogEntry) (fluentMessage, error) {
+	var message fluentMessage
+	if d.config.RequireAck {
+		id := make([]byte, 16)
+		if _, err := rand.Read(id); err != nil {
+			return message, fmt.Errorf("failed to generate chunk ID: %w", err)
+		}
+		message.chunk = base64.StdEncoding.EncodeToString(id)
+	}
+
+	var options int
+	if d.config.Mode != FluentMessage {
+		options++
+	}
+	if message.chunk != "" {
+		options++
+	}
+
+	// [tag, time, record] or [tag, entries], followed by the option map
+	length := 2
+	if d.config.Mode == FluentMessage {
+		length = 3
+	}
+	if options > 0 {
+		length++
+	}
+
+	var b msgpackBuffer
+	b.arrayHeader(length)
+	b.string(tag)
+
+	switch d.config.Mode {
+	case FluentMessage:
+		b.eventTime(entries[0].Timestamp)
+		fluentRecord(&b, entries[0])
+	case FluentForward:
+		b.arrayHeader(len(entries))
+		for _, entry := range entries {
+			fluentEntry(&b, entry)
+		}
+	case FluentPackedForward:
+		var stream msgpackBuffer
+		for _, entry := range entries {
+			fluentEntry(&stream, entry)
+		}
+		b.bin(stream)
+	}
+
+	if options > 0 {
+		b.mapHeader(options)
+		if d.config.Mode != FluentMessage {
+			b.string("size")
+			b.uint(uint64(len(entries)))
+		}
+		if message.chunk != "" {
+			b.string("chunk")
+			b.string(message.chunk)
+		}
+	}
+
+	message.data = b
+	return message, nil
+}
+
+// fluentEntry appends an entry as a [time, record] array
+func fluentEntry(b *msgpackBuffer, entry *core.LogEntry) {
+	b.arrayHeader(2)
+	b.eventTime(entry.Timestamp)
+	fluentRecord(b, entry)
+}
+
+// fluentRecord appends the record of an entry: the attributes as top-level
+// keys, with level, message, transaction_id, caller, function, error,
+// error_type, error_stack and stack taking precedence
+func fluentRecord(b *msgpackBuffer, entry *core.LogEntry) {
+	record := make(map[string]string, len(entry.Attrs)+2)
+	for key, value := range entry.Attrs {
+		record[key] = value
+	}
+
+	record["level"] = entry.Level.String()
+	record["message"] = entry.Message
+	if entry.TransactionID != "" {
+		record["transaction_id"] = entry.TransactionID
+	}
+	if entry.Caller != nil {
+		record["caller"] = formatCaller(entry.Caller)
+		record["function"] = entry.Caller.Function
+	}
+	if entry.Error != nil {
+		record["error"] = entry.Error.Message
+		if len(entry.Error.Chain) > 0 {
+			record["error_type"] = entry.Error.Chain[0].Type
+		}
+		if len(entry.Error.Stack) > 0 {
+			record["error_stack"] = strings.Join(formatFrames(entry.Error.Stack), "\n")
+		}
+	}
+	if len(entry.Stack) > 0 {
+		record["stack"] = strings.Join(formatFrames(entry.Stack), "\n")
+	}
+
+	b.mapHeader(len(record))
+	for _, key := range sortedKeys(record) {
+		b.string(key)
+		b.string(record[key])
+	}
+}
+
+// fluentDigest returns the hex SHA-512 digest of the concatenated values,
+// as the handshake uses for the shared key and the password
+func fluentDigest(values ...string) string {
+	sum := sha512.Sum512([]byte(strings.Join(values, "")))
+	return hex.EncodeToString(sum[:])
+}
+
+// msgpackText returns a string or binary value as a string
+func msgpackText(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return ""
+	}
+}
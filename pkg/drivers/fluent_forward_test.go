@@ -0,0 +1,535 @@
+package drivers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// fluentEvent is an entry received by fakeFluent
+type fluentEvent struct {
+	tag    string
+	time   time.Time
+	record map[string]interface{}
+}
+
+// fakeFluent is a forward input recording the events it receives. With a
+// shared key it performs the server side of the handshake.
+type fakeFluent struct {
+	listener net.Listener
+
+	// mu guards the settings and what was received
+	mu sync.Mutex
+
+	// sharedKey enables the handshake; pongKey, when set, is used to answer
+	// instead, as by a server not knowing the key
+	sharedKey string
+	pongKey   string
+
+	// dropAcks is the number of acknowledged messages to read and then drop
+	// the connection instead of acknowledging; ignoreAcks never answers
+	dropAcks   int
+	ignoreAcks bool
+
+	events     []fluentEvent
+	options    []map[string]interface{}
+	handshakes int
+}
+
+func newFakeFluent(t *testing.T, network, address string) *fakeFluent {
+	t.Helper()
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	f := &fakeFluent{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+
+	return f
+}
+
+func (f *fakeFluent) address() string {
+	return f.listener.Addr().String()
+}
+
+// serve reads messages from one connection
+func (f *fakeFluent) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	f.mu.Lock()
+	sharedKey := f.sharedKey
+	f.mu.Unlock()
+	if sharedKey != "" && !f.handshake(conn, reader) {
+		return
+	}
+
+	for {
+		v, err := readMsgpack(reader)
+		if err != nil {
+			return
+		}
+		message, _ := v.([]interface{})
+		if len(message) < 2 {
+			return
+		}
+
+		events, options := decodeFluentMessage(message)
+
+		f.mu.Lock()
+		f.events = append(f.events, events...)
+		f.options = append(f.options, options)
+		drop := f.dropAcks > 0
+		if drop {
+			f.dropAcks--
+		}
+		ignore := f.ignoreAcks
+		f.mu.Unlock()
+
+		chunk := msgpackText(options["chunk"])
+		if chunk == "" || ignore {
+			continue
+		}
+		if drop {
+			return
+		}
+
+		var ack msgpackBuffer
+		ack.mapHeader(1)
+		ack.string("ack")
+		ack.string(chunk)
+		conn.Write(ack)
+	}
+}
+
+// handshake sends a HELO, checks the PING and answers with a PONG
+func (f *fakeFluent) handshake(conn net.Conn, reader *bufio.Reader) bool {
+	f.mu.Lock()
+	f.handshakes++
+	sharedKey, pongKey := f.sharedKey, f.pongKey
+	f.mu.Unlock()
+
+	const nonce = "test-nonce"
+	var helo msgpackBuffer
+	helo.arrayHeader(2)
+	helo.string("HELO")
+	helo.mapHeader(3)
+	helo.string("nonce")
+	helo.bin([]byte(nonce))
+	helo.string("auth")
+	helo.string("")
+	helo.string("keepalive")
+	helo = append(helo, 0xc3)
+	conn.Write(helo)
+
+	v, err := readMsgpack(reader)
+	if err != nil {
+		return false
+	}
+	ping, _ := v.([]interface{})
+	if len(ping) != 6 || ping[0] != "PING" {
+		return false
+	}
+	hostname, salt := msgpackText(ping[1]), msgpackText(ping[2])
+	authenticated := msgpackText(ping[3]) == fluentDigest(salt, hostname, nonce, sharedKey)
+
+	if pongKey == "" {
+		pongKey = sharedKey
+	}
+
+	var pong msgpackBuffer
+	pong.arrayHeader(5)
+	pong.string("PONG")
+	if authenticated {
+		pong = append(pong, 0xc3)
+		pong.string("")
+	} else {
+		pong = append(pong, 0xc2)
+		pong.string("shared_key mismatch")
+	}
+	pong.string("fluentd-1")
+	pong.string(fluentDigest(salt, "fluentd-1", nonce, pongKey))
+	conn.Write(pong)
+
+	return authenticated
+}
+
+// decodeFluentMessage returns the events and options of a message in any
+// of the three modes
+func decodeFluentMessage(message []interface{}) ([]fluentEvent, map[string]interface{}) {
+	tag := msgpackText(message[0])
+
+	var events []fluentEvent
+	var options map[string]interface{}
+	switch entries := message[1].(type) {
+	case msgpackExt:
+		events = append(events, fluentEvent{tag: tag, time: decodeEventTime(entries), record: asMap(message[2])})
+		if len(message) > 3 {
+			options = asMap(message[3])
+		}
+	case []interface{}:
+		for _, entry := range entries {
+			events = append(events, decodeFluentEntry(tag, entry))
+		}
+		options = asMap(message[2])
+	case []byte:
+		reader := bufio.NewReader(bytes.NewReader(entries))
+		for {
+			entry, err := readMsgpack(reader)
+			if err != nil {
+				break
+			}
+			events = append(events, decodeFluentEntry(tag, entry))
+		}
+		options = asMap(message[2])
+	}
+
+	if options == nil {
+		options = map[string]interface{}{}
+	}
+	return events, options
+}
+
+func decodeFluentEntry(tag string, entry interface{}) fluentEvent {
+	pair, _ := entry.([]interface{})
+	ext, _ := pair[0].(msgpackExt)
+	return fluentEvent{tag: tag, time: decodeEventTime(ext), record: asMap(pair[1])}
+}
+
+func decodeEventTime(ext msgpackExt) time.Time {
+	if ext.Type != msgpackEventTimeType || len(ext.Data) != 8 {
+		return time.Time{}
+	}
+	return time.Unix(int64(binary.BigEndian.Uint32(ext.Data)), int64(binary.BigEndian.Uint32(ext.Data[4:])))
+}
+
+func asMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func (f *fakeFluent) received() ([]fluentEvent, []map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]fluentEvent(nil), f.events...), append([]map[string]interface{}(nil), f.options...)
+}
+
+func newTestFluentDriver(t *testing.T, options map[string]interface{}) *FluentForwardDriver {
+	t.Helper()
+
+	options["linger"] = "1h"
+	options["initial_backoff"] = "1ms"
+	options["max_backoff"] = "5ms"
+
+	driver, err := Create(FluentForwardDriverName, options)
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+	t.Cleanup(func() { driver.Close() })
+
+	return driver.(*FluentForwardDriver)
+}
+
+// waitForEvents waits until the server has received n events, since
+// without acknowledgements Flush returns once they are written
+func waitForEvents(t *testing.T, f *fakeFluent, n int) ([]fluentEvent, []map[string]interface{}) {
+	t.Helper()
+
+	waitFor(t, "the events", func() bool {
+		events, _ := f.received()
+		return len(events) >= n
+	})
+	return f.received()
+}
+
+func TestFluentForwardDriverModes(t *testing.T) {
+	timestamp := time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)
+
+	tests := []struct {
+		mode     string
+		messages int
+	}{
+		{mode: "message", messages: 3},
+		{mode: "forward", messages: 2},
+		{mode: "packed_forward", messages: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.mode, func(t *testing.T) {
+			server := newFakeFluent(t, "tcp", "127.0.0.1:0")
+			driver := newTestFluentDriver(t, map[string]interface{}{
+				"address":       server.address(),
+				"mode":          test.mode,
+				"tag":           "web",
+				"tag_attribute": "fluent_tag",
+			})
+
+			driver.Log(&core.LogEntry{Timestamp: timestamp, Level: core.Info, Message: "one"})
+			driver.Log(&core.LogEntry{Timestamp: timestamp, Level: core.Warning, Message: "audit", Attrs: core.Attributes{"fluent_tag": "audit"}})
+			driver.Log(&core.LogEntry{Timestamp: timestamp, Level: core.Info, Message: "two"})
+			if err := driver.Flush(); err != nil {
+				t.Fatalf("Flush failed: %v", err)
+			}
+
+			events, options := waitForEvents(t, server, 3)
+			want := []struct{ tag, message string }{{"web", "one"}, {"web", "two"}, {"audit", "audit"}}
+			for i, w := range want {
+				if events[i].tag != w.tag || events[i].record["message"] != w.message {
+					t.Errorf("event %d = %s %v, want %s %s", i, events[i].tag, events[i].record, w.tag, w.message)
+				}
+				if !events[i].time.Equal(timestamp) {
+					t.Errorf("event %d time = %v, want %v", i, events[i].time, timestamp)
+				}
+			}
+
+			if len(options) != test.messages {
+				t.Fatalf("got %d messages, want %d", len(options), test.messages)
+			}
+			if test.mode != "message" && (options[0]["size"] != int64(2) || options[1]["size"] != int64(1)) {
+				t.Errorf("options = %v, want the sizes", options)
+			}
+		})
+	}
+}
+
+func TestFluentForwardDriverRecord(t *testing.T) {
+	server := newFakeFluent(t, "tcp", "127.0.0.1:0")
+	driver := newTestFluentDriver(t, map[string]interface{}{"address": server.address()})
+
+	driver.Log(&core.LogEntry{
+		Timestamp:     time.Now(),
+		Level:         core.Error,
+		Message:       "charge failed",
+		TransactionID: "tx-1",
+		Attrs:         core.Attributes{"user.id": "42", "level": "shadowed"},
+		Caller:        &core.Frame{Function: "main.charge", File: "/app/main.go", Line: 12},
+		Error: &core.ErrorInfo{
+			Message: "card declined",
+			Chain:   []core.ErrorCause{{Type: "*errors.errorString", Message: "card declined"}},
+			Stack:   []core.Frame{{Function: "main.charge", File: "/app/main.go", Line: 10}},
+		},
+		Stack: []core.Frame{{Function: "main.main", File: "/app/main.go", Line: 20}},
+	})
+	driver.Flush()
+
+	events, _ := waitForEvents(t, server, 1)
+	want := map[string]interface{}{
+		"level":          "ERROR",
+		"message":        "charge failed",
+		"user.id":        "42",
+		"transaction_id": "tx-1",
+		"caller":         "/app/main.go:12",
+		"function":       "main.charge",
+		"error":          "card declined",
+		"error_type":     "*errors.errorString",
+		"error_stack":    "main.charge (/app/main.go:10)",
+		"stack":          "main.main (/app/main.go:20)",
+	}
+	record := events[0].record
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("%s = %#v, want %#v", key, record[key], value)
+		}
+	}
+	if len(record) != len(want) {
+		t.Errorf("record has %d fields, want %d: %v", len(record), len(want), record)
+	}
+}
+
+func TestFluentForwardDriverAck(t *testing.T) {
+	server := newFakeFluent(t, "tcp", "127.0.0.1:0")
+	server.mu.Lock()
+	server.dropAcks = 1
+	server.mu.Unlock()
+
+	driver := newTestFluentDriver(t, map[string]interface{}{
+		"address":     server.address(),
+		"require_ack": true,
+	})
+
+	driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: "important"})
+	if err := driver.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	// The unacknowledged message was sent again on a new connection
+	events, options := server.received()
+	if len(events) != 2 || events[1].record["message"] != "important" {
+		t.Fatalf("events = %v, want the message twice", events)
+	}
+	if chunk := msgpackText(options[0]["chunk"]); chunk == "" || chunk != msgpackText(options[1]["chunk"]) {
+		t.Errorf("chunks = %v and %v, want the same chunk resent", options[0]["chunk"], options[1]["chunk"])
+	}
+}
+
+func TestFluentForwardDriverAckTimeout(t *testing.T) {
+	server := newFakeFluent(t, "tcp", "127.0.0.1:0")
+	server.mu.Lock()
+	server.ignoreAcks = true
+	server.mu.Unlock()
+
+	driver := newTestFluentDriver(t, map[string]interface{}{
+		"address":     server.address(),
+		"require_ack": true,
+		"ack_timeout": "50ms",
+		"max_retries": 1,
+	})
+
+	driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: "lost"})
+	err := driver.Flush()
+	if err == nil || !strings.Contains(err.Error(), "failed to read ack") {
+		t.Fatalf("Flush() error = %v, want an ack error", err)
+	}
+
+	if events, _ := server.received(); len(events) != 2 {
+		t.Errorf("got %d events, want the message and one retry", len(events))
+	}
+}
+
+func TestFluentForwardDriverSharedKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		clientKey string
+		pongKey   string
+		wantErr   string
+	}{
+		{name: "valid", clientKey: "secret"},
+		{name: "wrong key", clientKey: "guess", wantErr: "authentication failed: shared_key mismatch"},
+		{name: "impostor server", clientKey: "secret", pongKey: "other", wantErr: "shared key check"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newFakeFluent(t, "tcp", "127.0.0.1:0")
+			server.mu.Lock()
+			server.sharedKey = "secret"
+			server.pongKey = test.pongKey
+			server.mu.Unlock()
+
+			driver := newTestFluentDriver(t, map[string]interface{}{
+				"address":     server.address(),
+				"shared_key":  test.clientKey,
+				"hostname":    "web-1",
+				"require_ack": true,
+			})
+
+			driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: "secured"})
+			err := driver.Flush()
+
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Flush failed: %v", err)
+				}
+				if events, _ := server.received(); len(events) != 1 {
+					t.Errorf("got %d events, want 1", len(events))
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("Flush() error = %v, want %q", err, test.wantErr)
+			}
+			// Authentication failures are not retried
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			if server.handshakes != 1 {
+				t.Errorf("got %d handshakes, want 1", server.handshakes)
+			}
+		})
+	}
+}
+
+func TestFluentForwardDriverReconnects(t *testing.T) {
+	server := newFakeFluent(t, "tcp", "127.0.0.1:0")
+	driver := newTestFluentDriver(t, map[string]interface{}{
+		"address":     server.address(),
+		"require_ack": true,
+	})
+
+	driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: "first"})
+	if err := driver.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	// Drop the connection after the next message, as by a restarting server
+	server.mu.Lock()
+	server.dropAcks = 1
+	server.mu.Unlock()
+
+	driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: "second"})
+	if err := driver.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	events, _ := server.received()
+	if len(events) != 3 || events[2].record["message"] != "second" {
+		t.Errorf("events = %v, want second resent", events)
+	}
+}
+
+func TestFluentForwardDriverUnix(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix sockets are not tested on Windows")
+	}
+
+	server := newFakeFluent(t, "unix", filepath.Join(t.TempDir(), "fluent.sock"))
+	driver := newTestFluentDriver(t, map[string]interface{}{
+		"network":     "unix",
+		"address":     server.address(),
+		"mode":        "message",
+		"require_ack": true,
+	})
+
+	driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: "local"})
+	if err := driver.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	if events, _ := server.received(); len(events) != 1 || events[0].tag != "app" {
+		t.Errorf("events = %v, want one with the default tag", events)
+	}
+}
+
+func TestNewFluentForwardDriverInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+	}{
+		{name: "missing address", options: map[string]interface{}{}},
+		{name: "network", options: map[string]interface{}{"address": "localhost:24224", "network": "udp"}},
+		{name: "mode", options: map[string]interface{}{"address": "localhost:24224", "mode": "compressed"}},
+		{name: "require_ack", options: map[string]interface{}{"address": "localhost:24224", "require_ack": "sometimes"}},
+		{name: "ack_timeout", options: map[string]interface{}{"address": "localhost:24224", "ack_timeout": "soon"}},
+		{name: "batch_size", options: map[string]interface{}{"address": "localhost:24224", "batch_size": "many"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if driver, err := Create(FluentForwardDriverName, test.options); err == nil {
+				driver.Close()
+				t.Error("Create succeeded, want an error")
+			}
+		})
+	}
+}
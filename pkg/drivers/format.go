@@ -0,0 +1,74 @@
+package drivers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// formatCaller renders a call site as "file:line"
+func formatCaller(frame *core.Frame) string {
+	return frame.File + ":" + strconv.Itoa(frame.Line)
+}
+
+// formatCallerFunction renders a call site as "file:line function" for the
+// text formats, which have no separate function field
+func formatCallerFunction(frame *core.Frame) string {
+	if frame.Function == "" {
+		return formatCaller(frame)
+	}
+	return formatCaller(frame) + " " + frame.Function
+}
+
+// formatFrames renders stack frames as "function (file:line)" strings
+func formatFrames(frames []core.Frame) []string {
+	if len(frames) == 0 {
+		return nil
+	}
+
+	result := make([]string, len(frames))
+	for i, frame := range frames {
+		result[i] = frame.String()
+	}
+
+	return result
+}
+
+// formatErrorChain renders an attached error as indented lines: the error
+// itself followed by one "caused by" line per wrapped error
+func formatErrorChain(info *core.ErrorInfo, indent string) string {
+	var builder strings.Builder
+
+	for i, cause := range info.Chain {
+		builder.WriteString("\n")
+		builder.WriteString(indent)
+		if i == 0 {
+			builder.WriteString("error: ")
+		} else {
+			builder.WriteString("caused by: ")
+		}
+		builder.WriteString(cause.Type)
+		builder.WriteString(": ")
+		builder.WriteString(cause.Message)
+	}
+
+	return builder.String()
+}
+
+// formatStack renders stack frames as an indented multi-line block
+func formatStack(frames []core.Frame, indent string) string {
+	var builder strings.Builder
+
+	builder.WriteString("\n")
+	builder.WriteString(indent)
+	builder.WriteString("stack:")
+	for _, frame := range frames {
+		builder.WriteString("\n")
+		builder.WriteString(indent)
+		builder.WriteString("    ")
+		builder.WriteString(frame.String())
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,429 @@
+package drivers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+	"github.com/MaoDaGreith/logging/pkg/internal/optparse"
+)
+
+// GELFDriverName is the name to use in configuration
+const GELFDriverName = "gelf"
+
+func init() {
+	Register(GELFDriverName, NewGELFDriver)
+}
+
+// GELF compressions of UDP messages
+const (
+	GELFGzip = "gzip"
+	GELFZlib = "zlib"
+	GELFNone = "none"
+)
+
+// GELF defaults
+const (
+	// defaultGELFChunkSize fits a datagram in the MTU of most networks
+	defaultGELFChunkSize = 1420
+	defaultGELFTimeout   = 5 * time.Second
+)
+
+// GELF chunking limits
+const (
+	gelfChunkHeaderSize = 12
+	gelfMaxChunks       = 128
+	gelfMaxChunkSize    = 65507
+)
+
+// gelfReservedFields are the additional fields set by the driver
+var gelfReservedFields = map[string]bool{
+	"_level_name":     true,
+	"_transaction_id": true,
+	"_file":           true,
+	"_line":           true,
+	"_function":       true,
+	"_error":          true,
+	"_error_type":     true,
+}
+
+// gelfChunkMagic starts every chunk of a chunked message
+var gelfChunkMagic = []byte{0x1e, 0x0f}
+
+// GELFConfig configures a GELFDriver
+type GELFConfig struct {
+	// Network is udp, tcp or tcp+tls
+	Network string
+
+	// Address is the host:port of the GELF input
+	Address string
+
+	// Host is the host field; defaults to the name of the machine
+	Host string
+
+	// Compression of UDP messages is GELFGzip, GELFZlib or GELFNone. TCP
+	// messages cannot be compressed.
+	Compression string
+
+	// ChunkSize is the largest UDP datagram sent, including the chunk header
+	ChunkSize int
+
+	// TLSConfig is used by the tcp+tls network
+	TLSConfig *tls.Config
+
+	// Timeout bounds connecting and writing a message
+	Timeout time.Duration
+}
+
+// GELFDriver sends entries to Graylog or another GELF input as GELF 1.1
+// messages. Over UDP, messages are compressed and split into chunks when
+// they do not fit in one datagram; over TCP, they are terminated by a null
+// byte. The connection is established on the first entry and re-established
+// once if a write fails.
+type GELFDriver struct {
+	config GELFConfig
+	filter *core.LevelFilter
+	mu     sync.Mutex
+	conn   net.Conn
+	closed bool
+}
+
+// NewGELFDriver creates a new GELF driver from a map of options
+func NewGELFDriver(options map[string]interface{}) (core.Driver, error) {
+	filter, err := newLevelFilter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	config := GELFConfig{Network: "udp"}
+	if network, ok := options["network"].(string); ok {
+		config.Network = network
+	}
+	if address, ok := options["address"].(string); ok {
+		config.Address = address
+	}
+	if host, ok := options["host"].(string); ok {
+		config.Host = host
+	}
+	if compression, ok := options["compression"].(string); ok {
+		config.Compression = compression
+	}
+	if config.ChunkSize, err = optparse.Int(options["chunk_size"], defaultGELFChunkSize); err != nil {
+		return nil, fmt.Errorf("invalid chunk_size: %w", err)
+	}
+	if config.Timeout, err = optparse.Duration(options["timeout"], defaultGELFTimeout); err != nil {
+		return nil, fmt.Errorf("invalid timeout: %w", err)
+	}
+
+	if config.Network == "tcp+tls" {
+		if config.TLSConfig, err = newTLSConfig(options); err != nil {
+			return nil, err
+		}
+	}
+
+	driver, err := NewGELFDriverWithConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	driver.filter = filter
+
+	return driver, nil
+}
+
+// NewGELFDriverWithConfig creates a GELF driver. It does not connect until
+// the first entry is logged.
+func NewGELFDriverWithConfig(config GELFConfig) (*GELFDriver, error) {
+	switch config.Network {
+	case "":
+		config.Network = "udp"
+	case "udp", "tcp", "tcp+tls":
+	default:
+		return nil, fmt.Errorf("unknown network: %s", config.Network)
+	}
+
+	if config.Address == "" {
+		return nil, fmt.Errorf("address is required")
+	}
+
+	switch config.Compression {
+	case "":
+		config.Compression = GELFGzip
+		if config.Network != "udp" {
+			config.Compression = GELFNone
+		}
+	case GELFGzip, GELFZlib, GELFNone:
+		if config.Network != "udp" && config.Compression != GELFNone {
+			return nil, fmt.Errorf("compression is only supported over udp")
+		}
+	default:
+		return nil, fmt.Errorf("unknown compression: %s", config.Compression)
+	}
+
+	if config.ChunkSize == 0 {
+		config.ChunkSize = defaultGELFChunkSize
+	}
+	if config.ChunkSize <= gelfChunkHeaderSize || config.ChunkSize > gelfMaxChunkSize {
+		return nil, fmt.Errorf("chunk_size must be between %d and %d, got %d", gelfChunkHeaderSize+1, gelfMaxChunkSize, config.ChunkSize)
+	}
+
+	if config.Host == "" {
+		config.Host, _ = os.Hostname()
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultGELFTimeout
+	}
+	if config.TLSConfig == nil && config.Network == "tcp+tls" {
+		config.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	return &GELFDriver{
+		config: config,
+	}, nil
+}
+
+// Log sends the entry as a GELF message
+func (d *GELFDriver) Log(entry *core.LogEntry) error {
+	if !d.filter.Allows(entry.Level) {
+		return nil
+	}
+
+	packets, err := d.packets(entry)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return fmt.Errorf("driver is closed")
+	}
+
+	// A broken connection, e.g. after the input restarted, is retried once
+	for attempt := 0; attempt < 2; attempt++ {
+		if d.conn == nil {
+			conn, err := d.dial()
+			if err != nil {
+				return fmt.Errorf("failed to connect to GELF input: %w", err)
+			}
+			d.conn = conn
+		}
+
+		d.conn.SetWriteDeadline(time.Now().Add(d.config.Timeout))
+		for _, packet := range packets {
+			if _, err = d.conn.Write(packet); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			return nil
+		}
+
+		d.conn.Close()
+		d.conn = nil
+	}
+
+	return fmt.Errorf("failed to write to GELF input: %w", err)
+}
+
+// dial connects to the GELF input
+func (d *GELFDriver) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: d.config.Timeout}
+
+	if d.config.Network == "tcp+tls" {
+		conn, err := tls.DialWithDialer(dialer, "tcp", d.config.Address, d.config.TLSConfig)
+		if err != nil {
+			return nil, err
+		}
+		return conn, nil
+	}
+
+	return dialer.Dial(d.config.Network, d.config.Address)
+}
+
+// packets encodes the entry into what is written to the connection: one
+// null-terminated message over TCP, one or more datagrams over UDP
+func (d *GELFDriver) packets(entry *core.LogEntry) ([][]byte, error) {
+	message, err := json.Marshal(d.message(entry))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode GELF message: %w", err)
+	}
+
+	if d.config.Network != "udp" {
+		return [][]byte{append(message, 0)}, nil
+	}
+
+	if message, err = d.compress(message); err != nil {
+		return nil, err
+	}
+
+	return gelfChunks(message, d.config.ChunkSize)
+}
+
+// message builds the GELF 1.1 fields of an entry
+func (d *GELFDriver) message(entry *core.LogEntry) map[string]interface{} {
+	message := map[string]interface{}{
+		"version":       "1.1",
+		"host":          d.config.Host,
+		"short_message": entry.Message,
+		"timestamp":     float64(entry.Timestamp.UnixNano()/int64(time.Millisecond)) / 1000,
+		"level":         entry.Level.SyslogSeverity(),
+		"_level_name":   entry.Level.String(),
+	}
+
+	// short_message is required to be non-empty
+	if entry.Message == "" {
+		message["short_message"] = "-"
+	}
+
+	if entry.TransactionID != "" {
+		message["_transaction_id"] = entry.TransactionID
+	}
+	if entry.Caller != nil {
+		message["_file"] = entry.Caller.File
+		message["_line"] = entry.Caller.Line
+		message["_function"] = entry.Caller.Function
+	}
+
+	// full_message carries the error chain and the stack traces
+	var full string
+	if entry.Error != nil {
+		message["_error"] = entry.Error.Message
+		if len(entry.Error.Chain) > 0 {
+			message["_error_type"] = entry.Error.Chain[0].Type
+		}
+		full += formatErrorChain(entry.Error, "")
+		if len(entry.Error.Stack) > 0 {
+			full += formatStack(entry.Error.Stack, "")
+		}
+	}
+	if len(entry.Stack) > 0 {
+		full += formatStack(entry.Stack, "")
+	}
+	if full != "" {
+		message["full_message"] = entry.Message + full
+	}
+
+	// Attributes come last and are renamed when they collide with a field
+	// set by the driver, whether or not this entry has it
+	for _, key := range sortedKeys(entry.Attrs) {
+		name := gelfFieldName(key)
+		if gelfReservedFields[name] {
+			name = "_attr" + name
+		}
+		message[name] = entry.Attrs[key]
+	}
+
+	return message
+}
+
+// compress applies the configured compression
+func (d *GELFDriver) compress(message []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var writer io.WriteCloser
+
+	switch d.config.Compression {
+	case GELFGzip:
+		writer = gzip.NewWriter(&buf)
+	case GELFZlib:
+		writer = zlib.NewWriter(&buf)
+	default:
+		return message, nil
+	}
+
+	writer.Write(message)
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress GELF message: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Name returns the driver name
+func (d *GELFDriver) Name() string {
+	return GELFDriverName
+}
+
+// Enabled reports whether the driver accepts entries at the level
+func (d *GELFDriver) Enabled(level core.Level) bool {
+	return d.filter.Allows(level)
+}
+
+// Close closes the connection
+func (d *GELFDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.closed = true
+	if d.conn == nil {
+		return nil
+	}
+
+	err := d.conn.Close()
+	d.conn = nil
+	return err
+}
+
+// gelfChunks splits a message into datagrams of at most chunkSize bytes.
+// Each chunk starts with the magic bytes, a message ID shared by all chunks,
+// its sequence number and the number of chunks.
+func gelfChunks(message []byte, chunkSize int) ([][]byte, error) {
+	if len(message) <= chunkSize {
+		return [][]byte{message}, nil
+	}
+
+	dataSize := chunkSize - gelfChunkHeaderSize
+	count := (len(message) + dataSize - 1) / dataSize
+	if count > gelfMaxChunks {
+		return nil, fmt.Errorf("GELF message of %d bytes needs %d chunks, at most %d are allowed", len(message), count, gelfMaxChunks)
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate message ID: %w", err)
+	}
+
+	chunks := make([][]byte, count)
+	for i := range chunks {
+		data := message[i*dataSize:]
+		if len(data) > dataSize {
+			data = data[:dataSize]
+		}
+
+		chunk := make([]byte, 0, gelfChunkHeaderSize+len(data))
+		chunk = append(chunk, gelfChunkMagic...)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunks[i] = append(chunk, data...)
+	}
+
+	return chunks, nil
+}
+
+// gelfFieldName turns an attribute key into an additional field name:
+// prefixed with an underscore, with characters other than letters, digits,
+// underscores, dashes and dots replaced. The reserved _id becomes __id.
+func gelfFieldName(key string) string {
+	name := []byte("_" + key)
+	for i, c := range name {
+		valid := c == '_' || c == '-' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+		if !valid {
+			name[i] = '_'
+		}
+	}
+	if string(name) == "_id" {
+		return "__id"
+	}
+
+	return string(name)
+}
@@ -0,0 +1,358 @@
+package drivers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// gelfTestEntry has every field the GELF driver maps
+var gelfTestEntry = &core.LogEntry{
+	Timestamp:     time.Date(2024, 5, 1, 12, 0, 0, 250000000, time.UTC),
+	Level:         core.Error,
+	Message:       "charge failed",
+	TransactionID: "tx-1",
+	Attrs:         core.Attributes{"user.id": "42", "id": "7", "card type": "visa", "file": "invoice.pdf", "error": "shadow"},
+	Caller:        &core.Frame{Function: "main.charge", File: "/app/main.go", Line: 12},
+	Error: &core.ErrorInfo{
+		Message: "card declined",
+		Chain:   []core.ErrorCause{{Type: "*errors.errorString", Message: "card declined"}},
+		Stack:   []core.Frame{{Function: "main.charge", File: "/app/main.go", Line: 10}},
+	},
+}
+
+// receiveGELFDatagrams reads datagrams until a complete message has arrived,
+// reassembling chunks and decompressing the result
+func receiveGELFDatagrams(t *testing.T, conn net.PacketConn) (map[string]interface{}, int) {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	var chunks [][]byte
+	var id []byte
+	var message []byte
+	for message == nil {
+		buf := make([]byte, 65536)
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("Failed to read datagram: %v", err)
+		}
+		datagram := buf[:n]
+
+		if !bytes.HasPrefix(datagram, gelfChunkMagic) {
+			message = datagram
+			break
+		}
+
+		seq, count := int(datagram[10]), int(datagram[11])
+		if chunks == nil {
+			chunks = make([][]byte, count)
+			id = datagram[2:10]
+		}
+		if !bytes.Equal(datagram[2:10], id) || len(chunks) != count || seq >= count {
+			t.Fatalf("Chunk %d/%d does not belong to message %x", seq, count, id)
+		}
+		chunks[seq] = datagram[12:]
+
+		complete := true
+		for _, chunk := range chunks {
+			complete = complete && len(chunk) > 0
+		}
+		if complete {
+			message = bytes.Join(chunks, nil)
+		}
+	}
+
+	var reader io.Reader = bytes.NewReader(message)
+	switch {
+	case bytes.HasPrefix(message, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			t.Fatalf("Invalid gzip message: %v", err)
+		}
+		reader = gz
+	case message[0] == 0x78:
+		z, err := zlib.NewReader(reader)
+		if err != nil {
+			t.Fatalf("Invalid zlib message: %v", err)
+		}
+		reader = z
+	}
+
+	var decoded map[string]interface{}
+	if err := json.NewDecoder(reader).Decode(&decoded); err != nil {
+		t.Fatalf("Invalid GELF message: %v", err)
+	}
+
+	return decoded, len(chunks)
+}
+
+func listenUDP(t *testing.T) net.PacketConn {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func TestGELFDriverMessage(t *testing.T) {
+	conn := listenUDP(t)
+
+	driver, err := Create(GELFDriverName, map[string]interface{}{
+		"address": conn.LocalAddr().String(),
+		"host":    "web-1",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+	defer driver.Close()
+
+	if err := driver.Log(gelfTestEntry); err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+
+	message, _ := receiveGELFDatagrams(t, conn)
+	want := map[string]interface{}{
+		"version":         "1.1",
+		"host":            "web-1",
+		"short_message":   "charge failed",
+		"timestamp":       1714564800.25,
+		"level":           float64(3),
+		"_level_name":     "ERROR",
+		"_user.id":        "42",
+		"__id":            "7",
+		"_card_type":      "visa",
+		"_attr_file":      "invoice.pdf",
+		"_attr_error":     "shadow",
+		"_transaction_id": "tx-1",
+		"_file":           "/app/main.go",
+		"_line":           float64(12),
+		"_function":       "main.charge",
+		"_error":          "card declined",
+		"_error_type":     "*errors.errorString",
+		"full_message": "charge failed\nerror: *errors.errorString: card declined" +
+			"\nstack:\n    main.charge (/app/main.go:10)",
+	}
+	for key, value := range want {
+		if message[key] != value {
+			t.Errorf("%s = %#v, want %#v", key, message[key], value)
+		}
+	}
+	if len(message) != len(want) {
+		t.Errorf("message has %d fields, want %d: %v", len(message), len(want), message)
+	}
+}
+
+func TestGELFDriverNoFullMessage(t *testing.T) {
+	conn := listenUDP(t)
+
+	driver, err := Create(GELFDriverName, map[string]interface{}{
+		"address":     conn.LocalAddr().String(),
+		"compression": "none",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+	defer driver.Close()
+
+	driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: "hello"})
+
+	message, _ := receiveGELFDatagrams(t, conn)
+	if _, ok := message["full_message"]; ok {
+		t.Errorf("full_message = %v, want none without error or stack", message["full_message"])
+	}
+	if message["level"] != float64(6) {
+		t.Errorf("level = %v, want 6", message["level"])
+	}
+}
+
+func TestGELFDriverChunking(t *testing.T) {
+	// Random data does not compress, so the message needs several chunks
+	random := make([]byte, 3000)
+	rand.Read(random)
+	large := hex.EncodeToString(random)
+
+	for _, compression := range []string{"gzip", "zlib", "none"} {
+		t.Run(compression, func(t *testing.T) {
+			conn := listenUDP(t)
+
+			driver, err := Create(GELFDriverName, map[string]interface{}{
+				"address":     conn.LocalAddr().String(),
+				"compression": compression,
+				"chunk_size":  512,
+			})
+			if err != nil {
+				t.Fatalf("Failed to create driver: %v", err)
+			}
+			defer driver.Close()
+
+			if err := driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: large}); err != nil {
+				t.Fatalf("Log failed: %v", err)
+			}
+
+			message, chunks := receiveGELFDatagrams(t, conn)
+			if message["short_message"] != large {
+				t.Error("reassembled message differs")
+			}
+			if chunks < 2 {
+				t.Errorf("got %d chunks, want several", chunks)
+			}
+		})
+	}
+}
+
+func TestGELFChunks(t *testing.T) {
+	message := bytes.Repeat([]byte("x"), 250)
+
+	chunks, err := gelfChunks(message, 112)
+	if err != nil {
+		t.Fatalf("gelfChunks failed: %v", err)
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if len(chunk) > 112 || !bytes.HasPrefix(chunk, gelfChunkMagic) {
+			t.Errorf("chunk %d has length %d and header %x", i, len(chunk), chunk[:2])
+		}
+		if !bytes.Equal(chunk[2:10], chunks[0][2:10]) {
+			t.Errorf("chunk %d has a different message ID", i)
+		}
+		if chunk[10] != byte(i) || chunk[11] != 3 {
+			t.Errorf("chunk %d has sequence %d/%d", i, chunk[10], chunk[11])
+		}
+	}
+
+	if single, _ := gelfChunks(message[:100], 112); len(single) != 1 || !bytes.Equal(single[0], message[:100]) {
+		t.Error("small message was chunked")
+	}
+
+	if _, err := gelfChunks(bytes.Repeat([]byte("x"), 129*100), 112); err == nil {
+		t.Error("gelfChunks with more than 128 chunks succeeded")
+	}
+}
+
+func TestGELFDriverTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	messages := acceptMessages(listener, readNullTerminated)
+
+	driver, err := Create(GELFDriverName, map[string]interface{}{
+		"network": "tcp",
+		"address": listener.Addr().String(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+	defer driver.Close()
+
+	for _, msg := range []string{"first", "second"} {
+		if err := driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: msg}); err != nil {
+			t.Fatalf("Log failed: %v", err)
+		}
+	}
+
+	for _, want := range []string{"first", "second"} {
+		var message map[string]interface{}
+		if err := json.Unmarshal([]byte(receive(t, messages)), &message); err != nil {
+			t.Fatalf("Invalid GELF message: %v", err)
+		}
+		if message["short_message"] != want {
+			t.Errorf("short_message = %v, want %s", message["short_message"], want)
+		}
+	}
+}
+
+func TestGELFDriverTLS(t *testing.T) {
+	cert, caFile := testCertificate(t)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	messages := acceptMessages(listener, readNullTerminated)
+
+	driver, err := Create(GELFDriverName, map[string]interface{}{
+		"network":     "tcp+tls",
+		"address":     listener.Addr().String(),
+		"tls_ca_file": caFile,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+	defer driver.Close()
+
+	if err := driver.Log(gelfTestEntry); err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+
+	if msg := receive(t, messages); !strings.Contains(msg, `"short_message":"charge failed"`) {
+		t.Errorf("Unexpected message: %q", msg)
+	}
+}
+
+// readNullTerminated reads a null-byte-delimited GELF message
+func readNullTerminated(r *bufio.Reader) (string, error) {
+	msg, err := r.ReadString(0)
+	return strings.TrimSuffix(msg, "\x00"), err
+}
+
+func TestGELFFieldName(t *testing.T) {
+	tests := map[string]string{
+		"user_id":   "_user_id",
+		"http.path": "_http.path",
+		"trace-id":  "_trace-id",
+		"a b/c":     "_a_b_c",
+		"id":        "__id",
+	}
+
+	for key, want := range tests {
+		if got := gelfFieldName(key); got != want {
+			t.Errorf("gelfFieldName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestNewGELFDriverInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+	}{
+		{name: "missing address", options: map[string]interface{}{}},
+		{name: "network", options: map[string]interface{}{"address": "localhost:12201", "network": "sctp"}},
+		{name: "compression", options: map[string]interface{}{"address": "localhost:12201", "compression": "lz4"}},
+		{name: "tcp compression", options: map[string]interface{}{"address": "localhost:12201", "network": "tcp", "compression": "gzip"}},
+		{name: "chunk_size", options: map[string]interface{}{"address": "localhost:12201", "chunk_size": 12}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if driver, err := Create(GELFDriverName, test.options); err == nil {
+				driver.Close()
+				t.Error("Create succeeded, want an error")
+			}
+		})
+	}
+}
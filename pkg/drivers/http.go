@@ -0,0 +1,165 @@
+package drivers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// HTTPDriverName is the name to use in configuration
+const HTTPDriverName = "http"
+
+func init() {
+	Register(HTTPDriverName, NewHTTPDriver)
+}
+
+// HTTPFormat is the body format of an HTTPDriver
+type HTTPFormat string
+
+const (
+	// HTTPFormatJSONLines sends one JSON object per line
+	HTTPFormatJSONLines HTTPFormat = "jsonl"
+
+	// HTTPFormatJSONArray sends a JSON array of objects
+	HTTPFormatJSONArray HTTPFormat = "json_array"
+)
+
+// HTTPDriverConfig configures an HTTPDriver
+type HTTPDriverConfig struct {
+	HTTP   HTTPConfig
+	Batch  BatchConfig
+	Format HTTPFormat
+}
+
+// HTTPDriver posts batches of entries to a URL from a background goroutine.
+// Entries are encoded like the json_file driver. Log never blocks on the
+// network: it fails with ErrQueueFull when the queue is full and reports
+// failed batches on a later call.
+type HTTPDriver struct {
+	format  HTTPFormat
+	filter  *core.LevelFilter
+	sender  *httpSender
+	batcher *batcher
+}
+
+// NewHTTPDriver creates a new HTTP driver from a map of options
+func NewHTTPDriver(options map[string]interface{}) (core.Driver, error) {
+	filter, err := newLevelFilter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	config := HTTPDriverConfig{}
+	if config.HTTP, err = newHTTPConfig(options); err != nil {
+		return nil, err
+	}
+	if config.Batch, err = newBatchConfig(options); err != nil {
+		return nil, err
+	}
+	if format, ok := options["format"].(string); ok {
+		config.Format = HTTPFormat(format)
+	}
+
+	driver, err := NewHTTPDriverWithConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	driver.filter = filter
+	return driver, nil
+}
+
+// NewHTTPDriverWithConfig creates an HTTP driver and starts its background
+// goroutine
+func NewHTTPDriverWithConfig(config HTTPDriverConfig) (*HTTPDriver, error) {
+	switch config.Format {
+	case "":
+		config.Format = HTTPFormatJSONLines
+	case HTTPFormatJSONLines, HTTPFormatJSONArray:
+	default:
+		return nil, fmt.Errorf("unknown format %q", config.Format)
+	}
+
+	sender, err := newHTTPSender(config.HTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	driver := &HTTPDriver{
+		format: config.Format,
+		sender: sender,
+	}
+	driver.batcher = newBatcher(config.Batch, driver.send)
+
+	return driver, nil
+}
+
+// Log queues the entry for the next batch
+func (d *HTTPDriver) Log(entry *core.LogEntry) error {
+	if !d.filter.Allows(entry.Level) {
+		return nil
+	}
+
+	return d.batcher.add(entry)
+}
+
+// Flush sends the queued entries and waits for the request to finish
+func (d *HTTPDriver) Flush() error {
+	return d.batcher.flush()
+}
+
+// Close sends the queued entries and stops the background goroutine
+func (d *HTTPDriver) Close() error {
+	return d.batcher.close()
+}
+
+// Name returns the driver name
+func (d *HTTPDriver) Name() string {
+	return HTTPDriverName
+}
+
+// Enabled reports whether the driver accepts entries at the level
+func (d *HTTPDriver) Enabled(level core.Level) bool {
+	return d.filter.Allows(level)
+}
+
+// send encodes and posts one batch
+func (d *HTTPDriver) send(batch []*core.LogEntry) error {
+	body, contentType, err := d.encode(batch)
+	if err != nil {
+		return err
+	}
+
+	if _, err := d.sender.send(body, contentType); err != nil {
+		return fmt.Errorf("failed to send %d entries: %w", len(batch), err)
+	}
+
+	return nil
+}
+
+// encode serializes a batch in the configured format
+func (d *HTTPDriver) encode(batch []*core.LogEntry) ([]byte, string, error) {
+	if d.format == HTTPFormatJSONArray {
+		entries := make([]*JSONLogEntry, len(batch))
+		for i, entry := range batch {
+			entries[i] = NewJSONLogEntry(entry)
+		}
+
+		body, err := json.Marshal(entries)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode entries: %w", err)
+		}
+		return body, "application/json", nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, entry := range batch {
+		if err := encoder.Encode(NewJSONLogEntry(entry)); err != nil {
+			return nil, "", fmt.Errorf("failed to encode entry: %w", err)
+		}
+	}
+
+	return buf.Bytes(), "application/x-ndjson", nil
+}
@@ -0,0 +1,302 @@
+package drivers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/internal/optparse"
+)
+
+// HTTP defaults
+const (
+	defaultHTTPTimeout    = 10 * time.Second
+	defaultMaxRetries     = 3
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 30 * time.Second
+)
+
+// maxErrorBody bounds how much of an error response is quoted in errors
+const maxErrorBody = 512
+
+// RetryConfig configures retries with exponential backoff and full jitter
+type RetryConfig struct {
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+
+	// InitialBackoff is the upper bound of the first delay
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay
+	MaxBackoff time.Duration
+}
+
+// withDefaults replaces zero values with the defaults
+func (c RetryConfig) withDefaults() RetryConfig {
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = defaultInitialBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = defaultMaxBackoff
+	}
+
+	return c
+}
+
+// backoff returns a random delay before retry number attempt (starting at 0),
+// up to InitialBackoff * 2^attempt and at most MaxBackoff
+func (c RetryConfig) backoff(attempt int) time.Duration {
+	limit := c.InitialBackoff
+	for i := 0; i < attempt && limit < c.MaxBackoff; i++ {
+		limit *= 2
+	}
+	if limit > c.MaxBackoff {
+		limit = c.MaxBackoff
+	}
+
+	return time.Duration(rand.Int63n(int64(limit) + 1))
+}
+
+// HTTPConfig configures the requests of the HTTP-based drivers
+type HTTPConfig struct {
+	// URL receives the requests
+	URL string
+
+	// Method defaults to POST
+	Method string
+
+	// Headers are added to every request
+	Headers map[string]string
+
+	// Username and Password enable basic authentication
+	Username string
+	Password string
+
+	// BearerToken enables bearer token authentication
+	BearerToken string
+
+	// Gzip compresses request bodies
+	Gzip bool
+
+	// Timeout bounds each attempt
+	Timeout time.Duration
+
+	// Retry configures retries of failed attempts
+	Retry RetryConfig
+
+	// Client is used instead of a client built from Timeout, e.g. for custom TLS
+	Client *http.Client
+}
+
+// HTTPStatusError is returned when the server rejects a request
+type HTTPStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+// Error returns the status code and the beginning of the response body
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("server responded with %d: %s", e.StatusCode, e.Body)
+}
+
+// newHTTPConfig reads the url, method, headers, authentication, gzip,
+// timeout, retry and tls_* options shared by the HTTP-based drivers
+func newHTTPConfig(options map[string]interface{}) (HTTPConfig, error) {
+	config := HTTPConfig{}
+	var err error
+
+	url, ok := options["url"].(string)
+	if !ok || url == "" {
+		return config, fmt.Errorf("url is required")
+	}
+	config.URL = url
+
+	if method, ok := options["method"].(string); ok {
+		config.Method = method
+	}
+	if config.Headers, err = optparse.StringMap(options["headers"]); err != nil {
+		return config, fmt.Errorf("invalid headers: %w", err)
+	}
+	if username, ok := options["username"].(string); ok {
+		config.Username = username
+	}
+	if password, ok := options["password"].(string); ok {
+		config.Password = password
+	}
+	if token, ok := options["bearer_token"].(string); ok {
+		config.BearerToken = token
+	}
+	if config.Gzip, err = optparse.Bool(options["gzip"], false); err != nil {
+		return config, fmt.Errorf("invalid gzip: %w", err)
+	}
+	if config.Timeout, err = optparse.Duration(options["timeout"], defaultHTTPTimeout); err != nil {
+		return config, fmt.Errorf("invalid timeout: %w", err)
+	}
+	if config.Retry.MaxRetries, err = optparse.Int(options["max_retries"], defaultMaxRetries); err != nil {
+		return config, fmt.Errorf("invalid max_retries: %w", err)
+	}
+	if config.Retry.InitialBackoff, err = optparse.Duration(options["initial_backoff"], defaultInitialBackoff); err != nil {
+		return config, fmt.Errorf("invalid initial_backoff: %w", err)
+	}
+	if config.Retry.MaxBackoff, err = optparse.Duration(options["max_backoff"], defaultMaxBackoff); err != nil {
+		return config, fmt.Errorf("invalid max_backoff: %w", err)
+	}
+
+	tlsConfig, err := newTLSConfig(options)
+	if err != nil {
+		return config, err
+	}
+	config.Client = &http.Client{
+		Timeout:   config.Timeout,
+		Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig},
+	}
+
+	return config, nil
+}
+
+// httpSender posts request bodies, retrying network errors, 5xx and 429
+// responses with exponential backoff and jitter
+type httpSender struct {
+	config HTTPConfig
+	sleep  func(time.Duration)
+}
+
+// newHTTPSender creates a sender from a configuration with defaults applied
+func newHTTPSender(config HTTPConfig) (*httpSender, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	if config.Method == "" {
+		config.Method = http.MethodPost
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultHTTPTimeout
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: config.Timeout}
+	}
+	config.Retry = config.Retry.withDefaults()
+
+	return &httpSender{
+		config: config,
+		sleep:  time.Sleep,
+	}, nil
+}
+
+// send posts body with the content type and returns the body of the
+// successful response
+func (s *httpSender) send(body []byte, contentType string) ([]byte, error) {
+	encoding := ""
+	if s.config.Gzip {
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		writer.Write(body)
+		if err := writer.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress body: %w", err)
+		}
+		body = buf.Bytes()
+		encoding = "gzip"
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		var response []byte
+		var retryAfter time.Duration
+		response, retryAfter, err = s.attempt(body, contentType, encoding)
+		if err == nil {
+			return response, nil
+		}
+
+		if !retryable(err) || attempt >= s.config.Retry.MaxRetries {
+			return nil, err
+		}
+
+		delay := s.config.Retry.backoff(attempt)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		s.sleep(delay)
+	}
+}
+
+// attempt makes one request. It returns the delay requested by a
+// Retry-After header along with a status error.
+func (s *httpSender) attempt(body []byte, contentType, encoding string) ([]byte, time.Duration, error) {
+	request, err := http.NewRequest(s.config.Method, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, &permanentError{err}
+	}
+
+	request.Header.Set("Content-Type", contentType)
+	if encoding != "" {
+		request.Header.Set("Content-Encoding", encoding)
+	}
+	for key, value := range s.config.Headers {
+		request.Header.Set(key, value)
+	}
+	if s.config.Username != "" || s.config.Password != "" {
+		request.SetBasicAuth(s.config.Username, s.config.Password)
+	}
+	if s.config.BearerToken != "" {
+		request.Header.Set("Authorization", "Bearer "+s.config.BearerToken)
+	}
+
+	response, err := s.config.Client.Do(request)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return responseBody, 0, nil
+	}
+
+	if len(responseBody) > maxErrorBody {
+		responseBody = responseBody[:maxErrorBody]
+	}
+	statusErr := &HTTPStatusError{StatusCode: response.StatusCode, Body: string(responseBody)}
+
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+		if retryAfter > s.config.Retry.MaxBackoff {
+			retryAfter = s.config.Retry.MaxBackoff
+		}
+	}
+
+	return nil, retryAfter, statusErr
+}
+
+// permanentError marks errors that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// retryable reports whether a failed attempt should be retried: network
+// errors, 429 Too Many Requests and 5xx responses
+func retryable(err error) bool {
+	switch e := err.(type) {
+	case *permanentError:
+		return false
+	case *HTTPStatusError:
+		return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+	default:
+		return true
+	}
+}
@@ -0,0 +1,410 @@
+package drivers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// httpRequest is a request received by an httpServer
+type httpRequest struct {
+	path   string
+	header http.Header
+	body   []byte
+}
+
+// httpServer records requests and answers them with the given status codes in
+// turn, then with 200
+type httpServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []httpRequest
+	statuses []int
+}
+
+func newHTTPServer(t *testing.T, statuses ...int) *httpServer {
+	t.Helper()
+
+	s := &httpServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			reader, err := gzip.NewReader(strings.NewReader(string(body)))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body, _ = io.ReadAll(reader)
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, httpRequest{path: r.URL.Path, header: r.Header.Clone(), body: body})
+		status := http.StatusOK
+		if len(s.statuses) > 0 {
+			status = s.statuses[0]
+			s.statuses = s.statuses[1:]
+		}
+		s.mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *httpServer) received() []httpRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]httpRequest(nil), s.requests...)
+}
+
+// decodeJSONLines decodes a JSON Lines body
+func decodeJSONLines(t *testing.T, body []byte) []JSONLogEntry {
+	t.Helper()
+
+	var entries []JSONLogEntry
+	scanner := bufio.NewScanner(strings.NewReader(string(body)))
+	for scanner.Scan() {
+		var entry JSONLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func newTestHTTPDriver(t *testing.T, options map[string]interface{}) *HTTPDriver {
+	t.Helper()
+
+	if _, ok := options["initial_backoff"]; !ok {
+		options["initial_backoff"] = "1ms"
+	}
+	driver, err := NewHTTPDriver(options)
+	if err != nil {
+		t.Fatalf("NewHTTPDriver() error = %v", err)
+	}
+	t.Cleanup(func() { driver.Close() })
+
+	return driver.(*HTTPDriver)
+}
+
+func TestHTTPDriverJSONLines(t *testing.T) {
+	server := newHTTPServer(t)
+	driver := newTestHTTPDriver(t, map[string]interface{}{
+		"url":        server.URL,
+		"batch_size": 2,
+		"linger":     "1h",
+	})
+
+	for _, message := range []string{"one", "two", "three"} {
+		if err := driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: message}); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+	}
+	if err := driver.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	requests := server.received()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	if got := requests[0].header.Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	first := decodeJSONLines(t, requests[0].body)
+	second := decodeJSONLines(t, requests[1].body)
+	if len(first) != 2 || first[0].Message != "one" || first[1].Message != "two" {
+		t.Errorf("first batch = %+v", first)
+	}
+	if len(second) != 1 || second[0].Message != "three" || second[0].Level != "INFO" {
+		t.Errorf("second batch = %+v", second)
+	}
+}
+
+func TestHTTPDriverJSONArrayGzip(t *testing.T) {
+	server := newHTTPServer(t)
+	driver := newTestHTTPDriver(t, map[string]interface{}{
+		"url":    server.URL,
+		"format": "json_array",
+		"gzip":   true,
+		"linger": "1h",
+	})
+
+	driver.Log(&core.LogEntry{Level: core.Warning, Message: "disk", Attrs: core.Attributes{"free": "5%"}})
+	if err := driver.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	requests := server.received()
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	if got := requests[0].header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := requests[0].header.Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("Content-Encoding = %q", got)
+	}
+
+	var entries []JSONLogEntry
+	if err := json.Unmarshal(requests[0].body, &entries); err != nil {
+		t.Fatalf("Invalid JSON array %q: %v", requests[0].body, err)
+	}
+	if len(entries) != 1 || entries[0].Message != "disk" || entries[0].Attributes["free"] != "5%" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestHTTPDriverLinger(t *testing.T) {
+	server := newHTTPServer(t)
+	driver := newTestHTTPDriver(t, map[string]interface{}{
+		"url":    server.URL,
+		"linger": "20ms",
+	})
+
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "waiting"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(server.received()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("batch was not sent after the linger time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHTTPDriverAuthAndHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+		check   func(t *testing.T, header http.Header)
+	}{
+		{
+			name:    "basic",
+			options: map[string]interface{}{"username": "shipper", "password": "secret"},
+			check: func(t *testing.T, header http.Header) {
+				request := &http.Request{Header: header}
+				username, password, ok := request.BasicAuth()
+				if !ok || username != "shipper" || password != "secret" {
+					t.Errorf("BasicAuth() = %q, %q, %v", username, password, ok)
+				}
+			},
+		},
+		{
+			name:    "bearer",
+			options: map[string]interface{}{"bearer_token": "abc"},
+			check: func(t *testing.T, header http.Header) {
+				if got := header.Get("Authorization"); got != "Bearer abc" {
+					t.Errorf("Authorization = %q", got)
+				}
+			},
+		},
+		{
+			name:    "headers",
+			options: map[string]interface{}{"headers": map[string]interface{}{"X-Tenant": "acme"}},
+			check: func(t *testing.T, header http.Header) {
+				if got := header.Get("X-Tenant"); got != "acme" {
+					t.Errorf("X-Tenant = %q", got)
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newHTTPServer(t)
+			test.options["url"] = server.URL
+			driver := newTestHTTPDriver(t, test.options)
+
+			driver.Log(&core.LogEntry{Level: core.Info, Message: "hello"})
+			if err := driver.Flush(); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+
+			requests := server.received()
+			if len(requests) != 1 {
+				t.Fatalf("got %d requests, want 1", len(requests))
+			}
+			test.check(t, requests[0].header)
+		})
+	}
+}
+
+func TestHTTPDriverRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		requests int
+		wantErr  bool
+	}{
+		{name: "server error", statuses: []int{500, 503}, requests: 3},
+		{name: "too many requests", statuses: []int{429}, requests: 2},
+		{name: "client error", statuses: []int{400}, requests: 1, wantErr: true},
+		{name: "exhausted", statuses: []int{500, 500, 500, 500}, requests: 4, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newHTTPServer(t, test.statuses...)
+			driver := newTestHTTPDriver(t, map[string]interface{}{
+				"url":         server.URL,
+				"max_retries": 3,
+			})
+
+			driver.Log(&core.LogEntry{Level: core.Info, Message: "retry"})
+			err := driver.Flush()
+			if (err != nil) != test.wantErr {
+				t.Errorf("Flush() error = %v, wantErr %v", err, test.wantErr)
+			}
+
+			var statusErr *HTTPStatusError
+			if test.wantErr && !errors.As(err, &statusErr) {
+				t.Errorf("Flush() error = %v, want an HTTPStatusError", err)
+			}
+			if got := len(server.received()); got != test.requests {
+				t.Errorf("got %d requests, want %d", got, test.requests)
+			}
+		})
+	}
+}
+
+func TestHTTPSenderBackoff(t *testing.T) {
+	server := newHTTPServer(t, 500, 500, 500)
+
+	sender, err := newHTTPSender(HTTPConfig{
+		URL:   server.URL,
+		Retry: RetryConfig{MaxRetries: 3, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 250 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("newHTTPSender() error = %v", err)
+	}
+
+	var delays []time.Duration
+	sender.sleep = func(d time.Duration) { delays = append(delays, d) }
+
+	if _, err := sender.send([]byte("{}"), "application/json"); err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+
+	limits := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 250 * time.Millisecond}
+	if len(delays) != len(limits) {
+		t.Fatalf("got %d delays, want %d", len(delays), len(limits))
+	}
+	for i, delay := range delays {
+		if delay < 0 || delay > limits[i] {
+			t.Errorf("delay %d = %v, want at most %v", i, delay, limits[i])
+		}
+	}
+}
+
+func TestHTTPSenderRetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	sender, _ := newHTTPSender(HTTPConfig{
+		URL:   server.URL,
+		Retry: RetryConfig{MaxRetries: 1, InitialBackoff: time.Millisecond},
+	})
+
+	var delay time.Duration
+	sender.sleep = func(d time.Duration) { delay = d }
+
+	if _, err := sender.send(nil, "application/json"); err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+	if delay != 2*time.Second {
+		t.Errorf("delay = %v, want the Retry-After value of 2s", delay)
+	}
+}
+
+func TestHTTPDriverQueueFull(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	driver := newTestHTTPDriver(t, map[string]interface{}{
+		"url":        server.URL,
+		"batch_size": 1,
+		"queue_size": 1,
+	})
+
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = driver.Log(&core.LogEntry{Level: core.Info, Message: "flood"})
+	}
+	if !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Log() error = %v, want ErrQueueFull", err)
+	}
+}
+
+func TestHTTPDriverLevelFilter(t *testing.T) {
+	server := newHTTPServer(t)
+	driver := newTestHTTPDriver(t, map[string]interface{}{
+		"url":       server.URL,
+		"min_level": "warning",
+	})
+
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "skipped"})
+	driver.Log(&core.LogEntry{Level: core.Error, Message: "kept"})
+	driver.Flush()
+
+	requests := server.received()
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	entries := decodeJSONLines(t, requests[0].body)
+	if len(entries) != 1 || entries[0].Message != "kept" {
+		t.Errorf("entries = %+v", entries)
+	}
+	if driver.Enabled(core.Info) {
+		t.Error("Enabled(Info) = true, want false")
+	}
+}
+
+func TestNewHTTPDriverInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+	}{
+		{name: "missing url", options: map[string]interface{}{}},
+		{name: "format", options: map[string]interface{}{"url": "http://localhost", "format": "xml"}},
+		{name: "gzip", options: map[string]interface{}{"url": "http://localhost", "gzip": "maybe"}},
+		{name: "max_retries", options: map[string]interface{}{"url": "http://localhost", "max_retries": "often"}},
+		{name: "headers", options: map[string]interface{}{"url": "http://localhost", "headers": "X-A: b"}},
+		{name: "linger", options: map[string]interface{}{"url": "http://localhost", "linger": "soon"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if driver, err := NewHTTPDriver(test.options); err == nil {
+				driver.Close()
+				t.Error("NewHTTPDriver() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package drivers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// JournaldDriverName is the name to use in configuration
+const JournaldDriverName = "journald"
+
+func init() {
+	Register(JournaldDriverName, NewJournaldDriver)
+}
+
+// defaultJournaldSocket is the native protocol socket of systemd-journald
+const defaultJournaldSocket = "/run/systemd/journal/socket"
+
+// journaldFieldMaxLen is the longest field name journald accepts
+const journaldFieldMaxLen = 64
+
+// JournaldConfig configures a JournaldDriver
+type JournaldConfig struct {
+	// Socket is the path of the journald socket
+	Socket string
+
+	// Identifier is the SYSLOG_IDENTIFIER field; defaults to the executable name
+	Identifier string
+}
+
+// JournaldDriver writes entries to systemd-journald using its native
+// protocol. The message becomes MESSAGE, the level PRIORITY, the transaction
+// ID TRANSACTION_ID and each attribute an uppercase field, e.g. user_id
+// becomes USER_ID. Entries too large for a datagram are passed as a file
+// descriptor, as journald expects.
+type JournaldDriver struct {
+	config JournaldConfig
+	filter *core.LevelFilter
+	mu     sync.Mutex
+	conn   *net.UnixConn
+	closed bool
+}
+
+// NewJournaldDriver creates a new journald driver from a map of options
+func NewJournaldDriver(options map[string]interface{}) (core.Driver, error) {
+	filter, err := newLevelFilter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	config := JournaldConfig{}
+	if socket, ok := options["socket"].(string); ok {
+		config.Socket = socket
+	}
+	if identifier, ok := options["identifier"].(string); ok {
+		config.Identifier = identifier
+	}
+
+	driver := NewJournaldDriverWithConfig(config)
+	driver.filter = filter
+	return driver, nil
+}
+
+// NewJournaldDriverWithConfig creates a journald driver. It does not connect
+// until the first entry is logged.
+func NewJournaldDriverWithConfig(config JournaldConfig) *JournaldDriver {
+	if config.Socket == "" {
+		config.Socket = defaultJournaldSocket
+	}
+	if config.Identifier == "" {
+		config.Identifier = filepath.Base(os.Args[0])
+	}
+
+	return &JournaldDriver{
+		config: config,
+	}
+}
+
+// Log sends the entry to journald
+func (d *JournaldDriver) Log(entry *core.LogEntry) error {
+	if !d.filter.Allows(entry.Level) {
+		return nil
+	}
+
+	payload := d.encode(entry)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return fmt.Errorf("driver is closed")
+	}
+
+	// The socket is reconnected once if journald was restarted
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if d.conn == nil {
+			conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: d.config.Socket, Net: "unixgram"})
+			if err != nil {
+				return fmt.Errorf("failed to connect to journald: %w", err)
+			}
+			d.conn = conn
+		}
+
+		_, err = d.conn.Write(payload)
+		if err != nil && journaldTooLarge(err) {
+			err = journaldSendFile(d.conn, payload)
+		}
+		if err == nil {
+			return nil
+		}
+
+		d.conn.Close()
+		d.conn = nil
+	}
+
+	return fmt.Errorf("failed to write to journald: %w", err)
+}
+
+// encode serializes the entry in the journald native protocol
+func (d *JournaldDriver) encode(entry *core.LogEntry) []byte {
+	var buf bytes.Buffer
+
+	message := entry.Message
+	if entry.Error != nil && entry.Error.Message != entry.Message {
+		message += ": " + entry.Error.Message
+	}
+
+	journaldField(&buf, "MESSAGE", message)
+	journaldField(&buf, "PRIORITY", strconv.Itoa(entry.Level.SyslogSeverity()))
+	journaldField(&buf, "SYSLOG_IDENTIFIER", d.config.Identifier)
+	journaldField(&buf, "LEVEL", entry.Level.String())
+
+	if entry.TransactionID != "" {
+		journaldField(&buf, "TRANSACTION_ID", entry.TransactionID)
+	}
+
+	if entry.Caller != nil {
+		journaldField(&buf, "CODE_FILE", entry.Caller.File)
+		journaldField(&buf, "CODE_LINE", strconv.Itoa(entry.Caller.Line))
+		journaldField(&buf, "CODE_FUNC", entry.Caller.Function)
+	}
+
+	if entry.Error != nil {
+		journaldField(&buf, "ERROR", entry.Error.Message)
+		if len(entry.Error.Chain) > 0 {
+			journaldField(&buf, "ERROR_TYPE", entry.Error.Chain[0].Type)
+		}
+	}
+
+	if len(entry.Stack) > 0 {
+		journaldField(&buf, "STACKTRACE", strings.Join(formatFrames(entry.Stack), "\n"))
+	}
+
+	for _, key := range sortedKeys(entry.Attrs) {
+		journaldField(&buf, journaldFieldName(key), entry.Attrs[key])
+	}
+
+	return buf.Bytes()
+}
+
+// Name returns the driver name used in configuration
+func (d *JournaldDriver) Name() string {
+	return JournaldDriverName
+}
+
+// Enabled reports whether the driver accepts entries at the level
+func (d *JournaldDriver) Enabled(level core.Level) bool {
+	return d.filter.Allows(level)
+}
+
+// Close closes the connection to journald
+func (d *JournaldDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.closed = true
+	if d.conn == nil {
+		return nil
+	}
+
+	err := d.conn.Close()
+	d.conn = nil
+	return err
+}
+
+// journaldField appends a field to buf. Values containing a newline are
+// written in the binary form: the name, a newline, the length as a 64-bit
+// little endian integer and the value.
+func journaldField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+
+	if !strings.Contains(value, "\n") {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+
+	buf.WriteByte('\n')
+	binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journaldReservedFields are the fields written by the driver and the other
+// fields with a meaning to journald. Attributes mapping to them are prefixed
+// with ATTR_ so that they cannot override or duplicate them.
+var journaldReservedFields = map[string]bool{
+	"MESSAGE":            true,
+	"MESSAGE_ID":         true,
+	"PRIORITY":           true,
+	"LEVEL":              true,
+	"TRANSACTION_ID":     true,
+	"CODE_FILE":          true,
+	"CODE_LINE":          true,
+	"CODE_FUNC":          true,
+	"ERROR":              true,
+	"ERROR_TYPE":         true,
+	"STACKTRACE":         true,
+	"ERRNO":              true,
+	"DOCUMENTATION":      true,
+	"TID":                true,
+	"INVOCATION_ID":      true,
+	"USER_INVOCATION_ID": true,
+	"UNIT":               true,
+	"USER_UNIT":          true,
+	"SYSLOG_FACILITY":    true,
+	"SYSLOG_IDENTIFIER":  true,
+	"SYSLOG_PID":         true,
+	"SYSLOG_TIMESTAMP":   true,
+	"SYSLOG_RAW":         true,
+}
+
+// journaldFieldName turns an attribute key into a valid field name:
+// uppercase letters, digits and underscores, not starting with an underscore
+// or a digit, at most 64 characters. Reserved names get the ATTR_ prefix.
+func journaldFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		default:
+			return '_'
+		}
+	}, key)
+
+	// Fields starting with an underscore are reserved for journald itself
+	name = strings.TrimLeft(name, "_")
+	if name == "" || name[0] >= '0' && name[0] <= '9' || journaldReservedFields[name] {
+		name = "ATTR_" + name
+	}
+
+	if len(name) > journaldFieldMaxLen {
+		name = name[:journaldFieldMaxLen]
+	}
+
+	return name
+}
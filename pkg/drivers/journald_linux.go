@@ -0,0 +1,62 @@
+//go:build linux
+
+package drivers
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+// journaldTooLarge reports whether a datagram was rejected for its size
+func journaldTooLarge(err error) bool {
+	return errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS)
+}
+
+// journaldSendFile writes the payload to an unlinked temporary file and
+// passes its descriptor to journald, which reads the entry from it. The file
+// is created in /dev/shm when possible so that it stays in memory.
+func journaldSendFile(conn *net.UnixConn, payload []byte) error {
+	dir := "/dev/shm"
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		dir = os.TempDir()
+	}
+
+	file, err := os.CreateTemp(dir, "journald-")
+	if err != nil {
+		return fmt.Errorf("failed to create payload file: %w", err)
+	}
+	defer file.Close()
+
+	// Unlinking right away leaves nothing behind if the process dies
+	if err := os.Remove(file.Name()); err != nil {
+		return fmt.Errorf("failed to unlink payload file: %w", err)
+	}
+
+	if _, err := file.Write(payload); err != nil {
+		return fmt.Errorf("failed to write payload file: %w", err)
+	}
+
+	// WriteMsgUnix refuses connected datagram sockets, so sendmsg is called directly
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	rights := syscall.UnixRights(int(file.Fd()))
+	var sendErr error
+	err = raw.Write(func(fd uintptr) bool {
+		sendErr = syscall.Sendmsg(int(fd), nil, rights, nil, 0)
+		return sendErr != syscall.EAGAIN
+	})
+	if err == nil {
+		err = sendErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to pass payload file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+//go:build linux
+
+package drivers
+
+import (
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+func TestJournaldDriverLargePayload(t *testing.T) {
+	listener, path := listenJournald(t)
+
+	driver := NewJournaldDriverWithConfig(JournaldConfig{Socket: path})
+	defer driver.Close()
+
+	// Far beyond the default datagram limit of unix sockets
+	message := strings.Repeat("x", 4<<20)
+	if err := driver.Log(&core.LogEntry{Level: core.Info, Message: message}); err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+
+	buf := make([]byte, 16)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	listener.SetReadDeadline(time.Now().Add(time.Second))
+	n, oobn, _, _, err := listener.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("Expected an empty datagram, got %d bytes", n)
+	}
+
+	messages, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(messages) != 1 {
+		t.Fatalf("Expected one control message, got %v (%v)", messages, err)
+	}
+	fds, err := syscall.ParseUnixRights(&messages[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("Expected one file descriptor, got %v (%v)", fds, err)
+	}
+
+	file := os.NewFile(uintptr(fds[0]), "payload")
+	defer file.Close()
+	file.Seek(0, io.SeekStart)
+	payload, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read payload: %v", err)
+	}
+
+	if fields := parseJournaldPayload(t, payload); fields["MESSAGE"] != message {
+		t.Errorf("Expected the message in the payload, got %d bytes", len(fields["MESSAGE"]))
+	}
+}
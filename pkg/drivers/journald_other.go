@@ -0,0 +1,19 @@
+//go:build !linux
+
+package drivers
+
+import (
+	"fmt"
+	"net"
+)
+
+// journaldTooLarge reports whether a datagram was rejected for its size.
+// journald only runs on Linux, so large payloads are never passed elsewhere.
+func journaldTooLarge(err error) bool {
+	return false
+}
+
+// journaldSendFile is only supported on Linux
+func journaldSendFile(conn *net.UnixConn, payload []byte) error {
+	return fmt.Errorf("passing payloads to journald is only supported on Linux")
+}
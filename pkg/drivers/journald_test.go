@@ -0,0 +1,183 @@
+package drivers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// parseJournaldPayload decodes the journald native protocol
+func parseJournaldPayload(t *testing.T, payload []byte) map[string]string {
+	t.Helper()
+
+	fields := make(map[string]string)
+	for len(payload) > 0 {
+		newline := bytes.IndexByte(payload, '\n')
+		if newline < 0 {
+			t.Fatalf("Missing newline in %q", payload)
+		}
+		line := string(payload[:newline])
+		payload = payload[newline+1:]
+
+		if name, value, ok := strings.Cut(line, "="); ok {
+			fields[name] = value
+			continue
+		}
+
+		length := binary.LittleEndian.Uint64(payload[:8])
+		fields[line] = string(payload[8 : 8+length])
+		if payload[8+length] != '\n' {
+			t.Fatalf("Missing newline after binary field %s", line)
+		}
+		payload = payload[9+length:]
+	}
+
+	return fields
+}
+
+func TestJournaldDriverEncode(t *testing.T) {
+	driver := NewJournaldDriverWithConfig(JournaldConfig{Identifier: "billing"})
+
+	entry := &core.LogEntry{
+		Timestamp:     time.Now(),
+		Level:         core.Error,
+		Message:       "charge failed",
+		TransactionID: "tx-1",
+		Attrs: core.Attributes{
+			"user_id":   "42",
+			"http.path": "/pay",
+			"_private":  "x",
+			"2fa":       "on",
+			"details":   "line one\nline two",
+			"priority":  "low",
+			"message":   "shadow",
+		},
+		Error:  core.NewErrorInfo(errors.New("card declined")),
+		Caller: &core.Frame{Function: "main.charge", File: "billing/charge.go", Line: 17},
+		Stack:  []core.Frame{{Function: "main.charge", File: "billing/charge.go", Line: 17}},
+	}
+
+	fields := parseJournaldPayload(t, driver.encode(entry))
+
+	expected := map[string]string{
+		"MESSAGE":           "charge failed: card declined",
+		"PRIORITY":          "3",
+		"SYSLOG_IDENTIFIER": "billing",
+		"LEVEL":             "ERROR",
+		"TRANSACTION_ID":    "tx-1",
+		"CODE_FILE":         "billing/charge.go",
+		"CODE_LINE":         "17",
+		"CODE_FUNC":         "main.charge",
+		"ERROR":             "card declined",
+		"ERROR_TYPE":        "*errors.errorString",
+		"STACKTRACE":        "main.charge (billing/charge.go:17)",
+		"USER_ID":           "42",
+		"HTTP_PATH":         "/pay",
+		"PRIVATE":           "x",
+		"ATTR_2FA":          "on",
+		"DETAILS":           "line one\nline two",
+		"ATTR_PRIORITY":     "low",
+		"ATTR_MESSAGE":      "shadow",
+	}
+
+	for name, value := range expected {
+		if fields[name] != value {
+			t.Errorf("%s: expected %q, got %q", name, value, fields[name])
+		}
+	}
+	if len(fields) != len(expected) {
+		t.Errorf("Expected %d fields, got %d: %v", len(expected), len(fields), fields)
+	}
+}
+
+func TestJournaldFieldName(t *testing.T) {
+	tests := map[string]string{
+		"user":                  "USER",
+		"userId":                "USERID",
+		"__SECRET":              "SECRET",
+		"9lives":                "ATTR_9LIVES",
+		"message":               "ATTR_MESSAGE",
+		"Syslog-Identifier":     "ATTR_SYSLOG_IDENTIFIER",
+		"code.file":             "ATTR_CODE_FILE",
+		"_transaction_id":       "ATTR_TRANSACTION_ID",
+		"":                      "ATTR_",
+		strings.Repeat("a", 70): strings.Repeat("A", 64),
+	}
+
+	for key, expected := range tests {
+		if got := journaldFieldName(key); got != expected {
+			t.Errorf("%q: expected %q, got %q", key, expected, got)
+		}
+	}
+}
+
+// listenJournald creates a unixgram socket standing in for journald
+func listenJournald(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "journald")
+	if err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "socket")
+	listener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	return listener, path
+}
+
+func TestJournaldDriverSocket(t *testing.T) {
+	listener, path := listenJournald(t)
+
+	driver, err := Create(JournaldDriverName, map[string]interface{}{
+		"socket":     path,
+		"identifier": "billing",
+		"min_level":  "info",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+	defer driver.Close()
+
+	driver.Log(&core.LogEntry{Level: core.Debug, Message: "filtered"})
+	if err := driver.Log(&core.LogEntry{Level: core.Notice, Message: "started", Attrs: core.Attributes{"port": "8080"}}); err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+
+	buf := make([]byte, 4096)
+	listener.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := listener.Read(buf)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+
+	fields := parseJournaldPayload(t, buf[:n])
+	if fields["MESSAGE"] != "started" || fields["PRIORITY"] != "5" || fields["PORT"] != "8080" {
+		t.Errorf("Unexpected fields: %v", fields)
+	}
+}
+
+func TestJournaldDriverErrors(t *testing.T) {
+	driver := NewJournaldDriverWithConfig(JournaldConfig{Socket: filepath.Join(t.TempDir(), "missing")})
+	if err := driver.Log(&core.LogEntry{Level: core.Info, Message: "lost"}); err == nil {
+		t.Error("Expected an error without journald")
+	}
+
+	driver.Close()
+	if err := driver.Log(&core.LogEntry{Level: core.Info, Message: "late"}); err == nil {
+		t.Error("Expected an error after Close")
+	}
+}
@@ -22,7 +22,7 @@ type JSONFileDriver struct {
 	filePath string
 	file     *os.File
 	encoder  *json.Encoder
-	minLevel core.Level
+	filter   *core.LevelFilter
 	mu       sync.Mutex
 }
 
@@ -33,6 +33,64 @@ type JSONLogEntry struct {
 	Message       string            `json:"message"`
 	Attributes    map[string]string `json:"attributes,omitempty"`
 	TransactionID string            `json:"transaction_id,omitempty"`
+	Error         *JSONError        `json:"error,omitempty"`
+	Caller        *JSONCaller       `json:"caller,omitempty"`
+	Stack         []string          `json:"stack,omitempty"`
+}
+
+// JSONCaller represents the call site of a log entry in JSON format
+type JSONCaller struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function,omitempty"`
+}
+
+// JSONError represents an attached Go error in JSON format
+type JSONError struct {
+	Message string           `json:"message"`
+	Chain   []JSONErrorCause `json:"chain,omitempty"`
+	Stack   []string         `json:"stack,omitempty"`
+}
+
+// JSONErrorCause represents one error of an unwrap chain in JSON format
+type JSONErrorCause struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// NewJSONLogEntry converts a log entry to its JSON representation
+func NewJSONLogEntry(entry *core.LogEntry) *JSONLogEntry {
+	jsonEntry := &JSONLogEntry{
+		Timestamp:     entry.Timestamp.Format("2006-01-02T15:04:05.000Z07:00"),
+		Level:         entry.Level.String(),
+		Message:       entry.Message,
+		Attributes:    entry.Attrs,
+		TransactionID: entry.TransactionID,
+		Stack:         formatFrames(entry.Stack),
+	}
+
+	if entry.Caller != nil {
+		jsonEntry.Caller = &JSONCaller{
+			File:     entry.Caller.File,
+			Line:     entry.Caller.Line,
+			Function: entry.Caller.Function,
+		}
+	}
+
+	if entry.Error != nil {
+		jsonEntry.Error = &JSONError{
+			Message: entry.Error.Message,
+			Stack:   formatFrames(entry.Error.Stack),
+		}
+		for _, cause := range entry.Error.Chain {
+			jsonEntry.Error.Chain = append(jsonEntry.Error.Chain, JSONErrorCause{
+				Type:    cause.Type,
+				Message: cause.Message,
+			})
+		}
+	}
+
+	return jsonEntry
 }
 
 // NewJSONFileDriver creates a new JSON file driver from a map of options
@@ -42,6 +100,11 @@ func NewJSONFileDriver(options map[string]interface{}) (core.Driver, error) {
 		return nil, fmt.Errorf("file_path is required")
 	}
 
+	filter, err := newLevelFilter(options)
+	if err != nil {
+		return nil, err
+	}
+
 	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create directory: %w", err)
@@ -56,13 +119,7 @@ func NewJSONFileDriver(options map[string]interface{}) (core.Driver, error) {
 		filePath: filePath,
 		file:     file,
 		encoder:  json.NewEncoder(file),
-		minLevel: core.Debug,
-	}
-
-	if levelStr, ok := options["min_level"].(string); ok {
-		if level, err := core.ParseLevel(levelStr); err == nil {
-			driver.minLevel = level
-		}
+		filter:   filter,
 	}
 
 	return driver, nil
@@ -70,7 +127,7 @@ func NewJSONFileDriver(options map[string]interface{}) (core.Driver, error) {
 
 // Log writes a log entry to the JSON file
 func (d *JSONFileDriver) Log(entry *core.LogEntry) error {
-	if entry.Level < d.minLevel {
+	if !d.filter.Allows(entry.Level) {
 		return nil
 	}
 
@@ -81,21 +138,17 @@ func (d *JSONFileDriver) Log(entry *core.LogEntry) error {
 		return fmt.Errorf("driver is closed")
 	}
 
-	jsonEntry := struct {
-		Timestamp     string            `json:"timestamp"`
-		Level         string            `json:"level"`
-		Message       string            `json:"message"`
-		Attributes    map[string]string `json:"attributes,omitempty"`
-		TransactionID string            `json:"transaction_id,omitempty"`
-	}{
-		Timestamp:     entry.Timestamp.Format("2006-01-02T15:04:05.000Z07:00"),
-		Level:         entry.Level.String(),
-		Message:       entry.Message,
-		Attributes:    entry.Attrs,
-		TransactionID: entry.TransactionID,
-	}
+	return d.encoder.Encode(NewJSONLogEntry(entry))
+}
+
+// Name returns the driver name used in configuration
+func (d *JSONFileDriver) Name() string {
+	return JSONFileDriverName
+}
 
-	return d.encoder.Encode(jsonEntry)
+// Enabled reports whether the driver accepts entries at the level
+func (d *JSONFileDriver) Enabled(level core.Level) bool {
+	return d.filter.Allows(level)
 }
 
 // Close closes the file
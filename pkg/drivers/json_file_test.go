@@ -271,3 +271,142 @@ func TestJSONFileDriverClose(t *testing.T) {
 		t.Error("Expected error when writing to closed driver")
 	}
 }
+
+func TestJSONFileDriverError(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "error_test.json")
+
+	driver, err := NewJSONFileDriver(map[string]interface{}{
+		"file_path": filePath,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	entry := &core.LogEntry{
+		Timestamp: time.Now(),
+		Level:     core.Error,
+		Message:   "request failed",
+		Error: &core.ErrorInfo{
+			Message: "fetch: timeout",
+			Chain: []core.ErrorCause{
+				{Type: "*fmt.wrapError", Message: "fetch: timeout"},
+				{Type: "*errors.errorString", Message: "timeout"},
+			},
+			Stack: []core.Frame{{Function: "main.fetch", File: "/app/main.go", Line: 12}},
+		},
+	}
+
+	if err := driver.Log(entry); err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	driver.Close()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	var logEntry struct {
+		Error struct {
+			Message string `json:"message"`
+			Chain   []struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"chain"`
+			Stack []string `json:"stack"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &logEntry); err != nil {
+		t.Fatalf("Failed to decode JSON: %v", err)
+	}
+
+	if logEntry.Error.Message != "fetch: timeout" {
+		t.Errorf("error.message = %q, want %q", logEntry.Error.Message, "fetch: timeout")
+	}
+	if len(logEntry.Error.Chain) != 2 || logEntry.Error.Chain[1].Type != "*errors.errorString" {
+		t.Errorf("error.chain = %+v, want 2 causes", logEntry.Error.Chain)
+	}
+	if len(logEntry.Error.Stack) != 1 || logEntry.Error.Stack[0] != "main.fetch (/app/main.go:12)" {
+		t.Errorf("error.stack = %v", logEntry.Error.Stack)
+	}
+}
+
+func TestJSONFileDriverCaller(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "caller_test.json")
+
+	driver, err := NewJSONFileDriver(map[string]interface{}{
+		"file_path": filePath,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	err = driver.Log(&core.LogEntry{
+		Timestamp: time.Now(),
+		Level:     core.Info,
+		Message:   "message",
+		Caller:    &core.Frame{File: "app/main.go", Line: 42, Function: "main.main"},
+	})
+	if err != nil {
+		t.Errorf("Log() error = %v", err)
+	}
+	driver.Close()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	var logEntry struct {
+		Caller struct {
+			File     string `json:"file"`
+			Line     int    `json:"line"`
+			Function string `json:"function"`
+		} `json:"caller"`
+	}
+	if err := json.Unmarshal(data, &logEntry); err != nil {
+		t.Fatalf("Failed to decode JSON: %v", err)
+	}
+
+	if logEntry.Caller.File != "app/main.go" || logEntry.Caller.Line != 42 || logEntry.Caller.Function != "main.main" {
+		t.Errorf("caller = %+v", logEntry.Caller)
+	}
+}
+
+func TestJSONFileDriverStack(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "stack_test.json")
+
+	driver, err := NewJSONFileDriver(map[string]interface{}{
+		"file_path": filePath,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	err = driver.Log(&core.LogEntry{
+		Timestamp: time.Now(),
+		Level:     core.Error,
+		Message:   "message",
+		Stack:     []core.Frame{{Function: "main.handler", File: "/app/main.go", Line: 42}},
+	})
+	if err != nil {
+		t.Errorf("Log() error = %v", err)
+	}
+	driver.Close()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	var logEntry struct {
+		Stack []string `json:"stack"`
+	}
+	if err := json.Unmarshal(data, &logEntry); err != nil {
+		t.Fatalf("Failed to decode JSON: %v", err)
+	}
+
+	if len(logEntry.Stack) != 1 || logEntry.Stack[0] != "main.handler (/app/main.go:42)" {
+		t.Errorf("stack = %v", logEntry.Stack)
+	}
+}
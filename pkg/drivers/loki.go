@@ -0,0 +1,452 @@
+package drivers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+	"github.com/MaoDaGreith/logging/pkg/internal/optparse"
+)
+
+// LokiDriverName is the name to use in configuration
+const LokiDriverName = "loki"
+
+func init() {
+	Register(LokiDriverName, NewLokiDriver)
+}
+
+// LokiEncoding is the body format of Loki push requests
+type LokiEncoding string
+
+const (
+	// LokiProtobuf sends snappy-compressed protobuf, the format of Loki's own clients
+	LokiProtobuf LokiEncoding = "protobuf"
+
+	// LokiJSON sends the JSON form of the push API
+	LokiJSON LokiEncoding = "json"
+)
+
+// LokiAttributes controls where attributes that are not labels end up
+type LokiAttributes string
+
+const (
+	// LokiStructuredMetadata attaches them to each line as structured metadata
+	LokiStructuredMetadata LokiAttributes = "structured_metadata"
+
+	// LokiLine appends them to the line as logfmt key=value pairs
+	LokiLine LokiAttributes = "line"
+)
+
+// LokiConfig configures a LokiDriver
+type LokiConfig struct {
+	// HTTP configures the push requests; URL is the push endpoint, e.g.
+	// http://localhost:3100/loki/api/v1/push
+	HTTP HTTPConfig
+
+	// Batch configures how entries are grouped into requests
+	Batch BatchConfig
+
+	// Encoding defaults to LokiProtobuf
+	Encoding LokiEncoding
+
+	// Labels lists the attributes promoted to stream labels, besides level
+	Labels []string
+
+	// StaticLabels are added to every stream, e.g. service or env
+	StaticLabels map[string]string
+
+	// Attributes defaults to LokiStructuredMetadata
+	Attributes LokiAttributes
+
+	// TenantID is sent as X-Scope-OrgID for multi-tenant Loki
+	TenantID string
+}
+
+// LokiDriver pushes entries to Grafana Loki. Entries are grouped into
+// streams by their level and the configured label attributes; the other
+// attributes, the transaction ID, the caller and the error are kept as
+// structured metadata or appended to the line.
+type LokiDriver struct {
+	config  LokiConfig
+	filter  *core.LevelFilter
+	sender  *httpSender
+	batcher *batcher
+}
+
+// NewLokiDriver creates a new Loki driver from a map of options
+func NewLokiDriver(options map[string]interface{}) (core.Driver, error) {
+	filter, err := newLevelFilter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	config := LokiConfig{}
+	if config.HTTP, err = newHTTPConfig(options); err != nil {
+		return nil, err
+	}
+	if config.Batch, err = newBatchConfig(options); err != nil {
+		return nil, err
+	}
+	if encoding, ok := options["encoding"].(string); ok {
+		config.Encoding = LokiEncoding(encoding)
+	}
+	if config.Labels, err = optparse.StringSlice(options["labels"]); err != nil {
+		return nil, fmt.Errorf("invalid labels: %w", err)
+	}
+	if config.StaticLabels, err = optparse.StringMap(options["static_labels"]); err != nil {
+		return nil, fmt.Errorf("invalid static_labels: %w", err)
+	}
+	if attributes, ok := options["attributes"].(string); ok {
+		config.Attributes = LokiAttributes(attributes)
+	}
+	if tenant, ok := options["tenant_id"].(string); ok {
+		config.TenantID = tenant
+	}
+
+	driver, err := NewLokiDriverWithConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	driver.filter = filter
+	return driver, nil
+}
+
+// NewLokiDriverWithConfig creates a Loki driver and starts its background
+// goroutine
+func NewLokiDriverWithConfig(config LokiConfig) (*LokiDriver, error) {
+	switch config.Encoding {
+	case "":
+		config.Encoding = LokiProtobuf
+	case LokiProtobuf, LokiJSON:
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", config.Encoding)
+	}
+
+	switch config.Attributes {
+	case "":
+		config.Attributes = LokiStructuredMetadata
+	case LokiStructuredMetadata, LokiLine:
+	default:
+		return nil, fmt.Errorf("unknown attributes mode %q", config.Attributes)
+	}
+
+	// Protobuf bodies are snappy-compressed; Loki does not accept gzip on top
+	if config.Encoding == LokiProtobuf && config.HTTP.Gzip {
+		return nil, fmt.Errorf("gzip is only supported with the json encoding")
+	}
+
+	for _, name := range append(append([]string{}, config.Labels...), sortedKeys(config.StaticLabels)...) {
+		if lokiLabelName(name) == "" {
+			return nil, fmt.Errorf("invalid label name %q", name)
+		}
+	}
+
+	if config.TenantID != "" {
+		headers := make(map[string]string, len(config.HTTP.Headers)+1)
+		for key, value := range config.HTTP.Headers {
+			headers[key] = value
+		}
+		headers["X-Scope-OrgID"] = config.TenantID
+		config.HTTP.Headers = headers
+	}
+
+	sender, err := newHTTPSender(config.HTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	driver := &LokiDriver{
+		config: config,
+		sender: sender,
+	}
+	driver.batcher = newBatcher(config.Batch, driver.send)
+
+	return driver, nil
+}
+
+// Log queues the entry for the next push
+func (d *LokiDriver) Log(entry *core.LogEntry) error {
+	if !d.filter.Allows(entry.Level) {
+		return nil
+	}
+
+	return d.batcher.add(entry)
+}
+
+// Flush pushes the queued entries and waits for the request to finish
+func (d *LokiDriver) Flush() error {
+	return d.batcher.flush()
+}
+
+// Close pushes the queued entries and stops the background goroutine
+func (d *LokiDriver) Close() error {
+	return d.batcher.close()
+}
+
+// Name returns the driver name
+func (d *LokiDriver) Name() string {
+	return LokiDriverName
+}
+
+// Enabled reports whether the driver accepts entries at the level
+func (d *LokiDriver) Enabled(level core.Level) bool {
+	return d.filter.Allows(level)
+}
+
+// lokiStream is a set of entries sharing the same labels
+type lokiStream struct {
+	labels  map[string]string
+	entries []lokiEntry
+}
+
+// lokiEntry is one line of a stream
+type lokiEntry struct {
+	timestamp int64
+	line      string
+	metadata  [][2]string
+}
+
+// send encodes and pushes one batch
+func (d *LokiDriver) send(batch []*core.LogEntry) error {
+	streams := d.streams(batch)
+
+	var body []byte
+	var contentType string
+	if d.config.Encoding == LokiJSON {
+		var err error
+		if body, err = encodeLokiJSON(streams); err != nil {
+			return err
+		}
+		contentType = "application/json"
+	} else {
+		body = snappyEncode(encodeLokiProtobuf(streams))
+		contentType = "application/x-protobuf"
+	}
+
+	if _, err := d.sender.send(body, contentType); err != nil {
+		return fmt.Errorf("failed to push %d entries to Loki: %w", len(batch), err)
+	}
+
+	return nil
+}
+
+// streams groups a batch into streams, in order of first appearance. Loki
+// rejects out of order entries in older versions, so each stream is sorted
+// by time.
+func (d *LokiDriver) streams(batch []*core.LogEntry) []*lokiStream {
+	var streams []*lokiStream
+	byKey := make(map[string]*lokiStream)
+
+	for _, entry := range batch {
+		labels := d.labels(entry)
+		key := formatLokiLabels(labels)
+
+		stream, ok := byKey[key]
+		if !ok {
+			stream = &lokiStream{labels: labels}
+			byKey[key] = stream
+			streams = append(streams, stream)
+		}
+		stream.entries = append(stream.entries, d.entry(entry))
+	}
+
+	for _, stream := range streams {
+		sort.SliceStable(stream.entries, func(i, j int) bool {
+			return stream.entries[i].timestamp < stream.entries[j].timestamp
+		})
+	}
+
+	return streams
+}
+
+// labels returns the stream labels of an entry
+func (d *LokiDriver) labels(entry *core.LogEntry) map[string]string {
+	labels := make(map[string]string, len(d.config.StaticLabels)+len(d.config.Labels)+1)
+	for name, value := range d.config.StaticLabels {
+		labels[lokiLabelName(name)] = value
+	}
+	for _, key := range d.config.Labels {
+		if value, ok := entry.Attrs[key]; ok {
+			labels[lokiLabelName(key)] = value
+		}
+	}
+	labels["level"] = strings.ToLower(entry.Level.String())
+
+	return labels
+}
+
+// entry builds the line and structured metadata of an entry
+func (d *LokiDriver) entry(entry *core.LogEntry) lokiEntry {
+	var fields [][2]string
+	for _, key := range sortedKeys(entry.Attrs) {
+		if !d.isLabel(key) {
+			fields = append(fields, [2]string{key, entry.Attrs[key]})
+		}
+	}
+	if entry.TransactionID != "" {
+		fields = append(fields, [2]string{"transaction_id", entry.TransactionID})
+	}
+	if entry.Caller != nil {
+		fields = append(fields, [2]string{"caller", formatCaller(entry.Caller)})
+	}
+	if entry.Error != nil {
+		fields = append(fields, [2]string{"error", entry.Error.Message})
+	}
+
+	result := lokiEntry{
+		timestamp: entry.Timestamp.UnixNano(),
+		line:      entry.Message,
+	}
+
+	if d.config.Attributes == LokiLine {
+		var builder strings.Builder
+		builder.WriteString(entry.Message)
+		for _, field := range fields {
+			builder.WriteString(" ")
+			builder.WriteString(field[0])
+			builder.WriteString("=")
+			builder.WriteString(logfmtValue(field[1]))
+		}
+		result.line = builder.String()
+	} else {
+		// Structured metadata names follow the label name rules
+		for i := range fields {
+			fields[i][0] = lokiLabelName(fields[i][0])
+		}
+		result.metadata = fields
+	}
+
+	if len(entry.Stack) > 0 {
+		result.line += formatStack(entry.Stack, "  ")
+	}
+
+	return result
+}
+
+// isLabel reports whether an attribute is promoted to a label
+func (d *LokiDriver) isLabel(key string) bool {
+	for _, label := range d.config.Labels {
+		if label == key {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeLokiJSON encodes the JSON push request:
+// {"streams":[{"stream":{...},"values":[["<ns>","<line>",{...}]]}]}
+func encodeLokiJSON(streams []*lokiStream) ([]byte, error) {
+	type jsonStream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][]interface{}   `json:"values"`
+	}
+
+	request := struct {
+		Streams []jsonStream `json:"streams"`
+	}{Streams: make([]jsonStream, 0, len(streams))}
+
+	for _, stream := range streams {
+		values := make([][]interface{}, len(stream.entries))
+		for i, entry := range stream.entries {
+			value := []interface{}{strconv.FormatInt(entry.timestamp, 10), entry.line}
+			if len(entry.metadata) > 0 {
+				metadata := make(map[string]string, len(entry.metadata))
+				for _, pair := range entry.metadata {
+					metadata[pair[0]] = pair[1]
+				}
+				value = append(value, metadata)
+			}
+			values[i] = value
+		}
+
+		request.Streams = append(request.Streams, jsonStream{Stream: stream.labels, Values: values})
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode push request: %w", err)
+	}
+	return body, nil
+}
+
+// encodeLokiProtobuf encodes the logproto.PushRequest message:
+//
+//	PushRequest   { repeated StreamAdapter streams = 1; }
+//	StreamAdapter { string labels = 1; repeated EntryAdapter entries = 2; }
+//	EntryAdapter  { Timestamp timestamp = 1; string line = 2;
+//	                repeated LabelPairAdapter structuredMetadata = 3; }
+//	LabelPairAdapter { string name = 1; string value = 2; }
+func encodeLokiProtobuf(streams []*lokiStream) []byte {
+	var request protoBuffer
+	for _, stream := range streams {
+		request.message(1, func(s *protoBuffer) {
+			s.string(1, formatLokiLabels(stream.labels))
+			for _, entry := range stream.entries {
+				entry := entry
+				s.message(2, func(e *protoBuffer) {
+					e.message(1, func(ts *protoBuffer) {
+						ts.int(1, entry.timestamp/1e9)
+						ts.int(2, entry.timestamp%1e9)
+					})
+					e.string(2, entry.line)
+					for _, pair := range entry.metadata {
+						pair := pair
+						e.message(3, func(p *protoBuffer) {
+							p.string(1, pair[0])
+							p.string(2, pair[1])
+						})
+					}
+				})
+			}
+		})
+	}
+
+	return request
+}
+
+// formatLokiLabels renders labels in the Prometheus selector syntax Loki
+// expects, sorted by name: {level="info", service="billing"}
+func formatLokiLabels(labels map[string]string) string {
+	var builder strings.Builder
+	builder.WriteString("{")
+	for i, name := range sortedKeys(labels) {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(name)
+		builder.WriteString("=")
+		builder.WriteString(strconv.Quote(labels[name]))
+	}
+	builder.WriteString("}")
+
+	return builder.String()
+}
+
+// lokiLabelName turns a key into a valid label name by replacing invalid
+// characters with underscores, e.g. http.method becomes http_method. It
+// returns an empty string for keys that cannot be fixed.
+func lokiLabelName(key string) string {
+	name := []byte(key)
+	for i, c := range name {
+		valid := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')
+		if !valid {
+			name[i] = '_'
+		}
+	}
+	if strings.Trim(string(name), "_") == "" {
+		return ""
+	}
+
+	return string(name)
+}
+
+// logfmtValue quotes a value if it would not read back as a single logfmt value
+func logfmtValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " =\"\t\r\n\\") {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,269 @@
+package drivers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// lokiPushedEntry is an entry decoded from a push request
+type lokiPushedEntry struct {
+	labels    string
+	timestamp int64
+	line      string
+	metadata  map[string]string
+}
+
+// decodeLokiProtobuf decodes a snappy-compressed protobuf push request
+func decodeLokiProtobuf(t *testing.T, body []byte) []lokiPushedEntry {
+	t.Helper()
+
+	data, err := snappyDecode(body)
+	if err != nil {
+		t.Fatalf("snappyDecode() error = %v", err)
+	}
+
+	var entries []lokiPushedEntry
+	for _, stream := range protoGet(parseProto(t, data), 1) {
+		streamFields := parseProto(t, stream.bytes)
+		labels := string(protoGet(streamFields, 1)[0].bytes)
+
+		for _, entry := range protoGet(streamFields, 2) {
+			entryFields := parseProto(t, entry.bytes)
+			timestamp := parseProto(t, protoGet(entryFields, 1)[0].bytes)
+			pushed := lokiPushedEntry{
+				labels:    labels,
+				timestamp: int64(protoGet(timestamp, 1)[0].varint)*1e9 + int64(protoGet(timestamp, 2)[0].varint),
+				line:      string(protoGet(entryFields, 2)[0].bytes),
+				metadata:  make(map[string]string),
+			}
+			for _, pair := range protoGet(entryFields, 3) {
+				pairFields := parseProto(t, pair.bytes)
+				pushed.metadata[string(pairFields[0].bytes)] = string(pairFields[1].bytes)
+			}
+			entries = append(entries, pushed)
+		}
+	}
+
+	return entries
+}
+
+func testLokiEntries() []*core.LogEntry {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)
+
+	return []*core.LogEntry{
+		{
+			Timestamp: base.Add(time.Second),
+			Level:     core.Info,
+			Message:   "served",
+			Attrs:     core.Attributes{"service": "billing", "user.id": "42"},
+		},
+		{
+			Timestamp:     base,
+			Level:         core.Info,
+			Message:       "started",
+			Attrs:         core.Attributes{"service": "billing"},
+			TransactionID: "tx-1",
+		},
+		{
+			Timestamp: base,
+			Level:     core.Error,
+			Message:   "failed",
+			Attrs:     core.Attributes{"service": "billing", "path": "/pay now"},
+			Error:     &core.ErrorInfo{Message: "timeout"},
+		},
+	}
+}
+
+func newTestLokiDriver(t *testing.T, options map[string]interface{}) *LokiDriver {
+	t.Helper()
+
+	options["initial_backoff"] = "1ms"
+	options["linger"] = "1h"
+	driver, err := NewLokiDriver(options)
+	if err != nil {
+		t.Fatalf("NewLokiDriver() error = %v", err)
+	}
+	t.Cleanup(func() { driver.Close() })
+
+	return driver.(*LokiDriver)
+}
+
+func TestLokiDriverProtobuf(t *testing.T) {
+	server := newHTTPServer(t)
+	driver := newTestLokiDriver(t, map[string]interface{}{
+		"url":           server.URL,
+		"labels":        []interface{}{"service"},
+		"static_labels": map[string]interface{}{"env": "prod"},
+		"tenant_id":     "team-a",
+	})
+
+	for _, entry := range testLokiEntries() {
+		if err := driver.Log(entry); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+	}
+	if err := driver.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	requests := server.received()
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	if got := requests[0].header.Get("Content-Type"); got != "application/x-protobuf" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := requests[0].header.Get("X-Scope-OrgID"); got != "team-a" {
+		t.Errorf("X-Scope-OrgID = %q", got)
+	}
+
+	entries := decodeLokiProtobuf(t, requests[0].body)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+
+	infoLabels := `{env="prod", level="info", service="billing"}`
+	if entries[0].labels != infoLabels || entries[0].line != "started" {
+		t.Errorf("first entry = %+v, want the earlier info entry first", entries[0])
+	}
+	if entries[0].timestamp != testLokiEntries()[1].Timestamp.UnixNano() {
+		t.Errorf("timestamp = %d", entries[0].timestamp)
+	}
+	if entries[0].metadata["transaction_id"] != "tx-1" {
+		t.Errorf("metadata = %v", entries[0].metadata)
+	}
+	if entries[1].labels != infoLabels || entries[1].metadata["user_id"] != "42" {
+		t.Errorf("second entry = %+v", entries[1])
+	}
+	if _, ok := entries[1].metadata["service"]; ok {
+		t.Error("label attribute also kept as metadata")
+	}
+	if entries[2].labels != `{env="prod", level="error", service="billing"}` || entries[2].metadata["error"] != "timeout" {
+		t.Errorf("third entry = %+v", entries[2])
+	}
+}
+
+func TestLokiDriverJSON(t *testing.T) {
+	server := newHTTPServer(t)
+	driver := newTestLokiDriver(t, map[string]interface{}{
+		"url":      server.URL,
+		"encoding": "json",
+		"gzip":     true,
+		"labels":   []interface{}{"service"},
+	})
+
+	for _, entry := range testLokiEntries() {
+		driver.Log(entry)
+	}
+	if err := driver.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	requests := server.received()
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+
+	var request struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][]interface{}   `json:"values"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(requests[0].body, &request); err != nil {
+		t.Fatalf("Invalid push request %q: %v", requests[0].body, err)
+	}
+
+	if len(request.Streams) != 2 {
+		t.Fatalf("got %d streams, want 2", len(request.Streams))
+	}
+	info := request.Streams[0]
+	if info.Stream["level"] != "info" || info.Stream["service"] != "billing" || len(info.Values) != 2 {
+		t.Errorf("info stream = %+v", info)
+	}
+
+	value := info.Values[0]
+	if value[0] != "1714564800123456789" || value[1] != "started" {
+		t.Errorf("value = %v", value)
+	}
+	if metadata, ok := value[2].(map[string]interface{}); !ok || metadata["transaction_id"] != "tx-1" {
+		t.Errorf("metadata = %v", value[2])
+	}
+}
+
+func TestLokiDriverAttributesInLine(t *testing.T) {
+	server := newHTTPServer(t)
+	driver := newTestLokiDriver(t, map[string]interface{}{
+		"url":        server.URL,
+		"labels":     []interface{}{"service"},
+		"attributes": "line",
+	})
+
+	driver.Log(testLokiEntries()[2])
+	driver.Flush()
+
+	requests := server.received()
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	entries := decodeLokiProtobuf(t, requests[0].body)
+	want := `failed path="/pay now" error=timeout`
+	if len(entries) != 1 || entries[0].line != want || len(entries[0].metadata) != 0 {
+		t.Errorf("entries = %+v, want line %q", entries, want)
+	}
+}
+
+func TestLokiDriverRetries(t *testing.T) {
+	server := newHTTPServer(t, 429, 502)
+	driver := newTestLokiDriver(t, map[string]interface{}{"url": server.URL})
+
+	driver.Log(testLokiEntries()[0])
+	if err := driver.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got := len(server.received()); got != 3 {
+		t.Errorf("got %d requests, want 3", got)
+	}
+}
+
+func TestLokiLabelName(t *testing.T) {
+	tests := map[string]string{
+		"service":   "service",
+		"http.path": "http_path",
+		"2xx":       "_xx",
+		"k8s-pod":   "k8s_pod",
+		"...":       "",
+	}
+
+	for key, want := range tests {
+		if got := lokiLabelName(key); got != want {
+			t.Errorf("lokiLabelName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestNewLokiDriverInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+	}{
+		{name: "missing url", options: map[string]interface{}{}},
+		{name: "encoding", options: map[string]interface{}{"url": "http://localhost", "encoding": "xml"}},
+		{name: "attributes", options: map[string]interface{}{"url": "http://localhost", "attributes": "drop"}},
+		{name: "gzip protobuf", options: map[string]interface{}{"url": "http://localhost", "gzip": true}},
+		{name: "label", options: map[string]interface{}{"url": "http://localhost", "labels": []interface{}{"-"}}},
+		{name: "labels", options: map[string]interface{}{"url": "http://localhost", "labels": 42}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if driver, err := NewLokiDriver(test.options); err == nil {
+				driver.Close()
+				t.Error("NewLokiDriver() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package drivers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+	"github.com/MaoDaGreith/logging/pkg/internal/optparse"
+)
+
+// MemoryDriverName is the name to use in configuration
+const MemoryDriverName = "memory"
+
+func init() {
+	Register(MemoryDriverName, NewMemoryDriver)
+}
+
+// defaultMemoryMaxEntries is the buffer size when none is configured
+const defaultMemoryMaxEntries = 1000
+
+// memoryEntryOverhead approximates the fixed size of an entry beyond its strings
+const memoryEntryOverhead = 64
+
+// MemoryConfig configures a MemoryDriver
+type MemoryConfig struct {
+	// MaxEntries is the number of entries kept
+	MaxEntries int
+
+	// MaxBytes additionally bounds the approximate size of the kept entries; 0 means no limit
+	MaxBytes int
+
+	// DumpPath is a file the buffer is appended to as JSON Lines when a Fatal
+	// or Panic entry is logged; empty disables the dump
+	DumpPath string
+}
+
+// MemoryDriver keeps the most recent entries in a ring buffer so that they
+// can be queried or exported from a running process, e.g. after a failure.
+type MemoryDriver struct {
+	config  MemoryConfig
+	filter  *core.LevelFilter
+	mu      sync.RWMutex
+	entries []*core.LogEntry
+	sizes   []int
+	head    int
+	count   int
+	bytes   int
+	closed  bool
+}
+
+// MemoryQuery selects entries from a MemoryDriver. Zero fields match every entry.
+type MemoryQuery struct {
+	// Filter selects entries by level
+	Filter *core.LevelFilter
+
+	// TransactionID selects the entries of one transaction
+	TransactionID string
+
+	// Since and Until bound the entry timestamps, inclusively
+	Since time.Time
+	Until time.Time
+
+	// Attrs selects entries having all of these attributes with equal values
+	Attrs core.Attributes
+
+	// Limit keeps only the most recent matching entries
+	Limit int
+}
+
+// NewMemoryDriver creates a new memory driver from a map of options
+func NewMemoryDriver(options map[string]interface{}) (core.Driver, error) {
+	filter, err := newLevelFilter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	config := MemoryConfig{}
+	if config.MaxEntries, err = optparse.Int(options["max_entries"], defaultMemoryMaxEntries); err != nil {
+		return nil, fmt.Errorf("invalid max_entries: %w", err)
+	}
+	if config.MaxBytes, err = optparse.Int(options["max_bytes"], 0); err != nil {
+		return nil, fmt.Errorf("invalid max_bytes: %w", err)
+	}
+	if path, ok := options["dump_path"].(string); ok {
+		config.DumpPath = path
+	}
+
+	driver := NewMemoryDriverWithConfig(config)
+	driver.filter = filter
+	return driver, nil
+}
+
+// NewMemoryDriverWithConfig creates a memory driver. A non-positive
+// MaxEntries uses the default.
+func NewMemoryDriverWithConfig(config MemoryConfig) *MemoryDriver {
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = defaultMemoryMaxEntries
+	}
+
+	return &MemoryDriver{
+		config:  config,
+		entries: make([]*core.LogEntry, config.MaxEntries),
+		sizes:   make([]int, config.MaxEntries),
+	}
+}
+
+// Log adds the entry to the buffer, evicting the oldest entries if needed.
+// Fatal and Panic entries additionally trigger the dump, if configured.
+func (d *MemoryDriver) Log(entry *core.LogEntry) error {
+	if !d.filter.Allows(entry.Level) {
+		return nil
+	}
+
+	size := memoryEntrySize(entry)
+
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return fmt.Errorf("driver is closed")
+	}
+
+	if d.count == len(d.entries) {
+		d.evictOldest()
+	}
+	for d.config.MaxBytes > 0 && d.count > 0 && d.bytes+size > d.config.MaxBytes {
+		d.evictOldest()
+	}
+
+	tail := (d.head + d.count) % len(d.entries)
+	d.entries[tail] = entry
+	d.sizes[tail] = size
+	d.count++
+	d.bytes += size
+	d.mu.Unlock()
+
+	if entry.Level >= core.Fatal && d.config.DumpPath != "" {
+		return d.dumpToFile(d.config.DumpPath)
+	}
+
+	return nil
+}
+
+// evictOldest removes the oldest entry. The caller must hold d.mu.
+func (d *MemoryDriver) evictOldest() {
+	d.bytes -= d.sizes[d.head]
+	d.entries[d.head] = nil
+	d.head = (d.head + 1) % len(d.entries)
+	d.count--
+}
+
+// Entries returns the buffered entries, oldest first
+func (d *MemoryDriver) Entries() []*core.LogEntry {
+	return d.Query(MemoryQuery{})
+}
+
+// Query returns the buffered entries matching the query, oldest first
+func (d *MemoryDriver) Query(query MemoryQuery) []*core.LogEntry {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var result []*core.LogEntry
+	for i := 0; i < d.count; i++ {
+		entry := d.entries[(d.head+i)%len(d.entries)]
+		if query.matches(entry) {
+			result = append(result, entry)
+		}
+	}
+
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[len(result)-query.Limit:]
+	}
+
+	return result
+}
+
+// Len returns the number of buffered entries
+func (d *MemoryDriver) Len() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.count
+}
+
+// Export writes the entries matching the query to w as JSON Lines, in the
+// format of the JSON file driver
+func (d *MemoryDriver) Export(w io.Writer, query MemoryQuery) error {
+	encoder := json.NewEncoder(w)
+	for _, entry := range d.Query(query) {
+		if err := encoder.Encode(NewJSONLogEntry(entry)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Snapshot writes every buffered entry to w as JSON Lines
+func (d *MemoryDriver) Snapshot(w io.Writer) error {
+	return d.Export(w, MemoryQuery{})
+}
+
+// DumpOnSignal writes a snapshot to w every time one of the signals is
+// received, e.g. syscall.SIGUSR1. The returned function stops listening.
+func (d *MemoryDriver) DumpOnSignal(w io.Writer, signals ...os.Signal) (stop func()) {
+	received := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(received, signals...)
+
+	go func() {
+		for {
+			select {
+			case <-received:
+				d.Snapshot(w)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(received)
+			close(done)
+		})
+	}
+}
+
+// DumpOnPanic writes a snapshot to w if the goroutine is panicking and then
+// continues panicking. It must be deferred directly:
+//
+//	defer memory.DumpOnPanic(os.Stderr)
+func (d *MemoryDriver) DumpOnPanic(w io.Writer) {
+	if r := recover(); r != nil {
+		d.Snapshot(w)
+		panic(r)
+	}
+}
+
+// dumpToFile appends a snapshot to the file at path
+func (d *MemoryDriver) dumpToFile(path string) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open dump file: %w", err)
+	}
+
+	if err := d.Snapshot(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write dump file: %w", err)
+	}
+
+	return file.Close()
+}
+
+// Name returns the driver name used in configuration
+func (d *MemoryDriver) Name() string {
+	return MemoryDriverName
+}
+
+// Enabled reports whether the driver accepts entries at the level
+func (d *MemoryDriver) Enabled(level core.Level) bool {
+	return d.filter.Allows(level)
+}
+
+// Close stops accepting entries. The buffer can still be queried.
+func (d *MemoryDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.closed = true
+	return nil
+}
+
+// matches reports whether the entry satisfies every field of the query
+func (q MemoryQuery) matches(entry *core.LogEntry) bool {
+	if !q.Filter.Allows(entry.Level) {
+		return false
+	}
+
+	if q.TransactionID != "" && entry.TransactionID != q.TransactionID {
+		return false
+	}
+
+	if !q.Since.IsZero() && entry.Timestamp.Before(q.Since) {
+		return false
+	}
+
+	if !q.Until.IsZero() && entry.Timestamp.After(q.Until) {
+		return false
+	}
+
+	for key, value := range q.Attrs {
+		if actual, ok := entry.Attrs[key]; !ok || actual != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// memoryEntrySize approximates the memory held by an entry
+func memoryEntrySize(entry *core.LogEntry) int {
+	size := memoryEntryOverhead + len(entry.Message) + len(entry.TransactionID)
+	for key, value := range entry.Attrs {
+		size += len(key) + len(value)
+	}
+
+	if entry.Error != nil {
+		size += len(entry.Error.Message)
+		for _, cause := range entry.Error.Chain {
+			size += len(cause.Type) + len(cause.Message)
+		}
+	}
+
+	return size
+}
@@ -0,0 +1,51 @@
+//go:build !windows
+
+package drivers
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestMemoryDriverDumpOnSignal(t *testing.T) {
+	driver := NewMemoryDriverWithConfig(MemoryConfig{})
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "still running"})
+
+	out := &syncBuffer{}
+	stop := driver.DumpOnSignal(out, syscall.SIGUSR1)
+	defer stop()
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(out.String(), "still running") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !strings.Contains(out.String(), "still running") {
+		t.Error("Expected a snapshot after the signal")
+	}
+}
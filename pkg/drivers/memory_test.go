@@ -0,0 +1,190 @@
+package drivers
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// memoryMessages returns the messages of entries
+func memoryMessages(entries []*core.LogEntry) []string {
+	messages := make([]string, len(entries))
+	for i, entry := range entries {
+		messages[i] = entry.Message
+	}
+	return messages
+}
+
+func TestMemoryDriverRingBuffer(t *testing.T) {
+	driver := NewMemoryDriverWithConfig(MemoryConfig{MaxEntries: 3})
+
+	for _, msg := range []string{"one", "two", "three", "four", "five"} {
+		driver.Log(&core.LogEntry{Level: core.Info, Message: msg})
+	}
+
+	expected := []string{"three", "four", "five"}
+	if got := memoryMessages(driver.Entries()); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if driver.Len() != 3 {
+		t.Errorf("Expected 3 entries, got %d", driver.Len())
+	}
+}
+
+func TestMemoryDriverMaxBytes(t *testing.T) {
+	driver := NewMemoryDriverWithConfig(MemoryConfig{MaxEntries: 100, MaxBytes: 3 * (memoryEntryOverhead + 10)})
+
+	for i := 0; i < 10; i++ {
+		driver.Log(&core.LogEntry{Level: core.Info, Message: strings.Repeat("x", 9) + string(rune('0'+i))})
+	}
+
+	if got := memoryMessages(driver.Entries()); len(got) != 3 || got[2] != "xxxxxxxxx9" {
+		t.Errorf("Expected the 3 most recent entries, got %v", got)
+	}
+
+	// An oversized entry evicts everything else but is still kept
+	driver.Log(&core.LogEntry{Level: core.Info, Message: strings.Repeat("y", 1000)})
+	if driver.Len() != 1 {
+		t.Errorf("Expected only the oversized entry, got %d entries", driver.Len())
+	}
+}
+
+func TestMemoryDriverQuery(t *testing.T) {
+	driver := NewMemoryDriverWithConfig(MemoryConfig{})
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	entries := []*core.LogEntry{
+		{Timestamp: start, Level: core.Info, Message: "request received", TransactionID: "tx-1"},
+		{Timestamp: start.Add(time.Second), Level: core.Debug, Message: "cache miss", TransactionID: "tx-1", Attrs: core.Attributes{"cache": "users"}},
+		{Timestamp: start.Add(2 * time.Second), Level: core.Error, Message: "query failed", TransactionID: "tx-2", Attrs: core.Attributes{"db": "primary"}},
+		{Timestamp: start.Add(3 * time.Second), Level: core.Warning, Message: "slow response", TransactionID: "tx-1", Attrs: core.Attributes{"db": "primary"}},
+	}
+	for _, entry := range entries {
+		driver.Log(entry)
+	}
+
+	tests := []struct {
+		name     string
+		query    MemoryQuery
+		expected []string
+	}{
+		{"all", MemoryQuery{}, []string{"request received", "cache miss", "query failed", "slow response"}},
+		{"level", MemoryQuery{Filter: core.NewLevelFilter(core.MinLevel(core.Warning))}, []string{"query failed", "slow response"}},
+		{"transaction", MemoryQuery{TransactionID: "tx-1"}, []string{"request received", "cache miss", "slow response"}},
+		{"time range", MemoryQuery{Since: start.Add(time.Second), Until: start.Add(2 * time.Second)}, []string{"cache miss", "query failed"}},
+		{"attrs", MemoryQuery{Attrs: core.Attributes{"db": "primary"}}, []string{"query failed", "slow response"}},
+		{"combined", MemoryQuery{TransactionID: "tx-1", Attrs: core.Attributes{"db": "primary"}}, []string{"slow response"}},
+		{"limit", MemoryQuery{Limit: 2}, []string{"query failed", "slow response"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := memoryMessages(driver.Query(test.query)); !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestMemoryDriverSnapshot(t *testing.T) {
+	driver := NewMemoryDriverWithConfig(MemoryConfig{})
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "first", Attrs: core.Attributes{"k": "v"}})
+	driver.Log(&core.LogEntry{Level: core.Error, Message: "second"})
+
+	var buf bytes.Buffer
+	if err := driver.Snapshot(&buf); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %q", len(lines), buf.String())
+	}
+
+	var decoded JSONLogEntry
+	if err := json.Unmarshal([]byte(lines[0]), &decoded); err != nil {
+		t.Fatalf("Invalid JSON line: %v", err)
+	}
+	if decoded.Message != "first" || decoded.Level != "INFO" || decoded.Attributes["k"] != "v" {
+		t.Errorf("Unexpected entry: %+v", decoded)
+	}
+}
+
+func TestMemoryDriverDumpOnFatal(t *testing.T) {
+	dumpPath := filepath.Join(t.TempDir(), "dump.jsonl")
+	driver := NewMemoryDriverWithConfig(MemoryConfig{DumpPath: dumpPath})
+
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "context"})
+	if _, err := os.Stat(dumpPath); !os.IsNotExist(err) {
+		t.Fatal("Expected no dump before a fatal entry")
+	}
+
+	if err := driver.Log(&core.LogEntry{Level: core.Fatal, Message: "giving up"}); err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+
+	content, err := os.ReadFile(dumpPath)
+	if err != nil {
+		t.Fatalf("Failed to read dump: %v", err)
+	}
+	if !strings.Contains(string(content), "context") || !strings.Contains(string(content), "giving up") {
+		t.Errorf("Unexpected dump: %q", content)
+	}
+}
+
+func TestMemoryDriverDumpOnPanic(t *testing.T) {
+	driver := NewMemoryDriverWithConfig(MemoryConfig{})
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "before the crash"})
+
+	var buf bytes.Buffer
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("Expected the panic to continue, got %v", r)
+			}
+		}()
+		defer driver.DumpOnPanic(&buf)
+		panic("boom")
+	}()
+
+	if !strings.Contains(buf.String(), "before the crash") {
+		t.Errorf("Expected a snapshot, got %q", buf.String())
+	}
+}
+
+func TestMemoryDriverFromOptions(t *testing.T) {
+	driver, err := Create(MemoryDriverName, map[string]interface{}{
+		"max_entries": 50,
+		"max_bytes":   "4096",
+		"min_level":   "info",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	memory := driver.(*MemoryDriver)
+	if memory.config.MaxEntries != 50 || memory.config.MaxBytes != 4096 {
+		t.Errorf("Unexpected config: %+v", memory.config)
+	}
+
+	memory.Log(&core.LogEntry{Level: core.Debug, Message: "filtered"})
+	if memory.Len() != 0 {
+		t.Error("Expected the level filter to apply")
+	}
+
+	memory.Close()
+	if err := memory.Log(&core.LogEntry{Level: core.Info}); err == nil {
+		t.Error("Expected an error after Close")
+	}
+
+	if _, err := Create(MemoryDriverName, map[string]interface{}{"max_entries": "many"}); err == nil {
+		t.Error("Expected an error for an invalid max_entries")
+	}
+}
@@ -0,0 +1,286 @@
+package drivers
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// msgpackMaxLength bounds the strings and collections read from a peer
+const msgpackMaxLength = 64 << 20
+
+// msgpackMaxPrealloc bounds the capacity allocated for a collection before
+// its values have been read
+const msgpackMaxPrealloc = 1024
+
+// msgpackEventTimeType is the extension type of Fluentd EventTime values
+const msgpackEventTimeType = 0
+
+// msgpackBuffer appends values in the MessagePack format. It covers what the
+// Fluentd Forward protocol needs, without a MessagePack library.
+type msgpackBuffer []byte
+
+// arrayHeader starts an array of n values
+func (b *msgpackBuffer) arrayHeader(n int) {
+	switch {
+	case n < 16:
+		*b = append(*b, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		*b = append(*b, 0xdc, byte(n>>8), byte(n))
+	default:
+		*b = append(*b, 0xdd)
+		*b = appendUint32(*b, uint32(n))
+	}
+}
+
+// mapHeader starts a map of n key and value pairs
+func (b *msgpackBuffer) mapHeader(n int) {
+	switch {
+	case n < 16:
+		*b = append(*b, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		*b = append(*b, 0xde, byte(n>>8), byte(n))
+	default:
+		*b = append(*b, 0xdf)
+		*b = appendUint32(*b, uint32(n))
+	}
+}
+
+// string appends a string
+func (b *msgpackBuffer) string(v string) {
+	switch n := len(v); {
+	case n < 32:
+		*b = append(*b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		*b = append(*b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		*b = append(*b, 0xda, byte(n>>8), byte(n))
+	default:
+		*b = append(*b, 0xdb)
+		*b = appendUint32(*b, uint32(n))
+	}
+	*b = append(*b, v...)
+}
+
+// bin appends binary data
+func (b *msgpackBuffer) bin(v []byte) {
+	switch n := len(v); {
+	case n <= math.MaxUint8:
+		*b = append(*b, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		*b = append(*b, 0xc5, byte(n>>8), byte(n))
+	default:
+		*b = append(*b, 0xc6)
+		*b = appendUint32(*b, uint32(n))
+	}
+	*b = append(*b, v...)
+}
+
+// uint appends an unsigned integer in the smallest encoding
+func (b *msgpackBuffer) uint(v uint64) {
+	switch {
+	case v < 128:
+		*b = append(*b, byte(v))
+	case v <= math.MaxUint8:
+		*b = append(*b, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		*b = append(*b, 0xcd, byte(v>>8), byte(v))
+	case v <= math.MaxUint32:
+		*b = append(*b, 0xce)
+		*b = appendUint32(*b, uint32(v))
+	default:
+		*b = append(*b, 0xcf)
+		*b = appendUint32(*b, uint32(v>>32))
+		*b = appendUint32(*b, uint32(v))
+	}
+}
+
+// eventTime appends a Fluentd EventTime, a fixext 8 holding the seconds and
+// nanoseconds as big-endian 32-bit integers
+func (b *msgpackBuffer) eventTime(t time.Time) {
+	*b = append(*b, 0xd7, msgpackEventTimeType)
+	*b = appendUint32(*b, uint32(t.Unix()))
+	*b = appendUint32(*b, uint32(t.Nanosecond()))
+}
+
+// appendUint32 appends v in big-endian order
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+// msgpackExt is an extension value read by readMsgpack
+type msgpackExt struct {
+	Type int8
+	Data []byte
+}
+
+// readMsgpack reads one value. Maps are returned as map[string]interface{},
+// arrays as []interface{}, integers as int64 or uint64, strings as string,
+// binary data as []byte and extensions as msgpackExt.
+func readMsgpack(r *bufio.Reader) (interface{}, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return readMsgpackMap(r, int(c&0x0f))
+	case c&0xf0 == 0x90:
+		return readMsgpackArray(r, int(c&0x0f))
+	case c&0xe0 == 0xa0:
+		data, err := readMsgpackBytes(r, int(c&0x1f))
+		return string(data), err
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := readMsgpackLength(r, c-0xc4)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackBytes(r, n)
+	case 0xc7, 0xc8, 0xc9:
+		n, err := readMsgpackLength(r, c-0xc7)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackExt(r, n)
+	case 0xca:
+		v, err := readMsgpackUint(r, 4)
+		return float64(math.Float32frombits(uint32(v))), err
+	case 0xcb:
+		v, err := readMsgpackUint(r, 8)
+		return math.Float64frombits(v), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return readMsgpackUint(r, 1<<(c-0xcc))
+	case 0xd0:
+		v, err := readMsgpackUint(r, 1)
+		return int64(int8(v)), err
+	case 0xd1:
+		v, err := readMsgpackUint(r, 2)
+		return int64(int16(v)), err
+	case 0xd2:
+		v, err := readMsgpackUint(r, 4)
+		return int64(int32(v)), err
+	case 0xd3:
+		v, err := readMsgpackUint(r, 8)
+		return int64(v), err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return readMsgpackExt(r, 1<<(c-0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := readMsgpackLength(r, c-0xd9)
+		if err != nil {
+			return nil, err
+		}
+		data, err := readMsgpackBytes(r, n)
+		return string(data), err
+	case 0xdc, 0xdd:
+		n, err := readMsgpackLength(r, c-0xdc+1)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackArray(r, n)
+	case 0xde, 0xdf:
+		n, err := readMsgpackLength(r, c-0xde+1)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackMap(r, n)
+	}
+
+	return nil, fmt.Errorf("invalid MessagePack type 0x%02x", c)
+}
+
+// readMsgpackUint reads a big-endian integer of size bytes
+func readMsgpackUint(r *bufio.Reader, size int) (uint64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[8-size:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf[:]), nil
+}
+
+// readMsgpackLength reads a length of 1, 2 or 4 bytes, for width 0, 1 or 2
+func readMsgpackLength(r *bufio.Reader, width byte) (int, error) {
+	n, err := readMsgpackUint(r, 1<<width)
+	if err != nil {
+		return 0, err
+	}
+	if n > msgpackMaxLength {
+		return 0, fmt.Errorf("MessagePack value of %d bytes is too large", n)
+	}
+	return int(n), nil
+}
+
+func readMsgpackBytes(r *bufio.Reader, n int) ([]byte, error) {
+	data := make([]byte, n)
+	_, err := io.ReadFull(r, data)
+	return data, err
+}
+
+func readMsgpackExt(r *bufio.Reader, n int) (interface{}, error) {
+	typ, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	data, err := readMsgpackBytes(r, n)
+	return msgpackExt{Type: int8(typ), Data: data}, err
+}
+
+func readMsgpackArray(r *bufio.Reader, n int) (interface{}, error) {
+	capacity := n
+	if capacity > msgpackMaxPrealloc {
+		capacity = msgpackMaxPrealloc
+	}
+
+	array := make([]interface{}, 0, capacity)
+	for i := 0; i < n; i++ {
+		v, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, v)
+	}
+	return array, nil
+}
+
+// readMsgpackMap reads a map, converting keys other than strings with
+// fmt.Sprint
+func readMsgpackMap(r *bufio.Reader, n int) (interface{}, error) {
+	capacity := n
+	if capacity > msgpackMaxPrealloc {
+		capacity = msgpackMaxPrealloc
+	}
+
+	m := make(map[string]interface{}, capacity)
+	for i := 0; i < n; i++ {
+		key, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		value, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := key.(string); ok {
+			m[s] = value
+		} else {
+			m[fmt.Sprint(key)] = value
+		}
+	}
+	return m, nil
+}
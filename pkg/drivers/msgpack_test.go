@@ -0,0 +1,139 @@
+package drivers
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeMsgpack(t *testing.T, data []byte) interface{} {
+	t.Helper()
+
+	reader := bufio.NewReader(bytes.NewReader(data))
+	v, err := readMsgpack(reader)
+	if err != nil {
+		t.Fatalf("readMsgpack failed: %v", err)
+	}
+	if reader.Buffered() > 0 {
+		t.Fatalf("%d bytes left after the value", reader.Buffered())
+	}
+
+	return v
+}
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	long := strings.Repeat("x", 300)
+	huge := strings.Repeat("y", 70000)
+
+	var b msgpackBuffer
+	b.arrayHeader(20)
+	b.string("")
+	b.string("short")
+	b.string(strings.Repeat("a", 40))
+	b.string(long)
+	b.string(huge)
+	b.bin([]byte{1, 2, 3})
+	b.bin(bytes.Repeat([]byte{7}, 300))
+	b.bin(bytes.Repeat([]byte{8}, 70000))
+	b.uint(5)
+	b.uint(200)
+	b.uint(60000)
+	b.uint(1 << 20)
+	b.uint(1 << 40)
+	b.mapHeader(1)
+	b.string("k")
+	b.string("v")
+	b.mapHeader(20)
+	for i := 0; i < 20; i++ {
+		b.string(string(rune('a' + i)))
+		b.uint(uint64(i))
+	}
+	b.arrayHeader(17)
+	for i := 0; i < 17; i++ {
+		b.uint(0)
+	}
+	b.eventTime(time.Unix(1714564800, 250))
+	b.arrayHeader(0)
+	b.mapHeader(0)
+	b.string("last")
+
+	got, ok := decodeMsgpack(t, b).([]interface{})
+	if !ok || len(got) != 20 {
+		t.Fatalf("decoded %#v, want an array of 20 values", got)
+	}
+
+	wide := make(map[string]interface{})
+	for i := 0; i < 20; i++ {
+		wide[string(rune('a'+i))] = int64(i)
+	}
+	want := []interface{}{
+		"", "short", strings.Repeat("a", 40), long, huge,
+		[]byte{1, 2, 3}, bytes.Repeat([]byte{7}, 300), bytes.Repeat([]byte{8}, 70000),
+		int64(5), uint64(200), uint64(60000), uint64(1 << 20), uint64(1 << 40),
+		map[string]interface{}{"k": "v"},
+		wide,
+		make([]interface{}, 17),
+		msgpackExt{Type: msgpackEventTimeType, Data: []byte{0x66, 0x32, 0x2e, 0xc0, 0, 0, 0, 250}},
+		[]interface{}{},
+		map[string]interface{}{},
+		"last",
+	}
+	for i := range want[15].([]interface{}) {
+		want[15].([]interface{})[i] = int64(0)
+	}
+
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("value %d = %#v, want %#v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestReadMsgpackTypes(t *testing.T) {
+	tests := []struct {
+		data []byte
+		want interface{}
+	}{
+		{data: []byte{0xc0}, want: nil},
+		{data: []byte{0xc2}, want: false},
+		{data: []byte{0xc3}, want: true},
+		{data: []byte{0xff}, want: int64(-1)},
+		{data: []byte{0xd0, 0x80}, want: int64(-128)},
+		{data: []byte{0xd1, 0xff, 0x00}, want: int64(-256)},
+		{data: []byte{0xd2, 0xff, 0xff, 0xff, 0xfe}, want: int64(-2)},
+		{data: []byte{0xd3, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfd}, want: int64(-3)},
+		{data: []byte{0xca, 0x3f, 0xc0, 0x00, 0x00}, want: 1.5},
+		{data: []byte{0xcb, 0x40, 0x04, 0, 0, 0, 0, 0, 0}, want: 2.5},
+		{data: []byte{0xd4, 0x05, 0x01}, want: msgpackExt{Type: 5, Data: []byte{1}}},
+		{data: []byte{0xc7, 0x02, 0x09, 0x01, 0x02}, want: msgpackExt{Type: 9, Data: []byte{1, 2}}},
+		{data: []byte{0x81, 0x01, 0xa1, 'x'}, want: map[string]interface{}{"1": "x"}},
+	}
+
+	for _, test := range tests {
+		if got := decodeMsgpack(t, test.data); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("readMsgpack(%x) = %#v, want %#v", test.data, got, test.want)
+		}
+	}
+}
+
+func TestReadMsgpackInvalid(t *testing.T) {
+	tests := map[string][]byte{
+		"reserved type":  {0xc1},
+		"truncated":      {0xa5, 'a', 'b'},
+		"truncated map":  {0x82, 0x01},
+		"too large":      {0xc6, 0xff, 0xff, 0xff, 0xff},
+		"huge array":     {0xdd, 0xff, 0xff, 0xff, 0xff},
+		"missing length": {0xda, 0x01},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if v, err := readMsgpack(bufio.NewReader(bytes.NewReader(data))); err == nil {
+				t.Errorf("readMsgpack succeeded with %#v", v)
+			}
+		})
+	}
+}
@@ -0,0 +1,530 @@
+package drivers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+	"github.com/MaoDaGreith/logging/pkg/internal/optparse"
+)
+
+// OTLPDriverName is the name to use in configuration
+const OTLPDriverName = "otlp"
+
+func init() {
+	Register(OTLPDriverName, NewOTLPDriver)
+}
+
+// otlpScopeName is the instrumentation scope of the exported records
+const otlpScopeName = "github.com/MaoDaGreith/logging"
+
+// otlpLogsPath is the default OTLP/HTTP logs endpoint path
+const otlpLogsPath = "/v1/logs"
+
+// OTLPEncoding is the body format of OTLP/HTTP requests
+type OTLPEncoding string
+
+const (
+	// OTLPProtobuf sends binary protobuf
+	OTLPProtobuf OTLPEncoding = "protobuf"
+
+	// OTLPJSON sends the JSON mapping of the protobuf messages
+	OTLPJSON OTLPEncoding = "json"
+)
+
+// OTLPConfig configures an OTLPDriver
+type OTLPConfig struct {
+	// HTTP configures the export requests. URL is the collector address,
+	// e.g. http://localhost:4318; /v1/logs is added when it has no path.
+	HTTP HTTPConfig
+
+	// Batch configures how entries are grouped into requests
+	Batch BatchConfig
+
+	// Encoding defaults to OTLPProtobuf
+	Encoding OTLPEncoding
+
+	// ServiceName is the service.name resource attribute; defaults to
+	// unknown_service:<executable> as in the OpenTelemetry SDKs
+	ServiceName string
+
+	// ResourceAttributes describe the process, e.g. service.version or
+	// deployment.environment
+	ResourceAttributes map[string]string
+}
+
+// OTLPDriver exports entries as OpenTelemetry log records over OTLP/HTTP.
+// Attributes become record attributes and the attached error and caller use
+// the exception.* and code.* semantic conventions. A transaction ID in the
+// W3C traceparent format, or a bare 32 hex digit trace ID, sets the trace
+// context of the record; other transaction IDs are kept as the
+// transaction.id attribute.
+type OTLPDriver struct {
+	config   OTLPConfig
+	filter   *core.LevelFilter
+	sender   *httpSender
+	batcher  *batcher
+	resource []otlpAttribute
+}
+
+// NewOTLPDriver creates a new OTLP driver from a map of options
+func NewOTLPDriver(options map[string]interface{}) (core.Driver, error) {
+	filter, err := newLevelFilter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	config := OTLPConfig{}
+	if config.HTTP, err = newHTTPConfig(options); err != nil {
+		return nil, err
+	}
+	if config.Batch, err = newBatchConfig(options); err != nil {
+		return nil, err
+	}
+	if encoding, ok := options["encoding"].(string); ok {
+		config.Encoding = OTLPEncoding(encoding)
+	}
+	if service, ok := options["service_name"].(string); ok {
+		config.ServiceName = service
+	}
+	if config.ResourceAttributes, err = optparse.StringMap(options["resource_attributes"]); err != nil {
+		return nil, fmt.Errorf("invalid resource_attributes: %w", err)
+	}
+
+	driver, err := NewOTLPDriverWithConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	driver.filter = filter
+	return driver, nil
+}
+
+// NewOTLPDriverWithConfig creates an OTLP driver and starts its background
+// goroutine
+func NewOTLPDriverWithConfig(config OTLPConfig) (*OTLPDriver, error) {
+	switch config.Encoding {
+	case "":
+		config.Encoding = OTLPProtobuf
+	case OTLPProtobuf, OTLPJSON:
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", config.Encoding)
+	}
+
+	endpoint, err := url.Parse(config.HTTP.URL)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid url %q", config.HTTP.URL)
+	}
+	if endpoint.Path == "" || endpoint.Path == "/" {
+		endpoint.Path = otlpLogsPath
+	}
+	config.HTTP.URL = endpoint.String()
+
+	sender, err := newHTTPSender(config.HTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	// service.name from ServiceName wins over the attribute map
+	attributes := make(map[string]string, len(config.ResourceAttributes)+1)
+	for key, value := range config.ResourceAttributes {
+		attributes[key] = value
+	}
+	if config.ServiceName != "" {
+		attributes["service.name"] = config.ServiceName
+	}
+	if attributes["service.name"] == "" {
+		attributes["service.name"] = "unknown_service:" + filepath.Base(os.Args[0])
+	}
+
+	var resource []otlpAttribute
+	for _, key := range sortedKeys(attributes) {
+		resource = append(resource, otlpAttribute{key: key, value: attributes[key]})
+	}
+
+	driver := &OTLPDriver{
+		config:   config,
+		sender:   sender,
+		resource: resource,
+	}
+	driver.batcher = newBatcher(config.Batch, driver.send)
+
+	return driver, nil
+}
+
+// Log queues the entry for the next export
+func (d *OTLPDriver) Log(entry *core.LogEntry) error {
+	if !d.filter.Allows(entry.Level) {
+		return nil
+	}
+
+	return d.batcher.add(entry)
+}
+
+// Flush exports the queued entries and waits for the request to finish
+func (d *OTLPDriver) Flush() error {
+	return d.batcher.flush()
+}
+
+// Close exports the queued entries and stops the background goroutine
+func (d *OTLPDriver) Close() error {
+	return d.batcher.close()
+}
+
+// Name returns the driver name
+func (d *OTLPDriver) Name() string {
+	return OTLPDriverName
+}
+
+// Enabled reports whether the driver accepts entries at the level
+func (d *OTLPDriver) Enabled(level core.Level) bool {
+	return d.filter.Allows(level)
+}
+
+// otlpAttribute is a key with a string or int64 value
+type otlpAttribute struct {
+	key   string
+	value interface{}
+}
+
+// otlpRecord is a LogRecord ready to be encoded
+type otlpRecord struct {
+	time           uint64
+	observed       uint64
+	severityNumber int
+	severityText   string
+	body           string
+	attributes     []otlpAttribute
+	traceID        []byte
+	spanID         []byte
+	flags          uint32
+}
+
+// send encodes and exports one batch
+func (d *OTLPDriver) send(batch []*core.LogEntry) error {
+	observed := uint64(time.Now().UnixNano())
+	records := make([]otlpRecord, len(batch))
+	for i, entry := range batch {
+		records[i] = newOTLPRecord(entry, observed)
+	}
+
+	var body []byte
+	var contentType string
+	if d.config.Encoding == OTLPJSON {
+		var err error
+		if body, err = encodeOTLPJSON(d.resource, records); err != nil {
+			return err
+		}
+		contentType = "application/json"
+	} else {
+		body = encodeOTLPProtobuf(d.resource, records)
+		contentType = "application/x-protobuf"
+	}
+
+	response, err := d.sender.send(body, contentType)
+	if err != nil {
+		return fmt.Errorf("failed to export %d entries: %w", len(batch), err)
+	}
+
+	rejected, message, err := d.partialSuccess(response)
+	if err != nil {
+		return fmt.Errorf("invalid export response: %w", err)
+	}
+	if rejected > 0 {
+		return fmt.Errorf("collector rejected %d of %d entries: %s", rejected, len(batch), message)
+	}
+
+	return nil
+}
+
+// partialSuccess reads the number of rejected records from an export
+// response, which is empty when everything was accepted
+func (d *OTLPDriver) partialSuccess(response []byte) (int64, string, error) {
+	if len(response) == 0 {
+		return 0, "", nil
+	}
+
+	if d.config.Encoding == OTLPJSON {
+		var decoded struct {
+			PartialSuccess struct {
+				RejectedLogRecords json.Number `json:"rejectedLogRecords"`
+				ErrorMessage       string      `json:"errorMessage"`
+			} `json:"partialSuccess"`
+		}
+		if err := json.Unmarshal(response, &decoded); err != nil {
+			return 0, "", err
+		}
+		rejected, _ := decoded.PartialSuccess.RejectedLogRecords.Int64()
+		return rejected, decoded.PartialSuccess.ErrorMessage, nil
+	}
+
+	// ExportLogsServiceResponse { ExportLogsPartialSuccess partial_success = 1; }
+	// ExportLogsPartialSuccess { int64 rejected_log_records = 1; string error_message = 2; }
+	var rejected int64
+	var message string
+	err := protoScan(response, func(field int, _ uint64, data []byte) error {
+		if field != 1 {
+			return nil
+		}
+		return protoScan(data, func(field int, value uint64, data []byte) error {
+			switch field {
+			case 1:
+				rejected = int64(value)
+			case 2:
+				message = string(data)
+			}
+			return nil
+		})
+	})
+
+	return rejected, message, err
+}
+
+// newOTLPRecord converts an entry into a log record
+func newOTLPRecord(entry *core.LogEntry, observed uint64) otlpRecord {
+	record := otlpRecord{
+		observed:       observed,
+		severityNumber: otlpSeverity(entry.Level),
+		severityText:   entry.Level.String(),
+		body:           entry.Message,
+	}
+	if !entry.Timestamp.IsZero() {
+		record.time = uint64(entry.Timestamp.UnixNano())
+	}
+
+	for _, key := range sortedKeys(entry.Attrs) {
+		record.attributes = append(record.attributes, otlpAttribute{key: key, value: entry.Attrs[key]})
+	}
+
+	if entry.TransactionID != "" {
+		traceID, spanID, flags, ok := parseTraceContext(entry.TransactionID)
+		if ok {
+			record.traceID = traceID
+			record.spanID = spanID
+			record.flags = uint32(flags)
+		} else {
+			record.attributes = append(record.attributes, otlpAttribute{key: "transaction.id", value: entry.TransactionID})
+		}
+	}
+
+	if entry.Caller != nil {
+		record.attributes = append(record.attributes,
+			otlpAttribute{key: "code.filepath", value: entry.Caller.File},
+			otlpAttribute{key: "code.lineno", value: int64(entry.Caller.Line)},
+			otlpAttribute{key: "code.function", value: entry.Caller.Function},
+		)
+	}
+
+	stack := entry.Stack
+	if entry.Error != nil {
+		record.attributes = append(record.attributes, otlpAttribute{key: "exception.message", value: entry.Error.Message})
+		if len(entry.Error.Chain) > 0 {
+			record.attributes = append(record.attributes, otlpAttribute{key: "exception.type", value: entry.Error.Chain[0].Type})
+		}
+		if len(entry.Error.Stack) > 0 {
+			stack = entry.Error.Stack
+		}
+	}
+	if len(stack) > 0 {
+		record.attributes = append(record.attributes, otlpAttribute{key: "exception.stacktrace", value: strings.Join(formatFrames(stack), "\n")})
+	}
+
+	return record
+}
+
+// otlpSeverity maps a level onto the OpenTelemetry severity numbers: 1-4
+// TRACE, 5-8 DEBUG, 9-12 INFO, 13-16 WARN, 17-20 ERROR and 21-24 FATAL.
+// Notice, Critical and Panic use the second step of their range, and custom
+// levels fall into the range of the level below them.
+func otlpSeverity(level core.Level) int {
+	switch {
+	case level < core.Debug:
+		return 1
+	case level < core.Info:
+		return 5
+	case level < core.Notice:
+		return 9
+	case level < core.Warning:
+		return 10
+	case level < core.Error:
+		return 13
+	case level < core.Critical:
+		return 17
+	case level < core.Fatal:
+		return 18
+	case level < core.Panic:
+		return 21
+	default:
+		return 22
+	}
+}
+
+// parseTraceContext extracts the trace context from a W3C traceparent
+// ("00-<trace-id>-<span-id>-<flags>") or a bare 32 hex digit trace ID. All
+// zero IDs are invalid.
+func parseTraceContext(id string) (traceID, spanID []byte, flags byte, ok bool) {
+	validID := func(s string, size int) ([]byte, bool) {
+		decoded, err := hex.DecodeString(s)
+		if err != nil || len(decoded) != size || strings.Trim(s, "0") == "" || strings.ToLower(s) != s {
+			return nil, false
+		}
+		return decoded, true
+	}
+
+	if len(id) == 32 {
+		traceID, ok = validID(id, 16)
+		return traceID, nil, 0, ok
+	}
+
+	parts := strings.Split(id, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return nil, nil, 0, false
+	}
+	if _, err := hex.DecodeString(parts[0]); err != nil {
+		return nil, nil, 0, false
+	}
+	if traceID, ok = validID(parts[1], 16); !ok {
+		return nil, nil, 0, false
+	}
+	if spanID, ok = validID(parts[2], 8); !ok {
+		return nil, nil, 0, false
+	}
+	flagBytes, err := hex.DecodeString(parts[3])
+	if err != nil || len(flagBytes) != 1 {
+		return nil, nil, 0, false
+	}
+
+	return traceID, spanID, flagBytes[0], true
+}
+
+// encodeOTLPProtobuf encodes the ExportLogsServiceRequest message:
+//
+//	ExportLogsServiceRequest { repeated ResourceLogs resource_logs = 1; }
+//	ResourceLogs { Resource resource = 1; repeated ScopeLogs scope_logs = 2; }
+//	Resource     { repeated KeyValue attributes = 1; }
+//	ScopeLogs    { InstrumentationScope scope = 1; repeated LogRecord log_records = 2; }
+//	LogRecord    { fixed64 time_unix_nano = 1; SeverityNumber severity_number = 2;
+//	               string severity_text = 3; AnyValue body = 5;
+//	               repeated KeyValue attributes = 6; fixed32 flags = 8;
+//	               bytes trace_id = 9; bytes span_id = 10;
+//	               fixed64 observed_time_unix_nano = 11; }
+func encodeOTLPProtobuf(resource []otlpAttribute, records []otlpRecord) []byte {
+	var request protoBuffer
+	request.message(1, func(rl *protoBuffer) {
+		rl.message(1, func(r *protoBuffer) {
+			for _, attribute := range resource {
+				protoKeyValue(r, 1, attribute)
+			}
+		})
+		rl.message(2, func(sl *protoBuffer) {
+			sl.message(1, func(s *protoBuffer) {
+				s.string(1, otlpScopeName)
+			})
+			for _, record := range records {
+				record := record
+				sl.message(2, func(lr *protoBuffer) {
+					lr.fixed64(1, record.time)
+					lr.uint(2, uint64(record.severityNumber))
+					lr.string(3, record.severityText)
+					lr.message(5, func(v *protoBuffer) {
+						v.string(1, record.body)
+					})
+					for _, attribute := range record.attributes {
+						protoKeyValue(lr, 6, attribute)
+					}
+					lr.fixed32(8, record.flags)
+					lr.bytes(9, record.traceID)
+					lr.bytes(10, record.spanID)
+					lr.fixed64(11, record.observed)
+				})
+			}
+		})
+	})
+
+	return request
+}
+
+// protoKeyValue appends a KeyValue { string key = 1; AnyValue value = 2; }
+// with an AnyValue { string string_value = 1; int64 int_value = 3; }
+func protoKeyValue(b *protoBuffer, field int, attribute otlpAttribute) {
+	b.message(field, func(kv *protoBuffer) {
+		kv.string(1, attribute.key)
+		kv.message(2, func(v *protoBuffer) {
+			switch value := attribute.value.(type) {
+			case int64:
+				// Written even when zero so the value is not empty
+				v.tag(3, protoVarint)
+				*v = appendUvarint(*v, uint64(value))
+			case string:
+				v.string(1, value)
+			}
+		})
+	})
+}
+
+// encodeOTLPJSON encodes the request in the OTLP JSON mapping: camelCase
+// field names, 64-bit integers as strings and IDs as hex
+func encodeOTLPJSON(resource []otlpAttribute, records []otlpRecord) ([]byte, error) {
+	logRecords := make([]map[string]interface{}, len(records))
+	for i, record := range records {
+		logRecord := map[string]interface{}{
+			"timeUnixNano":         strconv.FormatUint(record.time, 10),
+			"observedTimeUnixNano": strconv.FormatUint(record.observed, 10),
+			"severityNumber":       record.severityNumber,
+			"severityText":         record.severityText,
+			"body":                 map[string]string{"stringValue": record.body},
+			"attributes":           jsonKeyValues(record.attributes),
+		}
+		if record.traceID != nil {
+			logRecord["traceId"] = hex.EncodeToString(record.traceID)
+			logRecord["flags"] = record.flags
+		}
+		if record.spanID != nil {
+			logRecord["spanId"] = hex.EncodeToString(record.spanID)
+		}
+		logRecords[i] = logRecord
+	}
+
+	request := map[string]interface{}{
+		"resourceLogs": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{"attributes": jsonKeyValues(resource)},
+				"scopeLogs": []interface{}{
+					map[string]interface{}{
+						"scope":      map[string]string{"name": otlpScopeName},
+						"logRecords": logRecords,
+					},
+				},
+			},
+		},
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode export request: %w", err)
+	}
+	return body, nil
+}
+
+// jsonKeyValues converts attributes to the JSON form of KeyValue
+func jsonKeyValues(attributes []otlpAttribute) []interface{} {
+	result := make([]interface{}, len(attributes))
+	for i, attribute := range attributes {
+		var value interface{}
+		switch v := attribute.value.(type) {
+		case int64:
+			value = map[string]string{"intValue": strconv.FormatInt(v, 10)}
+		default:
+			value = map[string]interface{}{"stringValue": v}
+		}
+		result[i] = map[string]interface{}{"key": attribute.key, "value": value}
+	}
+
+	return result
+}
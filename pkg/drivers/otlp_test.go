@@ -0,0 +1,363 @@
+package drivers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// otlpExportedRecord is a log record decoded from a protobuf export request
+type otlpExportedRecord struct {
+	time           uint64
+	severityNumber uint64
+	severityText   string
+	body           string
+	attributes     map[string]interface{}
+	traceID        string
+	spanID         string
+	flags          uint64
+}
+
+// decodeOTLPKeyValues decodes repeated KeyValue fields
+func decodeOTLPKeyValues(t *testing.T, fields []protoField) map[string]interface{} {
+	t.Helper()
+
+	result := make(map[string]interface{})
+	for _, field := range fields {
+		kv := parseProto(t, field.bytes)
+		key := string(protoGet(kv, 1)[0].bytes)
+		value := parseProto(t, protoGet(kv, 2)[0].bytes)
+		if s := protoGet(value, 1); len(s) > 0 {
+			result[key] = string(s[0].bytes)
+		} else if i := protoGet(value, 3); len(i) > 0 {
+			result[key] = int64(i[0].varint)
+		}
+	}
+
+	return result
+}
+
+// decodeOTLPProtobuf decodes the resource attributes, scope name and records
+// of an export request
+func decodeOTLPProtobuf(t *testing.T, body []byte) (map[string]interface{}, string, []otlpExportedRecord) {
+	t.Helper()
+
+	resourceLogs := parseProto(t, protoGet(parseProto(t, body), 1)[0].bytes)
+	resource := decodeOTLPKeyValues(t, protoGet(parseProto(t, protoGet(resourceLogs, 1)[0].bytes), 1))
+
+	scopeLogs := parseProto(t, protoGet(resourceLogs, 2)[0].bytes)
+	scope := string(protoGet(parseProto(t, protoGet(scopeLogs, 1)[0].bytes), 1)[0].bytes)
+
+	var records []otlpExportedRecord
+	for _, field := range protoGet(scopeLogs, 2) {
+		fields := parseProto(t, field.bytes)
+		record := otlpExportedRecord{
+			time:           protoGet(fields, 1)[0].varint,
+			severityNumber: protoGet(fields, 2)[0].varint,
+			severityText:   string(protoGet(fields, 3)[0].bytes),
+			body:           string(protoGet(parseProto(t, protoGet(fields, 5)[0].bytes), 1)[0].bytes),
+			attributes:     decodeOTLPKeyValues(t, protoGet(fields, 6)),
+		}
+		if flags := protoGet(fields, 8); len(flags) > 0 {
+			record.flags = flags[0].varint
+		}
+		if traceID := protoGet(fields, 9); len(traceID) > 0 {
+			record.traceID = hex.EncodeToString(traceID[0].bytes)
+		}
+		if spanID := protoGet(fields, 10); len(spanID) > 0 {
+			record.spanID = hex.EncodeToString(spanID[0].bytes)
+		}
+		records = append(records, record)
+	}
+
+	return resource, scope, records
+}
+
+func newTestOTLPDriver(t *testing.T, options map[string]interface{}) *OTLPDriver {
+	t.Helper()
+
+	options["initial_backoff"] = "1ms"
+	options["linger"] = "1h"
+	driver, err := NewOTLPDriver(options)
+	if err != nil {
+		t.Fatalf("NewOTLPDriver() error = %v", err)
+	}
+	t.Cleanup(func() { driver.Close() })
+
+	return driver.(*OTLPDriver)
+}
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestOTLPDriverProtobuf(t *testing.T) {
+	server := newHTTPServer(t)
+	driver := newTestOTLPDriver(t, map[string]interface{}{
+		"url":                 server.URL,
+		"service_name":        "billing",
+		"resource_attributes": map[string]interface{}{"service.version": "1.2.0"},
+	})
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	driver.Log(&core.LogEntry{
+		Timestamp:     at,
+		Level:         core.Error,
+		Message:       "charge failed",
+		TransactionID: testTraceparent,
+		Attrs:         core.Attributes{"user.id": "42"},
+		Caller:        &core.Frame{Function: "main.charge", File: "/app/main.go", Line: 12},
+		Error: &core.ErrorInfo{
+			Message: "card declined",
+			Chain:   []core.ErrorCause{{Type: "*errors.errorString", Message: "card declined"}},
+		},
+	})
+	driver.Log(&core.LogEntry{Timestamp: at, Level: core.Notice, Message: "retrying", TransactionID: "request-7"})
+
+	if err := driver.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	requests := server.received()
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	if requests[0].path != "/v1/logs" {
+		t.Errorf("path = %q, want /v1/logs", requests[0].path)
+	}
+	if got := requests[0].header.Get("Content-Type"); got != "application/x-protobuf" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	resource, scope, records := decodeOTLPProtobuf(t, requests[0].body)
+	if resource["service.name"] != "billing" || resource["service.version"] != "1.2.0" {
+		t.Errorf("resource = %v", resource)
+	}
+	if scope != otlpScopeName {
+		t.Errorf("scope = %q", scope)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+
+	first := records[0]
+	if first.time != uint64(at.UnixNano()) || first.severityNumber != 17 || first.severityText != "ERROR" || first.body != "charge failed" {
+		t.Errorf("record = %+v", first)
+	}
+	if first.traceID != "4bf92f3577b34da6a3ce929d0e0e4736" || first.spanID != "00f067aa0ba902b7" || first.flags != 1 {
+		t.Errorf("trace context = %q %q %d", first.traceID, first.spanID, first.flags)
+	}
+	wantAttrs := map[string]interface{}{
+		"user.id":           "42",
+		"code.filepath":     "/app/main.go",
+		"code.lineno":       int64(12),
+		"code.function":     "main.charge",
+		"exception.message": "card declined",
+		"exception.type":    "*errors.errorString",
+	}
+	for key, want := range wantAttrs {
+		if first.attributes[key] != want {
+			t.Errorf("attribute %s = %v, want %v", key, first.attributes[key], want)
+		}
+	}
+
+	second := records[1]
+	if second.severityNumber != 10 || second.traceID != "" || second.attributes["transaction.id"] != "request-7" {
+		t.Errorf("record = %+v", second)
+	}
+}
+
+func TestOTLPDriverJSON(t *testing.T) {
+	server := newHTTPServer(t)
+	driver := newTestOTLPDriver(t, map[string]interface{}{
+		"url":          server.URL + "/custom/logs",
+		"encoding":     "json",
+		"service_name": "billing",
+	})
+
+	driver.Log(&core.LogEntry{
+		Timestamp:     time.Unix(1700000000, 5),
+		Level:         core.Warning,
+		Message:       "slow",
+		TransactionID: "4bf92f3577b34da6a3ce929d0e0e4736",
+	})
+	if err := driver.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	requests := server.received()
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	if requests[0].path != "/custom/logs" {
+		t.Errorf("path = %q, want the configured path", requests[0].path)
+	}
+
+	var request struct {
+		ResourceLogs []struct {
+			Resource struct {
+				Attributes []struct {
+					Key   string            `json:"key"`
+					Value map[string]string `json:"value"`
+				} `json:"attributes"`
+			} `json:"resource"`
+			ScopeLogs []struct {
+				LogRecords []map[string]interface{} `json:"logRecords"`
+			} `json:"scopeLogs"`
+		} `json:"resourceLogs"`
+	}
+	if err := json.Unmarshal(requests[0].body, &request); err != nil {
+		t.Fatalf("Invalid export request %q: %v", requests[0].body, err)
+	}
+
+	attributes := request.ResourceLogs[0].Resource.Attributes
+	if len(attributes) != 1 || attributes[0].Key != "service.name" || attributes[0].Value["stringValue"] != "billing" {
+		t.Errorf("resource attributes = %+v", attributes)
+	}
+
+	record := request.ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+	if record["timeUnixNano"] != "1700000000000000005" || record["severityNumber"] != float64(13) || record["severityText"] != "WARNING" {
+		t.Errorf("record = %v", record)
+	}
+	if record["traceId"] != "4bf92f3577b34da6a3ce929d0e0e4736" || record["spanId"] != nil {
+		t.Errorf("trace context = %v %v", record["traceId"], record["spanId"])
+	}
+	if body, _ := record["body"].(map[string]interface{}); body["stringValue"] != "slow" {
+		t.Errorf("body = %v", record["body"])
+	}
+}
+
+func TestOTLPDriverPartialSuccess(t *testing.T) {
+	var partial protoBuffer
+	partial.message(1, func(m *protoBuffer) {
+		m.int(1, 1)
+		m.string(2, "record too large")
+	})
+
+	tests := []struct {
+		encoding string
+		response []byte
+	}{
+		{encoding: "protobuf", response: partial},
+		{encoding: "json", response: []byte(`{"partialSuccess":{"rejectedLogRecords":"1","errorMessage":"record too large"}}`)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.encoding, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write(test.response)
+			}))
+			defer server.Close()
+
+			driver := newTestOTLPDriver(t, map[string]interface{}{"url": server.URL, "encoding": test.encoding})
+			driver.Log(&core.LogEntry{Level: core.Info, Message: "a"})
+			driver.Log(&core.LogEntry{Level: core.Info, Message: "b"})
+
+			err := driver.Flush()
+			if err == nil || !strings.Contains(err.Error(), "rejected 1 of 2 entries: record too large") {
+				t.Errorf("Flush() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestOTLPDriverRetries(t *testing.T) {
+	server := newHTTPServer(t, 503, 429)
+	driver := newTestOTLPDriver(t, map[string]interface{}{"url": server.URL})
+
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "retry"})
+	if err := driver.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got := len(server.received()); got != 3 {
+		t.Errorf("got %d requests, want 3", got)
+	}
+}
+
+func TestOTLPDriverDefaultServiceName(t *testing.T) {
+	driver := newTestOTLPDriver(t, map[string]interface{}{"url": "http://localhost:4318"})
+
+	if len(driver.resource) != 1 || !strings.HasPrefix(driver.resource[0].value.(string), "unknown_service:") {
+		t.Errorf("resource = %+v", driver.resource)
+	}
+}
+
+func TestOTLPSeverity(t *testing.T) {
+	tests := map[core.Level]int{
+		core.Trace:     1,
+		core.Debug:     5,
+		core.Info:      9,
+		core.Notice:    10,
+		core.Warning:   13,
+		core.Error:     17,
+		core.Critical:  18,
+		core.Fatal:     21,
+		core.Panic:     22,
+		core.Level(45): 13,
+	}
+
+	for level, want := range tests {
+		if got := otlpSeverity(level); got != want {
+			t.Errorf("otlpSeverity(%d) = %d, want %d", level, got, want)
+		}
+	}
+}
+
+func TestParseTraceContext(t *testing.T) {
+	tests := []struct {
+		id      string
+		traceID string
+		spanID  string
+		flags   byte
+		ok      bool
+	}{
+		{id: testTraceparent, traceID: "4bf92f3577b34da6a3ce929d0e0e4736", spanID: "00f067aa0ba902b7", flags: 1, ok: true},
+		{id: "4bf92f3577b34da6a3ce929d0e0e4736", traceID: "4bf92f3577b34da6a3ce929d0e0e4736", ok: true},
+		{id: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future", traceID: "4bf92f3577b34da6a3ce929d0e0e4736", spanID: "00f067aa0ba902b7", ok: true},
+		{id: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{id: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+		{id: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"},
+		{id: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{id: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"},
+		{id: "request-123"},
+	}
+
+	for _, test := range tests {
+		traceID, spanID, flags, ok := parseTraceContext(test.id)
+		if ok != test.ok {
+			t.Errorf("parseTraceContext(%q) ok = %v, want %v", test.id, ok, test.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if hex.EncodeToString(traceID) != test.traceID || hex.EncodeToString(spanID) != test.spanID || flags != test.flags {
+			t.Errorf("parseTraceContext(%q) = %x, %x, %d", test.id, traceID, spanID, flags)
+		}
+	}
+}
+
+func TestNewOTLPDriverInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+	}{
+		{name: "missing url", options: map[string]interface{}{}},
+		{name: "url", options: map[string]interface{}{"url": "localhost:4318"}},
+		{name: "encoding", options: map[string]interface{}{"url": "http://localhost:4318", "encoding": "grpc"}},
+		{name: "resource_attributes", options: map[string]interface{}{"url": "http://localhost:4318", "resource_attributes": []interface{}{"a"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if driver, err := NewOTLPDriver(test.options); err == nil {
+				driver.Close()
+				t.Error("NewOTLPDriver() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package drivers
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Protocol buffers wire types
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+// protoBuffer appends fields in the protocol buffers wire format. It covers
+// the few messages the network drivers send, without generated code.
+type protoBuffer []byte
+
+// tag appends a field number and wire type
+func (b *protoBuffer) tag(field int, wireType int) {
+	*b = appendUvarint(*b, uint64(field)<<3|uint64(wireType))
+}
+
+// uint appends a varint field, omitting the default zero value
+func (b *protoBuffer) uint(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	b.tag(field, protoVarint)
+	*b = appendUvarint(*b, v)
+}
+
+// int appends an int32 or int64 varint field. Negative values take ten bytes
+// as the format requires.
+func (b *protoBuffer) int(field int, v int64) {
+	b.uint(field, uint64(v))
+}
+
+// fixed64 appends a fixed64 field, omitting the default zero value
+func (b *protoBuffer) fixed64(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	b.tag(field, protoFixed64)
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	*b = append(*b, buf[:]...)
+}
+
+// fixed32 appends a fixed32 field, omitting the default zero value
+func (b *protoBuffer) fixed32(field int, v uint32) {
+	if v == 0 {
+		return
+	}
+	b.tag(field, protoFixed32)
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], v)
+	*b = append(*b, buf[:]...)
+}
+
+// bytes appends a length-delimited field, omitting empty values
+func (b *protoBuffer) bytes(field int, v []byte) {
+	if len(v) == 0 {
+		return
+	}
+	b.tag(field, protoBytes)
+	*b = appendUvarint(*b, uint64(len(v)))
+	*b = append(*b, v...)
+}
+
+// string appends a string field, omitting empty strings
+func (b *protoBuffer) string(field int, v string) {
+	if v == "" {
+		return
+	}
+	b.tag(field, protoBytes)
+	*b = appendUvarint(*b, uint64(len(v)))
+	*b = append(*b, v...)
+}
+
+// message appends an embedded message written by fn. The message is written
+// even when empty, since its presence can be significant.
+func (b *protoBuffer) message(field int, fn func(m *protoBuffer)) {
+	var m protoBuffer
+	fn(&m)
+
+	b.tag(field, protoBytes)
+	*b = appendUvarint(*b, uint64(len(m)))
+	*b = append(*b, m...)
+}
+
+// protoScan calls fn for each field of a message. Varint and fixed-size
+// values are passed as value, length-delimited ones as data.
+func protoScan(message []byte, fn func(field int, value uint64, data []byte) error) error {
+	for len(message) > 0 {
+		key, n := binary.Uvarint(message)
+		if n <= 0 {
+			return fmt.Errorf("invalid field key")
+		}
+		message = message[n:]
+
+		var value uint64
+		var data []byte
+		switch key & 7 {
+		case protoVarint:
+			if value, n = binary.Uvarint(message); n <= 0 {
+				return fmt.Errorf("invalid varint")
+			}
+			message = message[n:]
+		case protoFixed64:
+			if len(message) < 8 {
+				return fmt.Errorf("truncated fixed64")
+			}
+			value = binary.LittleEndian.Uint64(message)
+			message = message[8:]
+		case protoFixed32:
+			if len(message) < 4 {
+				return fmt.Errorf("truncated fixed32")
+			}
+			value = uint64(binary.LittleEndian.Uint32(message))
+			message = message[4:]
+		case protoBytes:
+			length, n := binary.Uvarint(message)
+			if n <= 0 || length > uint64(len(message)-n) {
+				return fmt.Errorf("invalid length")
+			}
+			data = message[n : n+int(length)]
+			message = message[n+int(length):]
+		default:
+			return fmt.Errorf("unsupported wire type %d", key&7)
+		}
+
+		if err := fn(int(key>>3), value, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// appendUvarint appends v in the unsigned varint encoding
+func appendUvarint(buf []byte, v uint64) []byte {
+	for v >= 0x80 {
+		buf = append(buf, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(buf, byte(v))
+}
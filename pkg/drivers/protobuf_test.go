@@ -0,0 +1,89 @@
+package drivers
+
+import "testing"
+
+// protoField is a decoded protocol buffers field
+type protoField struct {
+	number int
+	varint uint64
+	bytes  []byte
+}
+
+// parseProto decodes the fields of a message, in order
+func parseProto(t *testing.T, data []byte) []protoField {
+	t.Helper()
+
+	var fields []protoField
+	err := protoScan(data, func(field int, value uint64, data []byte) error {
+		fields = append(fields, protoField{number: field, varint: value, bytes: data})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Invalid message %x: %v", data, err)
+	}
+
+	return fields
+}
+
+// protoGet returns the fields with the given number
+func protoGet(fields []protoField, number int) []protoField {
+	var found []protoField
+	for _, field := range fields {
+		if field.number == number {
+			found = append(found, field)
+		}
+	}
+	return found
+}
+
+func TestProtoBuffer(t *testing.T) {
+	var b protoBuffer
+	b.uint(1, 300)
+	b.uint(2, 0)
+	b.int(3, -1)
+	b.string(4, "hi")
+	b.string(5, "")
+	b.fixed64(6, 42)
+	b.message(7, func(m *protoBuffer) {
+		m.string(1, "nested")
+	})
+	b.message(8, func(m *protoBuffer) {})
+	b.fixed32(9, 7)
+
+	fields := parseProto(t, b)
+	if len(fields) != 7 {
+		t.Fatalf("got %d fields, want 7 (zero values omitted)", len(fields))
+	}
+	if fields[0].number != 1 || fields[0].varint != 300 {
+		t.Errorf("field 1 = %+v", fields[0])
+	}
+	if fields[1].number != 3 || int64(fields[1].varint) != -1 {
+		t.Errorf("field 3 = %+v", fields[1])
+	}
+	if fields[2].number != 4 || string(fields[2].bytes) != "hi" {
+		t.Errorf("field 4 = %+v", fields[2])
+	}
+	if fields[3].number != 6 || fields[3].varint != 42 {
+		t.Errorf("field 6 = %+v", fields[3])
+	}
+
+	nested := parseProto(t, fields[4].bytes)
+	if len(nested) != 1 || string(nested[0].bytes) != "nested" {
+		t.Errorf("nested message = %+v", nested)
+	}
+	if fields[5].number != 8 || len(fields[5].bytes) != 0 {
+		t.Errorf("empty message = %+v", fields[5])
+	}
+	if fields[6].number != 9 || fields[6].varint != 7 {
+		t.Errorf("field 9 = %+v", fields[6])
+	}
+}
+
+func TestProtoScanInvalid(t *testing.T) {
+	for _, data := range [][]byte{{0x0a, 0x05, 'a'}, {0x08}, {0x0b}, {0x0d, 1, 2}} {
+		err := protoScan(data, func(int, uint64, []byte) error { return nil })
+		if err == nil {
+			t.Errorf("protoScan(%x) error = nil", data)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package drivers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// routeCondition is a single comparison of a route expression, e.g.
+// attrs.component == "billing"
+type routeCondition struct {
+	field string
+	attr  string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+// routeExpr is a conjunction of conditions; an empty expression matches every entry
+type routeExpr []routeCondition
+
+// routeOperators are the supported comparison operators
+var routeOperators = []string{"==", "!=", "matches", "contains"}
+
+// parseRouteExpr parses a route expression. Conditions compare a field with
+// a double-quoted string or, for matches, a /regular expression/, and are
+// joined with &&:
+//
+//	attrs.component == "billing" && message matches /payment (failed|declined)/
+//
+// Fields are message, transaction_id and attrs.<key>.
+func parseRouteExpr(expr string) (routeExpr, error) {
+	var conditions routeExpr
+	rest := strings.TrimSpace(expr)
+
+	for rest != "" {
+		condition, remainder, err := parseRouteCondition(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression %q: %w", expr, err)
+		}
+		conditions = append(conditions, condition)
+
+		rest = strings.TrimSpace(remainder)
+		if rest == "" {
+			break
+		}
+		if !strings.HasPrefix(rest, "&&") {
+			return nil, fmt.Errorf("invalid expression %q: expected && before %q", expr, rest)
+		}
+		rest = strings.TrimSpace(rest[2:])
+		if rest == "" {
+			return nil, fmt.Errorf("invalid expression %q: missing condition after &&", expr)
+		}
+	}
+
+	return conditions, nil
+}
+
+// parseRouteCondition parses one condition at the start of s and returns the
+// unparsed remainder
+func parseRouteCondition(s string) (routeCondition, string, error) {
+	var condition routeCondition
+
+	end := strings.IndexFunc(s, func(r rune) bool { return r == ' ' || r == '=' || r == '!' })
+	if end <= 0 {
+		return condition, "", fmt.Errorf("expected a field and an operator in %q", s)
+	}
+
+	field := s[:end]
+	switch {
+	case field == "message" || field == "transaction_id":
+		condition.field = field
+	case strings.HasPrefix(field, "attrs.") && len(field) > len("attrs."):
+		condition.field = "attrs"
+		condition.attr = field[len("attrs."):]
+	default:
+		return condition, "", fmt.Errorf("unknown field %q", field)
+	}
+
+	s = strings.TrimSpace(s[end:])
+	for _, op := range routeOperators {
+		if strings.HasPrefix(s, op) {
+			condition.op = op
+			s = strings.TrimSpace(s[len(op):])
+			break
+		}
+	}
+	if condition.op == "" {
+		return condition, "", fmt.Errorf("expected one of %s after %s", strings.Join(routeOperators, ", "), field)
+	}
+
+	if condition.op == "matches" {
+		pattern, rest, err := cutRegexLiteral(s)
+		if err != nil {
+			return condition, "", err
+		}
+		if condition.re, err = regexp.Compile(pattern); err != nil {
+			return condition, "", err
+		}
+		return condition, rest, nil
+	}
+
+	quoted, err := strconv.QuotedPrefix(s)
+	if err != nil || !strings.HasPrefix(quoted, `"`) {
+		return condition, "", fmt.Errorf("expected a double-quoted string after %s %s", field, condition.op)
+	}
+	condition.value, _ = strconv.Unquote(quoted)
+
+	return condition, s[len(quoted):], nil
+}
+
+// cutRegexLiteral splits a /regular expression/ off the start of s. A slash
+// inside the expression is written as \/.
+func cutRegexLiteral(s string) (string, string, error) {
+	if !strings.HasPrefix(s, "/") {
+		return "", "", fmt.Errorf("expected a /regular expression/ after matches")
+	}
+
+	// A backslash escapes the next character, which is skipped so that an
+	// escaped backslash before the closing slash does not escape it
+	var pattern strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			if s[i+1] != '/' {
+				pattern.WriteByte('\\')
+			}
+			pattern.WriteByte(s[i+1])
+			i++
+		case s[i] == '/':
+			return pattern.String(), s[i+1:], nil
+		default:
+			pattern.WriteByte(s[i])
+		}
+	}
+
+	return "", "", fmt.Errorf("unterminated regular expression %q", s)
+}
+
+// matches reports whether the entry satisfies every condition
+func (e routeExpr) matches(entry *core.LogEntry) bool {
+	for _, condition := range e {
+		if !condition.matches(entry) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matches reports whether the entry satisfies the condition. A missing
+// attribute only satisfies !=.
+func (c routeCondition) matches(entry *core.LogEntry) bool {
+	var value string
+	switch c.field {
+	case "message":
+		value = entry.Message
+	case "transaction_id":
+		value = entry.TransactionID
+	case "attrs":
+		var ok bool
+		if value, ok = entry.Attrs[c.attr]; !ok {
+			return c.op == "!="
+		}
+	}
+
+	switch c.op {
+	case "==":
+		return value == c.value
+	case "!=":
+		return value != c.value
+	case "contains":
+		return strings.Contains(value, c.value)
+	case "matches":
+		return c.re.MatchString(value)
+	}
+
+	return false
+}
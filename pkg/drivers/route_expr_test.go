@@ -0,0 +1,100 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+func TestRouteExpr(t *testing.T) {
+	entry := &core.LogEntry{
+		Message:       "payment declined for order 42",
+		TransactionID: "tx-7",
+		Attrs:         core.Attributes{"component": "billing", "path": "/api/v1/pay"},
+	}
+
+	tests := []struct {
+		expr     string
+		expected bool
+	}{
+		{``, true},
+		{`attrs.component == "billing"`, true},
+		{`attrs.component=="billing"`, true},
+		{`attrs.component != "billing"`, false},
+		{`attrs.region != "eu"`, true},
+		{`attrs.region == ""`, false},
+		{`message matches /payment (failed|declined)/`, true},
+		{`message matches /^order/`, false},
+		{`attrs.path matches /^\/api\/v1\//`, true},
+		{`message contains "order 42"`, true},
+		{`transaction_id == "tx-7"`, true},
+		{`attrs.component == "billing" && message contains "declined"`, true},
+		{`attrs.component == "billing" && message contains "refund"`, false},
+		{`message contains "a && b" && attrs.component == "billing"`, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			expr, err := parseRouteExpr(test.expr)
+			if err != nil {
+				t.Fatalf("Failed to parse: %v", err)
+			}
+			if got := expr.matches(entry); got != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestRouteExprInvalid(t *testing.T) {
+	invalid := []string{
+		`component == "billing"`,
+		`attrs. == "billing"`,
+		`attrs.component = "billing"`,
+		`attrs.component == billing`,
+		`attrs.component == 'billing'`,
+		`message matches payment`,
+		`message matches /payment`,
+		`message matches /(/`,
+		`message contains "a" attrs.component == "b"`,
+		`message contains "a" &&`,
+	}
+
+	for _, expr := range invalid {
+		if _, err := parseRouteExpr(expr); err == nil {
+			t.Errorf("Expected an error for %s", expr)
+		}
+	}
+}
+
+func TestCutRegexLiteral(t *testing.T) {
+	tests := []struct {
+		input   string
+		pattern string
+		rest    string
+	}{
+		{`/payment/`, `payment`, ``},
+		{`/a\/b/ && x`, `a/b`, ` && x`},
+		{`/a\\/ && x`, `a\\`, ` && x`},
+		{`/a\\\/b/`, `a\\/b`, ``},
+		{`/\d+\./`, `\d+\.`, ``},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			pattern, rest, err := cutRegexLiteral(test.input)
+			if err != nil {
+				t.Fatalf("Failed to cut: %v", err)
+			}
+			if pattern != test.pattern || rest != test.rest {
+				t.Errorf("Expected %q and %q, got %q and %q", test.pattern, test.rest, pattern, rest)
+			}
+		})
+	}
+
+	for _, input := range []string{`/a\/`, `/a\`, `payment/`} {
+		if _, _, err := cutRegexLiteral(input); err == nil {
+			t.Errorf("Expected an error for %s", input)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package drivers
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// RouterDriverName is the name to use in configuration
+const RouterDriverName = "router"
+
+func init() {
+	Register(RouterDriverName, NewRouterDriver)
+}
+
+// RouterMode selects how many routes receive an entry
+type RouterMode string
+
+const (
+	// RouteFirst sends each entry to the first matching route only
+	RouteFirst RouterMode = "first"
+	// RouteAll sends each entry to every matching route, like a tee
+	RouteAll RouterMode = "all"
+)
+
+// Route sends the entries matching an expression to a driver
+type Route struct {
+	// When is the route expression, e.g. `attrs.component == "billing"`.
+	// An empty expression matches every entry.
+	When string
+
+	// Driver receives the matching entries
+	Driver core.Driver
+}
+
+// RouterDriver splits entries between drivers based on their attributes,
+// message or transaction ID. Entries that match no route go to the default
+// driver, if there is one.
+type RouterDriver struct {
+	routes        []compiledRoute
+	defaultDriver core.Driver
+	mode          RouterMode
+	filter        *core.LevelFilter
+}
+
+// compiledRoute is a route with its parsed expression
+type compiledRoute struct {
+	expr   routeExpr
+	driver core.Driver
+}
+
+// NewRouterDriver creates a new router driver from a map of options. The
+// "routes" option lists maps with a "when" expression and a nested "driver";
+// "default" describes the driver for unmatched entries.
+func NewRouterDriver(options map[string]interface{}) (core.Driver, error) {
+	filter, err := newLevelFilter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := RouteFirst
+	if value, ok := options["mode"].(string); ok {
+		mode = RouterMode(value)
+	}
+
+	specs, ok := options["routes"].([]interface{})
+	if !ok && options["routes"] != nil {
+		return nil, fmt.Errorf("invalid routes: expected a list, got %T", options["routes"])
+	}
+
+	var routes []Route
+	var drivers []core.Driver
+	fail := func(err error) (core.Driver, error) {
+		closeAll(drivers)
+		return nil, err
+	}
+
+	for i, spec := range specs {
+		fields, ok := spec.(map[string]interface{})
+		if !ok {
+			return fail(fmt.Errorf("route %d: expected a map, got %T", i, spec))
+		}
+
+		when, ok := fields["when"].(string)
+		if !ok && fields["when"] != nil {
+			return fail(fmt.Errorf("route %d: expected a string expression, got %T", i, fields["when"]))
+		}
+
+		driver, err := createChild(fields["driver"])
+		if err != nil {
+			return fail(fmt.Errorf("route %d: %w", i, err))
+		}
+		drivers = append(drivers, driver)
+		routes = append(routes, Route{When: when, Driver: driver})
+	}
+
+	var defaultDriver core.Driver
+	if spec, ok := options["default"]; ok {
+		if defaultDriver, err = createChild(spec); err != nil {
+			return fail(fmt.Errorf("invalid default: %w", err))
+		}
+		drivers = append(drivers, defaultDriver)
+	}
+
+	router, err := NewRouterDriverWithRoutes(mode, routes, defaultDriver)
+	if err != nil {
+		return fail(err)
+	}
+	router.filter = filter
+
+	return router, nil
+}
+
+// NewRouterDriverWithRoutes creates a router from routes and an optional
+// default driver for the entries no route matches
+func NewRouterDriverWithRoutes(mode RouterMode, routes []Route, defaultDriver core.Driver) (*RouterDriver, error) {
+	if mode != RouteFirst && mode != RouteAll {
+		return nil, fmt.Errorf("unknown mode: %s", mode)
+	}
+
+	if len(routes) == 0 && defaultDriver == nil {
+		return nil, fmt.Errorf("at least one route or a default driver is required")
+	}
+
+	router := &RouterDriver{
+		defaultDriver: defaultDriver,
+		mode:          mode,
+	}
+
+	for i, route := range routes {
+		if route.Driver == nil {
+			return nil, fmt.Errorf("route %d: driver is required", i)
+		}
+
+		expr, err := parseRouteExpr(route.When)
+		if err != nil {
+			return nil, fmt.Errorf("route %d: %w", i, err)
+		}
+		router.routes = append(router.routes, compiledRoute{expr: expr, driver: route.Driver})
+	}
+
+	return router, nil
+}
+
+// Log sends the entry to the matching routes, or to the default driver if
+// none matches
+func (d *RouterDriver) Log(entry *core.LogEntry) error {
+	if !d.filter.Allows(entry.Level) {
+		return nil
+	}
+
+	errs := &core.MultiError{}
+	matched := false
+
+	for i, route := range d.routes {
+		if !route.expr.matches(entry) {
+			continue
+		}
+
+		matched = true
+		if err := route.driver.Log(entry); err != nil {
+			errs.Errors = append(errs.Errors, &core.DriverError{
+				Index: i,
+				Name:  core.DriverName(route.driver),
+				Err:   err,
+			})
+		}
+
+		if d.mode == RouteFirst {
+			break
+		}
+	}
+
+	if !matched && d.defaultDriver != nil {
+		if err := d.defaultDriver.Log(entry); err != nil {
+			errs.Errors = append(errs.Errors, &core.DriverError{
+				Index: len(d.routes),
+				Name:  core.DriverName(d.defaultDriver),
+				Err:   err,
+			})
+		}
+	}
+
+	if len(errs.Errors) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+// Name returns the driver name used in configuration
+func (d *RouterDriver) Name() string {
+	return RouterDriverName
+}
+
+// Enabled reports whether the driver and any of its routes accept the level
+func (d *RouterDriver) Enabled(level core.Level) bool {
+	if !d.filter.Allows(level) {
+		return false
+	}
+
+	for _, driver := range d.drivers() {
+		if driverEnabled(driver, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Close closes the drivers of every route and the default driver. A driver
+// shared by several routes is closed once. Errors are indexed like the
+// routes, with the default driver last.
+func (d *RouterDriver) Close() error {
+	errs := &core.MultiError{}
+	closed := make(map[core.Driver]bool)
+
+	for i, driver := range d.drivers() {
+		// Drivers of uncomparable types cannot be shared by pointer identity
+		if reflect.TypeOf(driver).Comparable() {
+			if closed[driver] {
+				continue
+			}
+			closed[driver] = true
+		}
+
+		addChildError(errs, i, driver, driver.Close())
+	}
+
+	return errorOrNil(errs)
+}
+
+// drivers returns the route drivers followed by the default driver
+func (d *RouterDriver) drivers() []core.Driver {
+	drivers := make([]core.Driver, 0, len(d.routes)+1)
+	for _, route := range d.routes {
+		drivers = append(drivers, route.driver)
+	}
+	if d.defaultDriver != nil {
+		drivers = append(drivers, d.defaultDriver)
+	}
+
+	return drivers
+}
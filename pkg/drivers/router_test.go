@@ -0,0 +1,199 @@
+package drivers
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+func TestRouterDriver(t *testing.T) {
+	billing := &recordingDriver{}
+	alerts := &recordingDriver{}
+	fallback := &recordingDriver{}
+
+	entries := []*core.LogEntry{
+		{Level: core.Info, Message: "invoice sent", Attrs: core.Attributes{"component": "billing"}},
+		{Level: core.Error, Message: "payment failed", Attrs: core.Attributes{"component": "billing"}},
+		{Level: core.Error, Message: "payment gateway down"},
+		{Level: core.Info, Message: "user signed in"},
+	}
+
+	tests := []struct {
+		mode     RouterMode
+		billing  []string
+		alerts   []string
+		fallback []string
+	}{
+		{
+			mode:     RouteFirst,
+			billing:  []string{"invoice sent", "payment failed"},
+			alerts:   []string{"payment gateway down"},
+			fallback: []string{"user signed in"},
+		},
+		{
+			mode:     RouteAll,
+			billing:  []string{"invoice sent", "payment failed"},
+			alerts:   []string{"payment failed", "payment gateway down"},
+			fallback: []string{"user signed in"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.mode), func(t *testing.T) {
+			*billing, *alerts, *fallback = recordingDriver{}, recordingDriver{}, recordingDriver{}
+
+			router, err := NewRouterDriverWithRoutes(test.mode, []Route{
+				{When: `attrs.component == "billing"`, Driver: billing},
+				{When: `message matches /payment/`, Driver: alerts},
+			}, fallback)
+			if err != nil {
+				t.Fatalf("Failed to create router: %v", err)
+			}
+
+			for _, entry := range entries {
+				if err := router.Log(entry); err != nil {
+					t.Fatalf("Log failed: %v", err)
+				}
+			}
+
+			for name, check := range map[string]struct {
+				driver   *recordingDriver
+				expected []string
+			}{
+				"billing":  {billing, test.billing},
+				"alerts":   {alerts, test.alerts},
+				"fallback": {fallback, test.fallback},
+			} {
+				if got := check.driver.messages(); !reflect.DeepEqual(got, check.expected) {
+					t.Errorf("%s: expected %v, got %v", name, check.expected, got)
+				}
+			}
+
+			if err := router.Close(); err != nil {
+				t.Errorf("Close failed: %v", err)
+			}
+			if !billing.closed || !alerts.closed || !fallback.closed {
+				t.Error("Expected every driver to be closed")
+			}
+		})
+	}
+}
+
+func TestRouterDriverErrors(t *testing.T) {
+	errBilling := errors.New("billing sink down")
+	billing := &recordingDriver{logErr: errBilling, closeErr: errBilling}
+	fallback := &recordingDriver{}
+
+	router, err := NewRouterDriverWithRoutes(RouteAll, []Route{
+		{When: `attrs.component == "billing"`, Driver: billing},
+	}, fallback)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	err = router.Log(&core.LogEntry{Level: core.Info, Attrs: core.Attributes{"component": "billing"}})
+	if !errors.Is(err, errBilling) {
+		t.Errorf("Expected the route's error, got %v", err)
+	}
+
+	var multi *core.MultiError
+	if err := router.Close(); !errors.As(err, &multi) || multi.Errors[0].Index != 0 {
+		t.Errorf("Expected Close to report the billing route, got %v", err)
+	}
+
+	if _, err := NewRouterDriverWithRoutes("random", nil, fallback); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+	if _, err := NewRouterDriverWithRoutes(RouteFirst, nil, nil); err == nil {
+		t.Error("Expected an error without routes")
+	}
+	if _, err := NewRouterDriverWithRoutes(RouteFirst, []Route{{When: `level > 3`, Driver: fallback}}, nil); err == nil {
+		t.Error("Expected an error for an invalid expression")
+	}
+}
+
+func TestRouterDriverFromOptions(t *testing.T) {
+	dir := t.TempDir()
+	billingPath := filepath.Join(dir, "billing.log")
+	defaultPath := filepath.Join(dir, "app.log")
+
+	driver, err := Create(RouterDriverName, map[string]interface{}{
+		"routes": []interface{}{
+			map[string]interface{}{
+				"when": `attrs.component == "billing"`,
+				"driver": map[string]interface{}{
+					"type":    TextFileDriverName,
+					"options": map[string]interface{}{"file_path": billingPath},
+				},
+			},
+		},
+		"default": map[string]interface{}{
+			"type":      TextFileDriverName,
+			"min_level": "info",
+			"options":   map[string]interface{}{"file_path": defaultPath},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	logger := core.NewLogger(driver)
+	logger.Info("charged card", core.Attributes{"component": "billing"})
+	logger.Info("page rendered", core.Attributes{"component": "web"})
+	logger.Debug("cache miss")
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	for path, expected := range map[string]string{billingPath: "charged card", defaultPath: "page rendered"} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		if lines := strings.Split(strings.TrimSpace(string(content)), "\n"); len(lines) != 1 || !strings.Contains(lines[0], expected) {
+			t.Errorf("%s: expected one line with %q, got %q", filepath.Base(path), expected, content)
+		}
+	}
+
+	invalid := []map[string]interface{}{
+		{},
+		{"routes": "billing"},
+		{"routes": []interface{}{"billing"}},
+		{"routes": []interface{}{map[string]interface{}{"when": 42, "driver": map[string]interface{}{"type": ConsoleDriverName}}}},
+		{"routes": []interface{}{map[string]interface{}{"when": `message matches /(/`, "driver": map[string]interface{}{"type": ConsoleDriverName}}}},
+		{"default": map[string]interface{}{"type": "unknown"}},
+		{"mode": "some", "default": map[string]interface{}{"type": ConsoleDriverName}},
+	}
+	for _, options := range invalid {
+		if _, err := Create(RouterDriverName, options); err == nil {
+			t.Errorf("Expected an error for %v", options)
+		}
+	}
+}
+
+func TestRouterDriverClosesSharedDriverOnce(t *testing.T) {
+	shared := &recordingDriver{closeErr: errors.New("already closed")}
+	other := &recordingDriver{}
+
+	router, err := NewRouterDriverWithRoutes(RouteFirst, []Route{
+		{When: `attrs.component == "billing"`, Driver: shared},
+		{When: `attrs.component == "audit"`, Driver: other},
+		{When: `message matches /payment/`, Driver: shared},
+	}, shared)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	var multi *core.MultiError
+	if err := router.Close(); !errors.As(err, &multi) || len(multi.Errors) != 1 || multi.Errors[0].Index != 0 {
+		t.Errorf("Close() error = %v, want one failure of the first route", err)
+	}
+	if !other.closed {
+		t.Error("Route driver not closed")
+	}
+}
@@ -0,0 +1,304 @@
+package drivers
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+	"github.com/MaoDaGreith/logging/pkg/internal/optparse"
+)
+
+// SamplingDriverName is the name to use in configuration
+const SamplingDriverName = "sampler"
+
+func init() {
+	Register(SamplingDriverName, NewSamplingDriver)
+}
+
+// SamplingConfig configures a SamplingDriver
+type SamplingConfig struct {
+	// Initial is the number of entries per key passed through in each window;
+	// 0 passes only every Thereafter-th entry
+	Initial int
+
+	// Thereafter passes every Mth entry once Initial is exceeded; 0 drops them all
+	Thereafter int
+
+	// Window is the length of a sampling window and the summary interval
+	Window time.Duration
+
+	// MaxKeys bounds the number of (level, message) keys tracked at once
+	MaxKeys int
+}
+
+// Sampling defaults
+const (
+	defaultSamplingInitial    = 10
+	defaultSamplingThereafter = 100
+	defaultSamplingWindow     = time.Second
+	defaultSamplingMaxKeys    = 1000
+)
+
+// SamplingDriver wraps another driver and thins out repetitive entries. For
+// each (level, message) key it passes the first Initial entries of a window
+// and every Thereafter-th entry after that. Suppressed entries are reported
+// by a "suppressed K similar entries" summary at the end of the window.
+type SamplingDriver struct {
+	next     core.Driver
+	filter   *core.LevelFilter
+	config   SamplingConfig
+	mu       sync.Mutex
+	counters *list.List
+	keys     map[samplingKey]*list.Element
+	now      func() time.Time
+	stop     chan struct{}
+	done     chan struct{}
+	closed   bool
+}
+
+// samplingKey identifies entries considered similar
+type samplingKey struct {
+	level   core.Level
+	message string
+}
+
+// samplingCounter tracks one key in the current window
+type samplingCounter struct {
+	key         samplingKey
+	windowStart time.Time
+	count       int
+	suppressed  int
+}
+
+// NewSamplingDriver creates a new sampling driver from a map of options. The
+// wrapped driver is described by the "driver" option.
+func NewSamplingDriver(options map[string]interface{}) (core.Driver, error) {
+	filter, err := newLevelFilter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	config := SamplingConfig{}
+	if config.Initial, err = optparse.Int(options["initial"], defaultSamplingInitial); err != nil {
+		return nil, fmt.Errorf("invalid initial: %w", err)
+	}
+	if config.Initial < 0 {
+		return nil, fmt.Errorf("invalid initial: %d is negative", config.Initial)
+	}
+	if config.Thereafter, err = optparse.Int(options["thereafter"], defaultSamplingThereafter); err != nil {
+		return nil, fmt.Errorf("invalid thereafter: %w", err)
+	}
+	if config.Thereafter < 0 {
+		return nil, fmt.Errorf("invalid thereafter: %d is negative", config.Thereafter)
+	}
+	if config.Window, err = optparse.Duration(options["window"], defaultSamplingWindow); err != nil {
+		return nil, fmt.Errorf("invalid window: %w", err)
+	}
+	if config.MaxKeys, err = optparse.Int(options["max_keys"], defaultSamplingMaxKeys); err != nil {
+		return nil, fmt.Errorf("invalid max_keys: %w", err)
+	}
+
+	next, err := createChild(options["driver"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid driver: %w", err)
+	}
+
+	driver := NewSamplingDriverWithConfig(next, config)
+	driver.filter = filter
+	return driver, nil
+}
+
+// NewSamplingDriverWithConfig wraps next in a sampling driver. A zero Window
+// or MaxKeys is replaced by the default, and negative counts by 0.
+func NewSamplingDriverWithConfig(next core.Driver, config SamplingConfig) *SamplingDriver {
+	if config.Initial < 0 {
+		config.Initial = 0
+	}
+	if config.Thereafter < 0 {
+		config.Thereafter = 0
+	}
+	if config.Window <= 0 {
+		config.Window = defaultSamplingWindow
+	}
+	if config.MaxKeys <= 0 {
+		config.MaxKeys = defaultSamplingMaxKeys
+	}
+
+	driver := &SamplingDriver{
+		next:     next,
+		config:   config,
+		counters: list.New(),
+		keys:     make(map[samplingKey]*list.Element),
+		now:      time.Now,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	go driver.run()
+
+	return driver
+}
+
+// Log passes the entry to the wrapped driver unless it is sampled out
+func (d *SamplingDriver) Log(entry *core.LogEntry) error {
+	if !d.filter.Allows(entry.Level) {
+		return nil
+	}
+
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return fmt.Errorf("driver is closed")
+	}
+	pass, summaries := d.sample(entry)
+	d.mu.Unlock()
+
+	errs := &core.MultiError{}
+	for _, summary := range summaries {
+		addChildError(errs, 0, d.next, d.next.Log(summary))
+	}
+
+	if pass {
+		addChildError(errs, 0, d.next, d.next.Log(entry))
+	}
+
+	return errorOrNil(errs)
+}
+
+// sample updates the counter for the entry's key and reports whether the
+// entry passes. It also returns summaries for windows that ended and for
+// keys evicted from the cache. The caller must hold d.mu.
+func (d *SamplingDriver) sample(entry *core.LogEntry) (bool, []*core.LogEntry) {
+	var summaries []*core.LogEntry
+	now := d.now()
+	key := samplingKey{level: entry.Level, message: entry.Message}
+
+	var counter *samplingCounter
+	if element, ok := d.keys[key]; ok {
+		d.counters.MoveToFront(element)
+		counter = element.Value.(*samplingCounter)
+	} else {
+		if d.counters.Len() >= d.config.MaxKeys {
+			oldest := d.counters.Back()
+			evicted := oldest.Value.(*samplingCounter)
+			if evicted.suppressed > 0 {
+				summaries = append(summaries, d.summary(evicted, now))
+			}
+			d.counters.Remove(oldest)
+			delete(d.keys, evicted.key)
+		}
+		counter = &samplingCounter{key: key, windowStart: now}
+		d.keys[key] = d.counters.PushFront(counter)
+	}
+
+	if now.Sub(counter.windowStart) >= d.config.Window {
+		if counter.suppressed > 0 {
+			summaries = append(summaries, d.summary(counter, now))
+		}
+		counter.windowStart = now
+		counter.count = 0
+		counter.suppressed = 0
+	}
+
+	counter.count++
+	if counter.count <= d.config.Initial {
+		return true, summaries
+	}
+
+	if d.config.Thereafter > 0 && (counter.count-d.config.Initial)%d.config.Thereafter == 0 {
+		return true, summaries
+	}
+
+	counter.suppressed++
+	return false, summaries
+}
+
+// summary builds the entry reporting the suppressed entries of a counter
+// and resets its suppressed count
+func (d *SamplingDriver) summary(counter *samplingCounter, now time.Time) *core.LogEntry {
+	entry := &core.LogEntry{
+		Timestamp: now,
+		Level:     counter.key.level,
+		Message:   fmt.Sprintf("suppressed %d similar entries: %s", counter.suppressed, counter.key.message),
+		Attrs: core.Attributes{
+			"sampled_message": counter.key.message,
+			"suppressed":      strconv.Itoa(counter.suppressed),
+		},
+	}
+	counter.suppressed = 0
+
+	return entry
+}
+
+// flushSummaries ends the window of every key, emitting summaries for keys
+// with suppressed entries and recording failures in errs
+func (d *SamplingDriver) flushSummaries(errs *core.MultiError) {
+	d.mu.Lock()
+	now := d.now()
+	var summaries []*core.LogEntry
+	for element := d.counters.Front(); element != nil; element = element.Next() {
+		counter := element.Value.(*samplingCounter)
+		if counter.suppressed > 0 {
+			summaries = append(summaries, d.summary(counter, now))
+		}
+		counter.windowStart = now
+		counter.count = 0
+	}
+	d.mu.Unlock()
+
+	for _, summary := range summaries {
+		addChildError(errs, 0, d.next, d.next.Log(summary))
+	}
+}
+
+// run emits summaries once per window until the driver is closed
+func (d *SamplingDriver) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.config.Window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// There is no caller to report failed summaries to
+			d.flushSummaries(&core.MultiError{})
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+// Name returns the driver name used in configuration
+func (d *SamplingDriver) Name() string {
+	return SamplingDriverName
+}
+
+// Enabled reports whether the driver and the wrapped driver accept the level
+func (d *SamplingDriver) Enabled(level core.Level) bool {
+	return d.filter.Allows(level) && driverEnabled(d.next, level)
+}
+
+// Close emits the pending summaries and closes the wrapped driver. The
+// returned *core.MultiError lists every failure.
+func (d *SamplingDriver) Close() error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	d.mu.Unlock()
+
+	close(d.stop)
+	<-d.done
+
+	errs := &core.MultiError{}
+	d.flushSummaries(errs)
+	addChildError(errs, 0, d.next, d.next.Close())
+
+	return errorOrNil(errs)
+}
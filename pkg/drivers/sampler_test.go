@@ -0,0 +1,284 @@
+package drivers
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// recordingDriver is a thread-safe driver that records entries for the
+// wrapping driver tests
+type recordingDriver struct {
+	mu          sync.Mutex
+	entries     []*core.LogEntry
+	calls       int
+	logErr      error
+	closeErr    error
+	closed      bool
+	minLevel    core.Level
+	hasMinLevel bool
+}
+
+func (d *recordingDriver) Log(entry *core.LogEntry) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.calls++
+	if d.logErr != nil {
+		return d.logErr
+	}
+	d.entries = append(d.entries, entry)
+	return nil
+}
+
+func (d *recordingDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.closed = true
+	return d.closeErr
+}
+
+func (d *recordingDriver) Enabled(level core.Level) bool {
+	return !d.hasMinLevel || level >= d.minLevel
+}
+
+func (d *recordingDriver) messages() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	messages := make([]string, len(d.entries))
+	for i, entry := range d.entries {
+		messages[i] = entry.Message
+	}
+	return messages
+}
+
+func TestSamplingDriver(t *testing.T) {
+	next := &recordingDriver{}
+	driver := NewSamplingDriverWithConfig(next, SamplingConfig{Initial: 2, Thereafter: 3, Window: time.Hour})
+	defer driver.Close()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	driver.now = func() time.Time { return now }
+
+	for i := 0; i < 8; i++ {
+		driver.Log(&core.LogEntry{Level: core.Warning, Message: "disk almost full"})
+	}
+	driver.Log(&core.LogEntry{Level: core.Error, Message: "disk almost full"})
+
+	// Entries 1, 2, 5 and 8 pass; the Error entry is a different key
+	if got := len(next.messages()); got != 5 {
+		t.Fatalf("Expected 5 entries, got %d: %v", got, next.messages())
+	}
+
+	// The next window starts with a summary of the previous one
+	now = now.Add(time.Hour)
+	driver.Log(&core.LogEntry{Level: core.Warning, Message: "disk almost full"})
+
+	messages := next.messages()
+	if len(messages) != 7 {
+		t.Fatalf("Expected 7 entries, got %d: %v", len(messages), messages)
+	}
+	if messages[5] != "suppressed 4 similar entries: disk almost full" {
+		t.Errorf("Summary = %q", messages[5])
+	}
+	if next.entries[5].Level != core.Warning || next.entries[5].Attrs["suppressed"] != "4" {
+		t.Errorf("Summary entry = %+v", next.entries[5])
+	}
+}
+
+func TestSamplingDriverThereafterZero(t *testing.T) {
+	next := &recordingDriver{}
+	driver := NewSamplingDriverWithConfig(next, SamplingConfig{Initial: 1, Thereafter: 0, Window: time.Hour})
+
+	for i := 0; i < 5; i++ {
+		driver.Log(&core.LogEntry{Level: core.Info, Message: "tick"})
+	}
+
+	if err := driver.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	messages := next.messages()
+	want := []string{"tick", "suppressed 4 similar entries: tick"}
+	if len(messages) != len(want) || messages[0] != want[0] || messages[1] != want[1] {
+		t.Errorf("Messages = %v, want %v", messages, want)
+	}
+	if !next.closed {
+		t.Error("Wrapped driver not closed")
+	}
+	if err := driver.Log(&core.LogEntry{Message: "late"}); err == nil {
+		t.Error("Expected error when logging to closed driver")
+	}
+}
+
+func TestSamplingDriverPeriodicSummary(t *testing.T) {
+	next := &recordingDriver{}
+	driver := NewSamplingDriverWithConfig(next, SamplingConfig{Initial: 1, Thereafter: 0, Window: 20 * time.Millisecond})
+	defer driver.Close()
+
+	// A clock that stands still leaves the windows to the summary ticker
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	driver.mu.Lock()
+	driver.now = func() time.Time { return now }
+	driver.mu.Unlock()
+
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "tick"})
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "tick"})
+
+	waitFor(t, "the summary", func() bool { return len(next.messages()) >= 2 })
+
+	messages := next.messages()
+	if len(messages) != 2 || messages[1] != "suppressed 1 similar entries: tick" {
+		t.Errorf("Messages = %v, want periodic summary", messages)
+	}
+
+	// The summary starts a new window, so the next entry passes again
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "tick"})
+	if messages := next.messages(); len(messages) != 3 || messages[2] != "tick" {
+		t.Errorf("Messages = %v, want the entry after the summary", messages)
+	}
+}
+
+func TestSamplingDriverInitialZero(t *testing.T) {
+	driver, err := Create(SamplingDriverName, map[string]interface{}{
+		"initial": 0,
+		"driver":  map[string]interface{}{"type": "console"},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	defer driver.Close()
+
+	if initial := driver.(*SamplingDriver).config.Initial; initial != 0 {
+		t.Errorf("Initial = %d, want 0", initial)
+	}
+
+	next := &recordingDriver{}
+	sampler := NewSamplingDriverWithConfig(next, SamplingConfig{Initial: 0, Thereafter: 3, Window: time.Hour})
+	defer sampler.Close()
+
+	for i := 0; i < 7; i++ {
+		sampler.Log(&core.LogEntry{Level: core.Info, Message: "sampled"})
+	}
+
+	// Only the 3rd and 6th entries pass
+	if messages := next.messages(); len(messages) != 2 {
+		t.Errorf("Messages = %v, want every third entry", messages)
+	}
+}
+
+func TestSamplingDriverLRUEviction(t *testing.T) {
+	next := &recordingDriver{}
+	driver := NewSamplingDriverWithConfig(next, SamplingConfig{Initial: 1, Thereafter: 0, Window: time.Hour, MaxKeys: 2})
+	defer driver.Close()
+
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "a"})
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "a"})
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "b"})
+	driver.Log(&core.LogEntry{Level: core.Info, Message: "c"}) // evicts "a"
+
+	driver.mu.Lock()
+	tracked := len(driver.keys)
+	driver.mu.Unlock()
+	if tracked != 2 {
+		t.Errorf("Tracked keys = %d, want 2", tracked)
+	}
+
+	messages := next.messages()
+	want := []string{"a", "b", "suppressed 1 similar entries: a", "c"}
+	if len(messages) != len(want) {
+		t.Fatalf("Messages = %v, want %v", messages, want)
+	}
+	for i := range want {
+		if messages[i] != want[i] {
+			t.Errorf("Messages = %v, want %v", messages, want)
+			break
+		}
+	}
+}
+
+func TestSamplingDriverFromOptions(t *testing.T) {
+	driver, err := Create(SamplingDriverName, map[string]interface{}{
+		"initial":    5,
+		"thereafter": 10.0,
+		"window":     "2s",
+		"min_level":  "info",
+		"driver": map[string]interface{}{
+			"type":      "console",
+			"max_level": "error",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	defer driver.Close()
+
+	sampler := driver.(*SamplingDriver)
+	if sampler.config.Initial != 5 || sampler.config.Thereafter != 10 || sampler.config.Window != 2*time.Second {
+		t.Errorf("Config = %+v", sampler.config)
+	}
+	if sampler.Enabled(core.Debug) || !sampler.Enabled(core.Info) || sampler.Enabled(core.Critical) {
+		t.Error("Enabled() does not combine the sampler and wrapped driver filters")
+	}
+}
+
+func TestSamplingDriverInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+	}{
+		{"missing driver", map[string]interface{}{}},
+		{"unknown driver", map[string]interface{}{"driver": map[string]interface{}{"type": "nope"}}},
+		{"invalid window", map[string]interface{}{"window": "soon", "driver": map[string]interface{}{"type": "console"}}},
+		{"invalid initial", map[string]interface{}{"initial": "many", "driver": map[string]interface{}{"type": "console"}}},
+		{"negative initial", map[string]interface{}{"initial": -1, "driver": map[string]interface{}{"type": "console"}}},
+		{"negative thereafter", map[string]interface{}{"thereafter": -1, "driver": map[string]interface{}{"type": "console"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			driver, err := Create(SamplingDriverName, test.options)
+			if err == nil {
+				t.Error("Expected error but got none")
+			}
+			if driver != nil {
+				t.Error("Expected nil driver when error occurs")
+			}
+		})
+	}
+}
+
+func TestSamplingDriverPropagatesErrors(t *testing.T) {
+	next := &recordingDriver{logErr: errors.New("disk full")}
+	driver := NewSamplingDriverWithConfig(next, SamplingConfig{Initial: 1, Window: time.Hour})
+	defer driver.Close()
+
+	if err := driver.Log(&core.LogEntry{Message: "message"}); err == nil {
+		t.Error("Expected error from wrapped driver")
+	}
+}
+
+func TestSamplingDriverCollectsErrors(t *testing.T) {
+	next := &recordingDriver{}
+	driver := NewSamplingDriverWithConfig(next, SamplingConfig{Initial: 1, Thereafter: 100, Window: time.Hour})
+
+	driver.Log(&core.LogEntry{Message: "message"})
+	driver.Log(&core.LogEntry{Message: "message"})
+
+	// Both the summary of the suppressed entry and closing fail
+	next.logErr = errors.New("disk full")
+	next.closeErr = errors.New("close failed")
+
+	var multi *core.MultiError
+	if err := driver.Close(); !errors.As(err, &multi) || len(multi.Errors) != 2 {
+		t.Fatalf("Close() error = %v, want both failures", err)
+	}
+	if !errors.Is(multi, next.logErr) || !errors.Is(multi, next.closeErr) {
+		t.Errorf("Close() error = %v, want the log and close errors", multi)
+	}
+}
@@ -0,0 +1,80 @@
+package drivers
+
+import "encoding/binary"
+
+// snappyHashBits sizes the table of recent positions used to find matches
+const snappyHashBits = 14
+
+// snappyMaxOffset is the farthest back a two-byte offset copy can reach
+const snappyMaxOffset = 1<<16 - 1
+
+// snappyEncode compresses src in the Snappy block format, which Loki expects
+// for protobuf push requests. It is a plain greedy encoder: it finds fewer
+// matches than the reference implementation but produces valid blocks.
+func snappyEncode(src []byte) []byte {
+	dst := appendUvarint(make([]byte, 0, len(src)/2+16), uint64(len(src)))
+
+	var table [1 << snappyHashBits]int32
+	literal := 0
+	for i := 0; i+4 <= len(src); {
+		current := binary.LittleEndian.Uint32(src[i:])
+		hash := (current * 0x1e35a7bd) >> (32 - snappyHashBits)
+
+		// Positions are stored plus one so that zero means empty
+		candidate := int(table[hash]) - 1
+		table[hash] = int32(i + 1)
+
+		if candidate < 0 || i-candidate > snappyMaxOffset || binary.LittleEndian.Uint32(src[candidate:]) != current {
+			i++
+			continue
+		}
+
+		length := 4
+		for i+length < len(src) && src[candidate+length] == src[i+length] {
+			length++
+		}
+
+		dst = snappyLiteral(dst, src[literal:i])
+		dst = snappyCopy(dst, i-candidate, length)
+		i += length
+		literal = i
+	}
+
+	return snappyLiteral(dst, src[literal:])
+}
+
+// snappyLiteral appends a literal element
+func snappyLiteral(dst, literal []byte) []byte {
+	n := len(literal) - 1
+	switch {
+	case n < 0:
+		return dst
+	case n < 60:
+		dst = append(dst, byte(n)<<2)
+	case n < 1<<8:
+		dst = append(dst, 60<<2, byte(n))
+	case n < 1<<16:
+		dst = append(dst, 61<<2, byte(n), byte(n>>8))
+	case n < 1<<24:
+		dst = append(dst, 62<<2, byte(n), byte(n>>8), byte(n>>16))
+	default:
+		dst = append(dst, 63<<2, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	}
+
+	return append(dst, literal...)
+}
+
+// snappyCopy appends copy elements with two-byte offsets, each covering at
+// most 64 bytes
+func snappyCopy(dst []byte, offset, length int) []byte {
+	for length > 0 {
+		n := length
+		if n > 64 {
+			n = 64
+		}
+		dst = append(dst, byte(n-1)<<2|2, byte(offset), byte(offset>>8))
+		length -= n
+	}
+
+	return dst
+}
@@ -0,0 +1,101 @@
+package drivers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// snappyDecode decompresses a Snappy block
+func snappyDecode(src []byte) ([]byte, error) {
+	length, n := binary.Uvarint(src)
+	if n <= 0 {
+		return nil, errors.New("invalid length")
+	}
+	src = src[n:]
+
+	dst := make([]byte, 0, length)
+	for len(src) > 0 {
+		tag := src[0]
+		switch tag & 3 {
+		case 0:
+			size := int(tag >> 2)
+			src = src[1:]
+			if size >= 60 {
+				extra := size - 59
+				if len(src) < extra {
+					return nil, errors.New("truncated literal length")
+				}
+				size = 0
+				for i := extra - 1; i >= 0; i-- {
+					size = size<<8 | int(src[i])
+				}
+				src = src[extra:]
+			}
+			size++
+			if len(src) < size {
+				return nil, errors.New("truncated literal")
+			}
+			dst = append(dst, src[:size]...)
+			src = src[size:]
+		case 2:
+			if len(src) < 3 {
+				return nil, errors.New("truncated copy")
+			}
+			size := int(tag>>2) + 1
+			offset := int(src[1]) | int(src[2])<<8
+			src = src[3:]
+			if offset == 0 || offset > len(dst) {
+				return nil, errors.New("invalid offset")
+			}
+			for i := 0; i < size; i++ {
+				dst = append(dst, dst[len(dst)-offset])
+			}
+		default:
+			return nil, errors.New("unsupported element")
+		}
+	}
+
+	if uint64(len(dst)) != length {
+		return nil, errors.New("length mismatch")
+	}
+	return dst, nil
+}
+
+func TestSnappyEncodeRoundTrip(t *testing.T) {
+	random := make([]byte, 100000)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	tests := map[string][]byte{
+		"empty":      {},
+		"short":      []byte("abc"),
+		"repetitive": []byte(strings.Repeat(`{"level":"info","message":"request served"}`, 500)),
+		"runs":       bytes.Repeat([]byte{'x'}, 1000),
+		"random":     random,
+		"long":       append(random[:70000:70000], random[:70000]...),
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			encoded := snappyEncode(data)
+			decoded, err := snappyDecode(encoded)
+			if err != nil {
+				t.Fatalf("snappyDecode() error = %v", err)
+			}
+			if !bytes.Equal(decoded, data) {
+				t.Fatal("decoded data differs from the input")
+			}
+		})
+	}
+}
+
+func TestSnappyEncodeCompresses(t *testing.T) {
+	data := []byte(strings.Repeat(`{"level":"info","message":"request served"}`, 500))
+
+	if encoded := snappyEncode(data); len(encoded) > len(data)/10 {
+		t.Errorf("encoded %d bytes into %d, want at least 10x smaller", len(data), len(encoded))
+	}
+}
@@ -0,0 +1,451 @@
+package drivers
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+	"github.com/MaoDaGreith/logging/pkg/internal/optparse"
+)
+
+// SocketDriverName is the name to use in configuration
+const SocketDriverName = "socket"
+
+func init() {
+	Register(SocketDriverName, NewSocketDriver)
+}
+
+// Socket line formats
+const (
+	// SocketJSON writes entries like the json_file driver
+	SocketJSON = "json"
+	// SocketText writes entries like the text_file driver
+	SocketText = "text"
+)
+
+// Socket defaults
+const (
+	defaultSocketBufferSize = 1000
+	defaultSocketTimeout    = 5 * time.Second
+)
+
+// SocketConfig configures a SocketDriver
+type SocketConfig struct {
+	// Network is tcp, tcp+tls, udp, unix or unixgram
+	Network string
+
+	// Address is the host:port or socket path of the endpoint
+	Address string
+
+	// Format is SocketJSON or SocketText
+	Format string
+
+	// BufferSize bounds the number of entries waiting to be written
+	BufferSize int
+
+	// TLSConfig is used by the tcp+tls network
+	TLSConfig *tls.Config
+
+	// Timeout bounds connecting, writing an entry and sending the buffered
+	// entries on Close
+	Timeout time.Duration
+
+	// Reconnect configures the backoff between connection attempts
+	Reconnect RetryConfig
+}
+
+// SocketHealth describes the state of a SocketDriver
+type SocketHealth struct {
+	// Connected reports whether the driver has a working connection
+	Connected bool
+
+	// Buffered is the number of entries waiting to be written
+	Buffered int
+
+	// Dropped counts the entries lost because the buffer was full or the
+	// driver was closed before they could be written
+	Dropped uint64
+
+	// Reconnects counts the connections made after the first one
+	Reconnects uint64
+
+	// LastError is the most recent connection or write error
+	LastError error
+
+	// LastErrorTime is when LastError happened
+	LastErrorTime time.Time
+}
+
+// SocketDriver streams newline-delimited entries to a TCP, UDP or Unix
+// endpoint, such as a Fluent Bit or Vector sidecar. Entries are written from
+// a background goroutine, so Log does not block on the network. While the
+// endpoint is unreachable, entries are buffered and the driver reconnects
+// with exponential backoff.
+type SocketDriver struct {
+	config SocketConfig
+	filter *core.LevelFilter
+	queue  chan []byte
+	abort  chan struct{}
+	done   chan struct{}
+
+	// mu guards closed against concurrent sends to the queue
+	mu     sync.RWMutex
+	closed bool
+
+	healthMu sync.Mutex
+	health   SocketHealth
+
+	// conn and everConnected are only used by the background goroutine
+	conn          *socketConn
+	everConnected bool
+}
+
+// socketConn is a connection with a channel closed when the peer hangs up,
+// which a write alone does not always notice
+type socketConn struct {
+	net.Conn
+	hungUp chan struct{}
+
+	// dropped is set, under the health lock, when the driver closes the
+	// connection itself
+	dropped bool
+}
+
+// NewSocketDriver creates a new socket driver from a map of options
+func NewSocketDriver(options map[string]interface{}) (core.Driver, error) {
+	filter, err := newLevelFilter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	config := SocketConfig{Network: "tcp"}
+	if network, ok := options["network"].(string); ok {
+		config.Network = network
+	}
+	if address, ok := options["address"].(string); ok {
+		config.Address = address
+	}
+	if format, ok := options["format"].(string); ok {
+		config.Format = format
+	}
+	if config.BufferSize, err = optparse.Int(options["buffer_size"], defaultSocketBufferSize); err != nil {
+		return nil, fmt.Errorf("invalid buffer_size: %w", err)
+	}
+	if config.Timeout, err = optparse.Duration(options["timeout"], defaultSocketTimeout); err != nil {
+		return nil, fmt.Errorf("invalid timeout: %w", err)
+	}
+	if config.Reconnect.InitialBackoff, err = optparse.Duration(options["initial_backoff"], defaultInitialBackoff); err != nil {
+		return nil, fmt.Errorf("invalid initial_backoff: %w", err)
+	}
+	if config.Reconnect.MaxBackoff, err = optparse.Duration(options["max_backoff"], defaultMaxBackoff); err != nil {
+		return nil, fmt.Errorf("invalid max_backoff: %w", err)
+	}
+
+	if config.Network == "tcp+tls" {
+		if config.TLSConfig, err = newTLSConfig(options); err != nil {
+			return nil, err
+		}
+	}
+
+	driver, err := NewSocketDriverWithConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	driver.filter = filter
+
+	return driver, nil
+}
+
+// NewSocketDriverWithConfig creates a socket driver and starts its
+// background goroutine, which connects on the first entry
+func NewSocketDriverWithConfig(config SocketConfig) (*SocketDriver, error) {
+	switch config.Network {
+	case "":
+		config.Network = "tcp"
+	case "tcp", "tcp+tls", "udp", "unix", "unixgram":
+	default:
+		return nil, fmt.Errorf("unknown network: %s", config.Network)
+	}
+
+	if config.Address == "" {
+		return nil, fmt.Errorf("address is required")
+	}
+
+	switch config.Format {
+	case "":
+		config.Format = SocketJSON
+	case SocketJSON, SocketText:
+	default:
+		return nil, fmt.Errorf("unknown format: %s", config.Format)
+	}
+
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaultSocketBufferSize
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultSocketTimeout
+	}
+	if config.TLSConfig == nil && config.Network == "tcp+tls" {
+		config.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	config.Reconnect = config.Reconnect.withDefaults()
+
+	d := &SocketDriver{
+		config: config,
+		queue:  make(chan []byte, config.BufferSize),
+		abort:  make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	go d.run()
+
+	return d, nil
+}
+
+// Log queues the entry without blocking. It returns ErrQueueFull when the
+// buffer is full and the entry is dropped.
+func (d *SocketDriver) Log(entry *core.LogEntry) error {
+	if !d.filter.Allows(entry.Level) {
+		return nil
+	}
+
+	line, err := d.encode(entry)
+	if err != nil {
+		return err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		return fmt.Errorf("driver is closed")
+	}
+
+	select {
+	case d.queue <- line:
+		return nil
+	default:
+		d.healthMu.Lock()
+		d.health.Dropped++
+		d.healthMu.Unlock()
+		return ErrQueueFull
+	}
+}
+
+// encode renders the entry as one line. Line breaks in text entries, from
+// error chains and stack traces, are escaped as \n.
+func (d *SocketDriver) encode(entry *core.LogEntry) ([]byte, error) {
+	if d.config.Format == SocketText {
+		return []byte(strings.ReplaceAll(formatTextEntry(entry), "\n", `\n`) + "\n"), nil
+	}
+
+	line, err := json.Marshal(NewJSONLogEntry(entry))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode entry: %w", err)
+	}
+	return append(line, '\n'), nil
+}
+
+// Health returns the current state of the driver
+func (d *SocketDriver) Health() SocketHealth {
+	d.healthMu.Lock()
+	defer d.healthMu.Unlock()
+
+	health := d.health
+	health.Buffered = len(d.queue)
+	return health
+}
+
+// Name returns the driver name
+func (d *SocketDriver) Name() string {
+	return SocketDriverName
+}
+
+// Enabled reports whether the driver accepts entries at the level
+func (d *SocketDriver) Enabled(level core.Level) bool {
+	return d.filter.Allows(level)
+}
+
+// Close writes the buffered entries, waiting at most for the timeout, and
+// closes the connection. It reports the entries that could not be written.
+func (d *SocketDriver) Close() error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	close(d.queue)
+	d.mu.Unlock()
+
+	dropped := d.Health().Dropped
+
+	timer := time.NewTimer(d.config.Timeout)
+	defer timer.Stop()
+	select {
+	case <-d.done:
+	case <-timer.C:
+		close(d.abort)
+		<-d.done
+	}
+
+	if lost := d.Health().Dropped - dropped; lost > 0 {
+		return fmt.Errorf("closed with %d entries not sent", lost)
+	}
+
+	return nil
+}
+
+// run writes queued entries until the queue is closed and drained, or the
+// driver is aborted
+func (d *SocketDriver) run() {
+	defer close(d.done)
+	defer d.disconnect()
+
+	for line := range d.queue {
+		d.write(line)
+	}
+}
+
+// write writes one line, reconnecting until it succeeds or the driver is
+// aborted. A broken connection is re-established right away once, then
+// with backoff.
+func (d *SocketDriver) write(line []byte) {
+	for attempt := 0; ; {
+		select {
+		case <-d.abort:
+			d.healthMu.Lock()
+			d.health.Dropped++
+			d.healthMu.Unlock()
+			return
+		default:
+		}
+
+		if d.conn == nil {
+			if err := d.connect(); err != nil {
+				d.recordError(fmt.Errorf("failed to connect: %w", err))
+				d.wait(d.config.Reconnect.backoff(attempt))
+				attempt++
+				continue
+			}
+		}
+
+		err := d.writeConn(line)
+		if err == nil {
+			return
+		}
+
+		d.recordError(fmt.Errorf("failed to write: %w", err))
+		d.disconnect()
+		if attempt > 0 {
+			d.wait(d.config.Reconnect.backoff(attempt))
+		}
+		attempt++
+	}
+}
+
+// writeConn writes a line unless the peer has hung up
+func (d *SocketDriver) writeConn(line []byte) error {
+	select {
+	case <-d.conn.hungUp:
+		return fmt.Errorf("connection closed by peer")
+	default:
+	}
+
+	d.conn.SetWriteDeadline(time.Now().Add(d.config.Timeout))
+	_, err := d.conn.Write(line)
+	return err
+}
+
+// connect dials the endpoint. On stream connections a goroutine watches for
+// the peer hanging up; the endpoints never send anything.
+func (d *SocketDriver) connect() error {
+	dialer := &net.Dialer{Timeout: d.config.Timeout}
+
+	var conn net.Conn
+	var err error
+	if d.config.Network == "tcp+tls" {
+		var tlsConn *tls.Conn
+		if tlsConn, err = tls.DialWithDialer(dialer, "tcp", d.config.Address, d.config.TLSConfig); err == nil {
+			conn = tlsConn
+		}
+	} else {
+		conn, err = dialer.Dial(d.config.Network, d.config.Address)
+	}
+	if err != nil {
+		return err
+	}
+
+	d.conn = &socketConn{Conn: conn, hungUp: make(chan struct{})}
+	switch d.config.Network {
+	case "tcp", "tcp+tls", "unix":
+		go d.watch(d.conn)
+	}
+
+	d.healthMu.Lock()
+	d.health.Connected = true
+	if d.everConnected {
+		d.health.Reconnects++
+	}
+	d.healthMu.Unlock()
+	d.everConnected = true
+
+	return nil
+}
+
+// watch reads from a stream connection until the peer hangs up, so that
+// Health reports the disconnect before the next write
+func (d *SocketDriver) watch(c *socketConn) {
+	io.Copy(io.Discard, c)
+	close(c.hungUp)
+
+	d.healthMu.Lock()
+	defer d.healthMu.Unlock()
+
+	if !c.dropped {
+		d.health.Connected = false
+		d.health.LastError = fmt.Errorf("connection closed by peer")
+		d.health.LastErrorTime = time.Now()
+	}
+}
+
+// disconnect closes the current connection, if any
+func (d *SocketDriver) disconnect() {
+	if d.conn == nil {
+		return
+	}
+
+	d.healthMu.Lock()
+	d.conn.dropped = true
+	d.health.Connected = false
+	d.healthMu.Unlock()
+
+	d.conn.Close()
+	d.conn = nil
+}
+
+// wait sleeps for the backoff delay unless the driver is aborted
+func (d *SocketDriver) wait(delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-d.abort:
+	}
+}
+
+// recordError stores the last error for Health
+func (d *SocketDriver) recordError(err error) {
+	d.healthMu.Lock()
+	defer d.healthMu.Unlock()
+
+	d.health.LastError = err
+	d.health.LastErrorTime = time.Now()
+}
@@ -0,0 +1,324 @@
+package drivers
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// readLine reads a newline-delimited entry
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	return strings.TrimSuffix(line, "\n"), err
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func newTestSocketDriver(t *testing.T, options map[string]interface{}) *SocketDriver {
+	t.Helper()
+
+	if _, ok := options["initial_backoff"]; !ok {
+		options["initial_backoff"] = "10ms"
+	}
+	options["max_backoff"] = "50ms"
+
+	driver, err := Create(SocketDriverName, options)
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+	t.Cleanup(func() { driver.Close() })
+
+	return driver.(*SocketDriver)
+}
+
+func TestSocketDriverTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	messages := acceptMessages(listener, readLine)
+	driver := newTestSocketDriver(t, map[string]interface{}{"address": listener.Addr().String()})
+
+	for _, msg := range []string{"first", "second"} {
+		if err := driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: msg, Attrs: core.Attributes{"k": "v"}}); err != nil {
+			t.Fatalf("Log failed: %v", err)
+		}
+	}
+
+	for _, want := range []string{"first", "second"} {
+		var entry JSONLogEntry
+		if err := json.Unmarshal([]byte(receive(t, messages)), &entry); err != nil {
+			t.Fatalf("Invalid JSON line: %v", err)
+		}
+		if entry.Message != want || entry.Attributes["k"] != "v" {
+			t.Errorf("entry = %+v, want message %s", entry, want)
+		}
+	}
+
+	health := driver.Health()
+	if !health.Connected || health.Buffered != 0 || health.LastError != nil {
+		t.Errorf("Health() = %+v", health)
+	}
+}
+
+func TestSocketDriverText(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	messages := acceptMessages(listener, readLine)
+	driver := newTestSocketDriver(t, map[string]interface{}{
+		"address": listener.Addr().String(),
+		"format":  "text",
+	})
+
+	driver.Log(&core.LogEntry{
+		Timestamp: time.Now(),
+		Level:     core.Error,
+		Message:   "failed",
+		Error:     &core.ErrorInfo{Message: "boom", Chain: []core.ErrorCause{{Type: "*errors.errorString", Message: "boom"}}},
+	})
+
+	line := receive(t, messages)
+	if !strings.Contains(line, "[ERROR] failed\\n    error: *errors.errorString: boom") {
+		t.Errorf("Unexpected line: %q", line)
+	}
+}
+
+func TestSocketDriverUDP(t *testing.T) {
+	conn := listenUDP(t)
+	driver := newTestSocketDriver(t, map[string]interface{}{
+		"network": "udp",
+		"address": conn.LocalAddr().String(),
+	})
+
+	driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: "datagram"})
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 4096)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Failed to read datagram: %v", err)
+	}
+	if line := string(buf[:n]); !strings.HasSuffix(line, "\n") || !strings.Contains(line, `"message":"datagram"`) {
+		t.Errorf("Unexpected datagram: %q", line)
+	}
+}
+
+func TestSocketDriverUnix(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix sockets are not tested on Windows")
+	}
+
+	path := filepath.Join(t.TempDir(), "sidecar.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	messages := acceptMessages(listener, readLine)
+	driver := newTestSocketDriver(t, map[string]interface{}{
+		"network": "unix",
+		"address": path,
+	})
+
+	driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: "local"})
+
+	if line := receive(t, messages); !strings.Contains(line, `"message":"local"`) {
+		t.Errorf("Unexpected line: %q", line)
+	}
+}
+
+func TestSocketDriverReconnects(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	// The first connection is closed after one line, as by a restarting sidecar
+	lines := make(chan string, 10)
+	go func() {
+		for i := 0; ; i++ {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn, first bool) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					line, err := readLine(reader)
+					if err != nil {
+						return
+					}
+					lines <- line
+					if first {
+						return
+					}
+				}
+			}(conn, i == 0)
+		}
+	}()
+
+	driver := newTestSocketDriver(t, map[string]interface{}{"address": listener.Addr().String()})
+
+	driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: "before"})
+	if line := receive(t, lines); !strings.Contains(line, "before") {
+		t.Fatalf("Unexpected line: %q", line)
+	}
+
+	// Wait for the driver to notice the hang-up before logging again
+	waitFor(t, "the disconnect", func() bool { return !driver.Health().Connected })
+
+	driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: "after"})
+	if line := receive(t, lines); !strings.Contains(line, "after") {
+		t.Fatalf("Unexpected line: %q", line)
+	}
+
+	health := driver.Health()
+	if !health.Connected || health.Reconnects != 1 || health.LastError == nil {
+		t.Errorf("Health() = %+v, want connected after one reconnect", health)
+	}
+}
+
+func TestSocketDriverBuffersWhileDisconnected(t *testing.T) {
+	// Reserve a port, then free it so that nothing is listening yet
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	driver := newTestSocketDriver(t, map[string]interface{}{"address": address})
+
+	for _, msg := range []string{"one", "two", "three"} {
+		if err := driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: msg}); err != nil {
+			t.Fatalf("Log failed: %v", err)
+		}
+	}
+
+	waitFor(t, "a connection error", func() bool { return driver.Health().LastError != nil })
+	if health := driver.Health(); health.Connected || health.Buffered < 2 {
+		t.Errorf("Health() = %+v, want disconnected with buffered entries", health)
+	}
+
+	if listener, err = net.Listen("tcp", address); err != nil {
+		t.Skipf("Port was taken in the meantime: %v", err)
+	}
+	defer listener.Close()
+
+	messages := acceptMessages(listener, readLine)
+	for _, want := range []string{"one", "two", "three"} {
+		if line := receive(t, messages); !strings.Contains(line, want) {
+			t.Errorf("Unexpected line %q, want %s", line, want)
+		}
+	}
+
+	waitFor(t, "the connection", func() bool { return driver.Health().Connected })
+}
+
+func TestSocketDriverBufferFull(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	driver := newTestSocketDriver(t, map[string]interface{}{
+		"address":     address,
+		"buffer_size": 1,
+		"timeout":     "50ms",
+	})
+
+	err = nil
+	for i := 0; i < 10 && err == nil; i++ {
+		err = driver.Log(&core.LogEntry{Level: core.Info, Message: "flood"})
+	}
+	if !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Log() error = %v, want ErrQueueFull", err)
+	}
+	if health := driver.Health(); health.Dropped == 0 {
+		t.Errorf("Health() = %+v, want dropped entries", health)
+	}
+
+	// The entries still buffered cannot be sent before the timeout
+	if err := driver.Close(); err == nil || !strings.Contains(err.Error(), "not sent") {
+		t.Errorf("Close() error = %v, want unsent entries reported", err)
+	}
+	if err := driver.Log(&core.LogEntry{Level: core.Info, Message: "late"}); err == nil {
+		t.Error("Log after Close succeeded")
+	}
+}
+
+func TestSocketDriverTLS(t *testing.T) {
+	cert, caFile := testCertificate(t)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	messages := acceptMessages(listener, readLine)
+	driver := newTestSocketDriver(t, map[string]interface{}{
+		"network":     "tcp+tls",
+		"address":     listener.Addr().String(),
+		"tls_ca_file": caFile,
+	})
+
+	driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: "secure"})
+
+	if line := receive(t, messages); !strings.Contains(line, `"message":"secure"`) {
+		t.Errorf("Unexpected line: %q", line)
+	}
+}
+
+func TestNewSocketDriverInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+	}{
+		{name: "missing address", options: map[string]interface{}{}},
+		{name: "network", options: map[string]interface{}{"address": "localhost:5170", "network": "sctp"}},
+		{name: "format", options: map[string]interface{}{"address": "localhost:5170", "format": "xml"}},
+		{name: "buffer_size", options: map[string]interface{}{"address": "localhost:5170", "buffer_size": "big"}},
+		{name: "backoff", options: map[string]interface{}{"address": "localhost:5170", "max_backoff": "later"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if driver, err := Create(SocketDriverName, test.options); err == nil {
+				driver.Close()
+				t.Error("Create succeeded, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,458 @@
+package drivers
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+	"github.com/MaoDaGreith/logging/pkg/internal/optparse"
+)
+
+// SyslogDriverName is the name to use in configuration
+const SyslogDriverName = "syslog"
+
+func init() {
+	Register(SyslogDriverName, NewSyslogDriver)
+}
+
+// Syslog message formats
+const (
+	// SyslogRFC5424 is the current syslog protocol, with structured data
+	SyslogRFC5424 = "rfc5424"
+	// SyslogRFC3164 is the legacy BSD syslog format
+	SyslogRFC3164 = "rfc3164"
+)
+
+// Syslog framings of stream transports, see RFC 6587
+const (
+	// SyslogOctetCounting prefixes each message with its length
+	SyslogOctetCounting = "octet_counting"
+	// SyslogNewline terminates each message with a line feed
+	SyslogNewline = "newline"
+)
+
+// Syslog defaults
+const (
+	defaultSyslogAddress = "/dev/log"
+	defaultSyslogSDID    = "attrs@32473"
+	defaultSyslogTimeout = 5 * time.Second
+)
+
+// syslogTxSDID is the structured data element carrying the transaction ID
+const syslogTxSDID = "tx@32473"
+
+// syslogFacilities maps facility names to their codes
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// SyslogConfig configures a SyslogDriver
+type SyslogConfig struct {
+	// Network is unixgram, unix, udp, tcp or tcp+tls
+	Network string
+
+	// Address is the socket path or host:port of the syslog server
+	Address string
+
+	// Facility is the syslog facility code, e.g. 1 for user or 16 for local0
+	Facility int
+
+	// AppName identifies the application; defaults to the executable name
+	AppName string
+
+	// Hostname defaults to the name of the machine
+	Hostname string
+
+	// Format is SyslogRFC5424 or SyslogRFC3164
+	Format string
+
+	// Framing is used on stream transports: SyslogOctetCounting or SyslogNewline
+	Framing string
+
+	// StructuredDataID is the SD-ID of the element carrying the attributes
+	StructuredDataID string
+
+	// TLSConfig is used by the tcp+tls network
+	TLSConfig *tls.Config
+
+	// Timeout bounds connecting and writing a message
+	Timeout time.Duration
+}
+
+// SyslogDriver sends entries to a syslog daemon or a remote syslog server.
+// The connection is established on the first entry and re-established once
+// if a write fails.
+type SyslogDriver struct {
+	config SyslogConfig
+	filter *core.LevelFilter
+	pid    string
+	mu     sync.Mutex
+	conn   net.Conn
+	closed bool
+}
+
+// NewSyslogDriver creates a new syslog driver from a map of options
+func NewSyslogDriver(options map[string]interface{}) (core.Driver, error) {
+	filter, err := newLevelFilter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	config := SyslogConfig{
+		Network:          "unixgram",
+		Format:           SyslogRFC5424,
+		StructuredDataID: defaultSyslogSDID,
+		Facility:         syslogFacilities["user"],
+	}
+
+	if network, ok := options["network"].(string); ok {
+		config.Network = network
+	}
+	if address, ok := options["address"].(string); ok {
+		config.Address = address
+	}
+	if appName, ok := options["app_name"].(string); ok {
+		config.AppName = appName
+	}
+	if hostname, ok := options["hostname"].(string); ok {
+		config.Hostname = hostname
+	}
+	if format, ok := options["format"].(string); ok {
+		config.Format = format
+	}
+	if framing, ok := options["framing"].(string); ok {
+		config.Framing = framing
+	}
+	if sdID, ok := options["sd_id"].(string); ok {
+		config.StructuredDataID = sdID
+	}
+
+	switch facility := options["facility"].(type) {
+	case nil:
+	case string:
+		code, ok := syslogFacilities[strings.ToLower(facility)]
+		if !ok {
+			return nil, fmt.Errorf("unknown facility: %s", facility)
+		}
+		config.Facility = code
+	default:
+		if config.Facility, err = optparse.Int(facility, 0); err != nil {
+			return nil, fmt.Errorf("invalid facility: %w", err)
+		}
+	}
+
+	if config.Timeout, err = optparse.Duration(options["timeout"], defaultSyslogTimeout); err != nil {
+		return nil, fmt.Errorf("invalid timeout: %w", err)
+	}
+
+	if config.Network == "tcp+tls" {
+		if config.TLSConfig, err = newTLSConfig(options); err != nil {
+			return nil, err
+		}
+	}
+
+	driver, err := NewSyslogDriverWithConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	driver.filter = filter
+
+	return driver, nil
+}
+
+// NewSyslogDriverWithConfig creates a syslog driver. It does not connect
+// until the first entry is logged.
+func NewSyslogDriverWithConfig(config SyslogConfig) (*SyslogDriver, error) {
+	switch config.Network {
+	case "unixgram", "unix":
+		if config.Address == "" {
+			config.Address = defaultSyslogAddress
+		}
+	case "udp", "tcp", "tcp+tls":
+		if config.Address == "" {
+			return nil, fmt.Errorf("address is required for network %s", config.Network)
+		}
+	default:
+		return nil, fmt.Errorf("unknown network: %s", config.Network)
+	}
+
+	switch config.Format {
+	case "":
+		config.Format = SyslogRFC5424
+	case SyslogRFC5424, SyslogRFC3164:
+	default:
+		return nil, fmt.Errorf("unknown format: %s", config.Format)
+	}
+
+	switch config.Framing {
+	case "":
+		config.Framing = SyslogOctetCounting
+	case SyslogOctetCounting, SyslogNewline:
+	default:
+		return nil, fmt.Errorf("unknown framing: %s", config.Framing)
+	}
+
+	if config.Facility < 0 || config.Facility > 23 {
+		return nil, fmt.Errorf("facility must be between 0 and 23, got %d", config.Facility)
+	}
+
+	if config.AppName == "" {
+		config.AppName = filepath.Base(os.Args[0])
+	}
+	if config.Hostname == "" {
+		config.Hostname, _ = os.Hostname()
+	}
+	if config.StructuredDataID == "" {
+		config.StructuredDataID = defaultSyslogSDID
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultSyslogTimeout
+	}
+	if config.TLSConfig == nil && config.Network == "tcp+tls" {
+		config.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	return &SyslogDriver{
+		config: config,
+		pid:    strconv.Itoa(os.Getpid()),
+	}, nil
+}
+
+// Log sends the entry to the syslog server
+func (d *SyslogDriver) Log(entry *core.LogEntry) error {
+	if !d.filter.Allows(entry.Level) {
+		return nil
+	}
+
+	message := d.frame(d.format(entry))
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return fmt.Errorf("driver is closed")
+	}
+
+	// A broken connection, e.g. after the daemon restarted, is retried once
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if d.conn == nil {
+			conn, err := d.dial()
+			if err != nil {
+				return fmt.Errorf("failed to connect to syslog: %w", err)
+			}
+			d.conn = conn
+		}
+
+		d.conn.SetWriteDeadline(time.Now().Add(d.config.Timeout))
+		if _, err = d.conn.Write(message); err == nil {
+			return nil
+		}
+
+		d.conn.Close()
+		d.conn = nil
+	}
+
+	return fmt.Errorf("failed to write to syslog: %w", err)
+}
+
+// dial connects to the syslog server
+func (d *SyslogDriver) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: d.config.Timeout}
+
+	if d.config.Network == "tcp+tls" {
+		conn, err := tls.DialWithDialer(dialer, "tcp", d.config.Address, d.config.TLSConfig)
+		if err != nil {
+			return nil, err
+		}
+		return conn, nil
+	}
+
+	return dialer.Dial(d.config.Network, d.config.Address)
+}
+
+// frame applies the framing of stream transports to a message
+func (d *SyslogDriver) frame(message string) []byte {
+	switch d.config.Network {
+	case "unixgram", "udp":
+		return []byte(message)
+	}
+
+	if d.config.Framing == SyslogNewline {
+		return []byte(message + "\n")
+	}
+
+	return []byte(strconv.Itoa(len(message)) + " " + message)
+}
+
+// format renders the entry as a syslog message without framing
+func (d *SyslogDriver) format(entry *core.LogEntry) string {
+	priority := d.config.Facility*8 + entry.Level.SyslogSeverity()
+
+	msg := entry.Message
+	if entry.Error != nil && entry.Error.Message != entry.Message {
+		msg += ": " + entry.Error.Message
+	}
+
+	if d.config.Format == SyslogRFC3164 {
+		return d.formatRFC3164(priority, entry, msg)
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s %s %s - %s %s",
+		priority,
+		entry.Timestamp.Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeaderField(d.config.Hostname, 255),
+		syslogHeaderField(d.config.AppName, 48),
+		d.pid,
+		d.structuredData(entry),
+		msg,
+	)
+}
+
+// formatRFC3164 renders the entry in the BSD syslog format. Attributes and
+// the transaction ID are appended to the message.
+func (d *SyslogDriver) formatRFC3164(priority int, entry *core.LogEntry, msg string) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "<%d>%s %s %s[%s]: %s",
+		priority,
+		entry.Timestamp.Format(time.Stamp),
+		syslogHeaderField(d.config.Hostname, 255),
+		syslogHeaderField(d.config.AppName, 32),
+		d.pid,
+		msg,
+	)
+
+	if entry.TransactionID != "" {
+		fmt.Fprintf(&builder, " (tx: %s)", entry.TransactionID)
+	}
+
+	for _, key := range sortedKeys(entry.Attrs) {
+		fmt.Fprintf(&builder, " %s=%s", key, entry.Attrs[key])
+	}
+
+	return builder.String()
+}
+
+// structuredData renders the attributes and the transaction ID as RFC 5424
+// structured data elements, or "-" if there are none
+func (d *SyslogDriver) structuredData(entry *core.LogEntry) string {
+	if len(entry.Attrs) == 0 && entry.TransactionID == "" {
+		return "-"
+	}
+
+	var builder strings.Builder
+
+	if len(entry.Attrs) > 0 {
+		builder.WriteString("[" + d.config.StructuredDataID)
+		for _, key := range sortedKeys(entry.Attrs) {
+			builder.WriteString(" " + syslogParamName(key) + `="` + syslogParamValue(entry.Attrs[key]) + `"`)
+		}
+		builder.WriteString("]")
+	}
+
+	if entry.TransactionID != "" {
+		builder.WriteString("[" + syslogTxSDID + ` id="` + syslogParamValue(entry.TransactionID) + `"]`)
+	}
+
+	return builder.String()
+}
+
+// Name returns the driver name used in configuration
+func (d *SyslogDriver) Name() string {
+	return SyslogDriverName
+}
+
+// Enabled reports whether the driver accepts entries at the level
+func (d *SyslogDriver) Enabled(level core.Level) bool {
+	return d.filter.Allows(level)
+}
+
+// Close closes the connection to the syslog server
+func (d *SyslogDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.closed = true
+	if d.conn == nil {
+		return nil
+	}
+
+	err := d.conn.Close()
+	d.conn = nil
+	return err
+}
+
+// sortedKeys returns the attribute keys in sorted order
+func sortedKeys(attrs core.Attributes) []string {
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// syslogHeaderField makes a header field valid: printable US-ASCII without
+// spaces, at most maxLen characters, "-" when empty
+func syslogHeaderField(value string, maxLen int) string {
+	field := syslogPrintable(value, func(r rune) bool { return r == ' ' })
+	if len(field) > maxLen {
+		field = field[:maxLen]
+	}
+	if field == "" {
+		return "-"
+	}
+
+	return field
+}
+
+// syslogParamName makes an attribute key a valid SD-NAME: printable US-ASCII
+// except '=', ' ', ']' and '"', at most 32 characters
+func syslogParamName(key string) string {
+	name := syslogPrintable(key, func(r rune) bool { return r == '=' || r == ' ' || r == ']' || r == '"' })
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	if name == "" {
+		return "_"
+	}
+
+	return name
+}
+
+// syslogPrintable replaces characters outside printable US-ASCII, and those
+// rejected by invalid, with underscores
+func syslogPrintable(value string, invalid func(r rune) bool) string {
+	return strings.Map(func(r rune) rune {
+		if r < 32 || r > 126 || invalid(r) {
+			return '_'
+		}
+		return r
+	}, value)
+}
+
+// syslogParamValue escapes '"', '\' and ']' in a structured data value
+func syslogParamValue(value string) string {
+	var builder strings.Builder
+	for _, r := range value {
+		if r == '"' || r == '\\' || r == ']' {
+			builder.WriteByte('\\')
+		}
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,301 @@
+package drivers
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// syslogTestEntry is the entry used by the syslog tests
+var syslogTestEntry = &core.LogEntry{
+	Timestamp:     time.Date(2026, 3, 7, 14, 5, 9, 123456000, time.UTC),
+	Level:         core.Warning,
+	Message:       "disk almost full",
+	Attrs:         core.Attributes{"mount": "/var", "note": `50% "used"]`},
+	TransactionID: "tx-1",
+}
+
+func TestSyslogDriverFormat(t *testing.T) {
+	pid := os.Getpid()
+
+	tests := []struct {
+		name     string
+		config   SyslogConfig
+		entry    *core.LogEntry
+		expected string
+	}{
+		{
+			name:   "rfc5424",
+			config: SyslogConfig{Network: "udp", Address: "127.0.0.1:514", Facility: 16, AppName: "billing", Hostname: "web-1"},
+			entry:  syslogTestEntry,
+			expected: fmt.Sprintf(`<132>1 2026-03-07T14:05:09.123456Z web-1 billing %d - `+
+				`[attrs@32473 mount="/var" note="50%% \"used\"\]"][tx@32473 id="tx-1"] disk almost full`, pid),
+		},
+		{
+			name:     "rfc5424 without structured data",
+			config:   SyslogConfig{Network: "udp", Address: "127.0.0.1:514", Facility: 1, AppName: "my app", Hostname: "web-1"},
+			entry:    &core.LogEntry{Timestamp: syslogTestEntry.Timestamp, Level: core.Error, Message: "failed", Error: core.NewErrorInfo(errors.New("timeout"))},
+			expected: fmt.Sprintf(`<11>1 2026-03-07T14:05:09.123456Z web-1 my_app %d - - failed: timeout`, pid),
+		},
+		{
+			name:     "rfc5424 custom SD-ID and sanitized names",
+			config:   SyslogConfig{Network: "udp", Address: "127.0.0.1:514", AppName: "app", Hostname: "web-1", StructuredDataID: "meta@12345"},
+			entry:    &core.LogEntry{Timestamp: syslogTestEntry.Timestamp, Level: core.Debug, Message: "m", Attrs: core.Attributes{"a b=c": "v"}},
+			expected: fmt.Sprintf(`<7>1 2026-03-07T14:05:09.123456Z web-1 app %d - [meta@12345 a_b_c="v"] m`, pid),
+		},
+		{
+			name:     "rfc3164",
+			config:   SyslogConfig{Network: "udp", Address: "127.0.0.1:514", Facility: 3, AppName: "billing", Hostname: "web-1", Format: SyslogRFC3164},
+			entry:    syslogTestEntry,
+			expected: fmt.Sprintf(`<28>Mar  7 14:05:09 web-1 billing[%d]: disk almost full (tx: tx-1) mount=/var note=50%% "used"]`, pid),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			driver, err := NewSyslogDriverWithConfig(test.config)
+			if err != nil {
+				t.Fatalf("Failed to create driver: %v", err)
+			}
+
+			if got := driver.format(test.entry); got != test.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestSyslogDriverUnixgram(t *testing.T) {
+	dir, err := os.MkdirTemp("", "syslog")
+	if err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "log.sock")
+	listener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	driver, err := Create(SyslogDriverName, map[string]interface{}{
+		"address":  path,
+		"facility": "local3",
+		"app_name": "billing",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+	defer driver.Close()
+
+	if err := driver.Log(syslogTestEntry); err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+
+	buf := make([]byte, 4096)
+	listener.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := listener.Read(buf)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+
+	// local3 (19) * 8 + warning (4)
+	if msg := string(buf[:n]); !strings.HasPrefix(msg, "<156>1 ") || !strings.HasSuffix(msg, "disk almost full") {
+		t.Errorf("Unexpected message: %q", msg)
+	}
+}
+
+func TestSyslogDriverUDP(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	driver, err := Create(SyslogDriverName, map[string]interface{}{
+		"network": "udp",
+		"address": listener.LocalAddr().String(),
+		"format":  "rfc3164",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+	defer driver.Close()
+
+	driver.Log(syslogTestEntry)
+
+	buf := make([]byte, 4096)
+	listener.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := listener.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+
+	if msg := string(buf[:n]); !strings.HasPrefix(msg, "<12>Mar  7 14:05:09 ") {
+		t.Errorf("Unexpected message: %q", msg)
+	}
+}
+
+// readOctetCounted reads one octet-counted message
+func readOctetCounted(r *bufio.Reader) (string, error) {
+	length, err := r.ReadString(' ')
+	if err != nil {
+		return "", err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+	if err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// acceptMessages accepts one connection on listener and sends the messages
+// read by read to the returned channel
+func acceptMessages(listener net.Listener, read func(*bufio.Reader) (string, error)) <-chan string {
+	messages := make(chan string, 10)
+
+	go func() {
+		defer close(messages)
+
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		for {
+			msg, err := read(reader)
+			if err != nil {
+				return
+			}
+			messages <- msg
+		}
+	}()
+
+	return messages
+}
+
+// receive waits for a message
+func receive(t *testing.T, messages <-chan string) string {
+	t.Helper()
+
+	select {
+	case msg := <-messages:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for a message")
+		return ""
+	}
+}
+
+func TestSyslogDriverTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	messages := acceptMessages(listener, readOctetCounted)
+
+	driver, err := Create(SyslogDriverName, map[string]interface{}{
+		"network": "tcp",
+		"address": listener.Addr().String(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+	defer driver.Close()
+
+	driver.Log(syslogTestEntry)
+	driver.Log(&core.LogEntry{Timestamp: time.Now(), Level: core.Info, Message: "second"})
+
+	if msg := receive(t, messages); !strings.HasSuffix(msg, "disk almost full") {
+		t.Errorf("Unexpected first message: %q", msg)
+	}
+	if msg := receive(t, messages); !strings.HasPrefix(msg, "<14>1 ") || !strings.HasSuffix(msg, "second") {
+		t.Errorf("Unexpected second message: %q", msg)
+	}
+}
+
+func TestSyslogDriverTLS(t *testing.T) {
+	cert, caFile := testCertificate(t)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	messages := acceptMessages(listener, func(r *bufio.Reader) (string, error) {
+		line, err := r.ReadString('\n')
+		return strings.TrimSuffix(line, "\n"), err
+	})
+
+	driver, err := Create(SyslogDriverName, map[string]interface{}{
+		"network":     "tcp+tls",
+		"address":     listener.Addr().String(),
+		"framing":     "newline",
+		"tls_ca_file": caFile,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+	defer driver.Close()
+
+	if err := driver.Log(syslogTestEntry); err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+
+	if msg := receive(t, messages); !strings.HasSuffix(msg, "disk almost full") {
+		t.Errorf("Unexpected message: %q", msg)
+	}
+}
+
+func TestSyslogDriverErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"network": "carrier-pigeon"},
+		{"network": "tcp"},
+		{"format": "rfc9999"},
+		{"framing": "smoke"},
+		{"facility": "galaxy"},
+		{"facility": 42},
+		{"timeout": "never"},
+	}
+	for _, options := range invalid {
+		if _, err := Create(SyslogDriverName, options); err == nil {
+			t.Errorf("Expected an error for %v", options)
+		}
+	}
+
+	driver, err := NewSyslogDriverWithConfig(SyslogConfig{Network: "unixgram", Address: filepath.Join(t.TempDir(), "missing.sock")})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+	if err := driver.Log(syslogTestEntry); err == nil {
+		t.Error("Expected an error without a listener")
+	}
+
+	driver.Close()
+	if err := driver.Log(syslogTestEntry); err == nil {
+		t.Error("Expected an error after Close")
+	}
+}
@@ -21,7 +21,7 @@ func init() {
 type TextFileDriver struct {
 	filePath string
 	file     *os.File
-	minLevel core.Level
+	filter   *core.LevelFilter
 	mu       sync.Mutex
 }
 
@@ -32,6 +32,11 @@ func NewTextFileDriver(options map[string]interface{}) (core.Driver, error) {
 		return nil, fmt.Errorf("file_path is required")
 	}
 
+	filter, err := newLevelFilter(options)
+	if err != nil {
+		return nil, err
+	}
+
 	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create directory: %w", err)
@@ -45,13 +50,7 @@ func NewTextFileDriver(options map[string]interface{}) (core.Driver, error) {
 	driver := &TextFileDriver{
 		filePath: filePath,
 		file:     file,
-		minLevel: core.Debug,
-	}
-
-	if levelStr, ok := options["min_level"].(string); ok {
-		if level, err := core.ParseLevel(levelStr); err == nil {
-			driver.minLevel = level
-		}
+		filter:   filter,
 	}
 
 	return driver, nil
@@ -59,7 +58,7 @@ func NewTextFileDriver(options map[string]interface{}) (core.Driver, error) {
 
 // Log writes a log entry to the text file
 func (d *TextFileDriver) Log(entry *core.LogEntry) error {
-	if entry.Level < d.minLevel {
+	if !d.filter.Allows(entry.Level) {
 		return nil
 	}
 
@@ -70,6 +69,37 @@ func (d *TextFileDriver) Log(entry *core.LogEntry) error {
 		return fmt.Errorf("driver is closed")
 	}
 
+	_, err := d.file.WriteString(formatTextEntry(entry) + "\n")
+	return err
+}
+
+// Name returns the driver name used in configuration
+func (d *TextFileDriver) Name() string {
+	return TextFileDriverName
+}
+
+// Enabled reports whether the driver accepts entries at the level
+func (d *TextFileDriver) Enabled(level core.Level) bool {
+	return d.filter.Allows(level)
+}
+
+// Close closes the file
+func (d *TextFileDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.file == nil {
+		return nil
+	}
+
+	err := d.file.Close()
+	d.file = nil
+	return err
+}
+
+// formatTextEntry renders an entry as a line, followed by indented lines
+// for the attached error and the stack trace
+func formatTextEntry(entry *core.LogEntry) string {
 	var builder strings.Builder
 	builder.WriteString(entry.Timestamp.Format("2006-01-02T15:04:05.000Z07:00"))
 	builder.WriteString(" [")
@@ -98,22 +128,19 @@ func (d *TextFileDriver) Log(entry *core.LogEntry) error {
 		builder.WriteString(")")
 	}
 
-	builder.WriteString("\n")
-
-	_, err := d.file.WriteString(builder.String())
-	return err
-}
+	if entry.Caller != nil {
+		builder.WriteString(" (caller: ")
+		builder.WriteString(formatCallerFunction(entry.Caller))
+		builder.WriteString(")")
+	}
 
-// Close closes the file
-func (d *TextFileDriver) Close() error {
-	d.mu.Lock()
-	defer d.mu.Unlock()
+	if entry.Error != nil {
+		builder.WriteString(formatErrorChain(entry.Error, "    "))
+	}
 
-	if d.file == nil {
-		return nil
+	if len(entry.Stack) > 0 {
+		builder.WriteString(formatStack(entry.Stack, "    "))
 	}
 
-	err := d.file.Close()
-	d.file = nil
-	return err
+	return builder.String()
 }
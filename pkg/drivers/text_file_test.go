@@ -248,3 +248,69 @@ func TestTextFileDriverClose(t *testing.T) {
 		t.Error("Expected error when writing to closed driver")
 	}
 }
+
+func TestTextFileDriverCaller(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "caller_test.log")
+
+	driver, err := NewTextFileDriver(map[string]interface{}{
+		"file_path": filePath,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	err = driver.Log(&core.LogEntry{
+		Timestamp: time.Now(),
+		Level:     core.Info,
+		Message:   "message",
+		Caller:    &core.Frame{File: "app/main.go", Line: 42, Function: "main.main"},
+	})
+	if err != nil {
+		t.Errorf("Log() error = %v", err)
+	}
+	driver.Close()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	if !strings.Contains(string(data), "(caller: app/main.go:42 main.main)") {
+		t.Errorf("Output %q does not contain caller", data)
+	}
+}
+
+func TestTextFileDriverStack(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "stack_test.log")
+
+	driver, err := NewTextFileDriver(map[string]interface{}{
+		"file_path": filePath,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	err = driver.Log(&core.LogEntry{
+		Timestamp: time.Now(),
+		Level:     core.Error,
+		Message:   "message",
+		Stack: []core.Frame{
+			{Function: "main.handler", File: "/app/main.go", Line: 42},
+			{Function: "main.main", File: "/app/main.go", Line: 10},
+		},
+	})
+	if err != nil {
+		t.Errorf("Log() error = %v", err)
+	}
+	driver.Close()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	want := "message\n    stack:\n        main.handler (/app/main.go:42)\n        main.main (/app/main.go:10)\n"
+	if !strings.HasSuffix(string(data), want) {
+		t.Errorf("Output = %q, want suffix %q", data, want)
+	}
+}
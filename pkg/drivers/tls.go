@@ -0,0 +1,52 @@
+package drivers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/MaoDaGreith/logging/pkg/internal/optparse"
+)
+
+// newTLSConfig builds a TLS client configuration from the tls_* options of
+// the network drivers: tls_ca_file, tls_cert_file, tls_key_file,
+// tls_server_name and tls_insecure_skip_verify
+func newTLSConfig(options map[string]interface{}) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if caFile, ok := options["tls_ca_file"].(string); ok && caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls_ca_file: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in tls_ca_file %s", caFile)
+		}
+	}
+
+	certFile, _ := options["tls_cert_file"].(string)
+	keyFile, _ := options["tls_key_file"].(string)
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if serverName, ok := options["tls_server_name"].(string); ok {
+		config.ServerName = serverName
+	}
+
+	insecure, err := optparse.Bool(options["tls_insecure_skip_verify"], false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tls_insecure_skip_verify: %w", err)
+	}
+	config.InsecureSkipVerify = insecure
+
+	return config, nil
+}
@@ -0,0 +1,81 @@
+package drivers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCertificate creates a self-signed certificate for 127.0.0.1 and writes
+// it to a PEM file. It returns the certificate and the file path.
+func testCertificate(t *testing.T) (tls.Certificate, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "logging test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	certPath := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(certPath, certPEM, 0600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, certPath
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	_, caFile := testCertificate(t)
+
+	config, err := newTLSConfig(map[string]interface{}{
+		"tls_ca_file":              caFile,
+		"tls_server_name":          "logs.example.com",
+		"tls_insecure_skip_verify": "false",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create TLS config: %v", err)
+	}
+	if config.RootCAs == nil || config.ServerName != "logs.example.com" || config.InsecureSkipVerify {
+		t.Errorf("Unexpected TLS config: %+v", config)
+	}
+
+	invalid := []map[string]interface{}{
+		{"tls_ca_file": filepath.Join(t.TempDir(), "missing.pem")},
+		{"tls_ca_file": os.Args[0]},
+		{"tls_cert_file": caFile},
+		{"tls_insecure_skip_verify": "maybe"},
+	}
+	for _, options := range invalid {
+		if _, err := newTLSConfig(options); err == nil {
+			t.Errorf("Expected an error for %v", options)
+		}
+	}
+}
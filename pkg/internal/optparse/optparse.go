@@ -0,0 +1,128 @@
+// Package optparse reads typed values from the option maps that drivers and
+// processors receive from configuration files.
+package optparse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StringSlice reads a list of strings from an option value. Lists may be
+// given as YAML/JSON arrays or as a single comma-separated string.
+func StringSlice(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		var result []string
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				result = append(result, part)
+			}
+		}
+		return result, nil
+	case []string:
+		return v, nil
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected string, got %T", item)
+			}
+			result = append(result, s)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("expected list of strings, got %T", value)
+	}
+}
+
+// StringMap reads a map of strings from an option value. Non-string values
+// are formatted with fmt.Sprint.
+func StringMap(value interface{}) (map[string]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case map[string]string:
+		return v, nil
+	case map[string]interface{}:
+		result := make(map[string]string, len(v))
+		for key, item := range v {
+			result[key] = fmt.Sprint(item)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("expected map of strings, got %T", value)
+	}
+}
+
+// Int reads an integer option value, returning def if the value is missing.
+// JSON numbers and numeric strings are accepted.
+func Int(value interface{}, def int) (int, error) {
+	switch v := value.(type) {
+	case nil:
+		return def, nil
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		if v != float64(int(v)) {
+			return 0, fmt.Errorf("expected integer, got %v", v)
+		}
+		return int(v), nil
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return 0, fmt.Errorf("expected integer, got %q", v)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("expected integer, got %T", value)
+	}
+}
+
+// Duration reads a duration option value, returning def if the value is
+// missing. Strings use time.ParseDuration syntax ("1.5s", "200ms"); plain
+// numbers are taken as seconds.
+func Duration(value interface{}, def time.Duration) (time.Duration, error) {
+	switch v := value.(type) {
+	case nil:
+		return def, nil
+	case time.Duration:
+		return v, nil
+	case int:
+		return time.Duration(v) * time.Second, nil
+	case float64:
+		return time.Duration(v * float64(time.Second)), nil
+	case string:
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil {
+			return 0, fmt.Errorf("expected duration, got %q", v)
+		}
+		return d, nil
+	default:
+		return 0, fmt.Errorf("expected duration, got %T", value)
+	}
+}
+
+// Bool reads a boolean option value, returning def if the value is missing
+func Bool(value interface{}, def bool) (bool, error) {
+	switch v := value.(type) {
+	case nil:
+		return def, nil
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return false, fmt.Errorf("expected boolean, got %q", v)
+		}
+		return b, nil
+	default:
+		return false, fmt.Errorf("expected boolean, got %T", value)
+	}
+}
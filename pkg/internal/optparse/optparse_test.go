@@ -0,0 +1,90 @@
+package optparse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInt(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		want    int
+		wantErr bool
+	}{
+		{value: nil, want: 7},
+		{value: 3, want: 3},
+		{value: int64(4), want: 4},
+		{value: 5.0, want: 5},
+		{value: " 6 ", want: 6},
+		{value: 0, want: 0},
+		{value: 1.5, wantErr: true},
+		{value: "many", wantErr: true},
+		{value: true, wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := Int(test.value, 7)
+		if (err != nil) != test.wantErr {
+			t.Errorf("Int(%#v) error = %v, wantErr %v", test.value, err, test.wantErr)
+			continue
+		}
+		if !test.wantErr && got != test.want {
+			t.Errorf("Int(%#v) = %d, want %d", test.value, got, test.want)
+		}
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: nil, want: time.Minute},
+		{value: 2 * time.Second, want: 2 * time.Second},
+		{value: 3, want: 3 * time.Second},
+		{value: 1.5, want: 1500 * time.Millisecond},
+		{value: "200ms", want: 200 * time.Millisecond},
+		{value: "soon", wantErr: true},
+		{value: "5", wantErr: true},
+		{value: []string{"1s"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := Duration(test.value, time.Minute)
+		if (err != nil) != test.wantErr {
+			t.Errorf("Duration(%#v) error = %v, wantErr %v", test.value, err, test.wantErr)
+			continue
+		}
+		if !test.wantErr && got != test.want {
+			t.Errorf("Duration(%#v) = %v, want %v", test.value, got, test.want)
+		}
+	}
+}
+
+func TestBool(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		want    bool
+		wantErr bool
+	}{
+		{value: nil, want: true},
+		{value: false, want: false},
+		{value: "false", want: false},
+		{value: " 1 ", want: true},
+		{value: "yes", wantErr: true},
+		{value: 1, wantErr: true},
+		{value: 1.0, wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := Bool(test.value, true)
+		if (err != nil) != test.wantErr {
+			t.Errorf("Bool(%#v) error = %v, wantErr %v", test.value, err, test.wantErr)
+			continue
+		}
+		if !test.wantErr && got != test.want {
+			t.Errorf("Bool(%#v) = %v, want %v", test.value, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package logtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// AssertLogged fails the test unless an entry matching every matcher was
+// recorded. It returns the first matching entry, or nil.
+func AssertLogged(t testing.TB, r *Recorder, matchers ...Matcher) *core.LogEntry {
+	t.Helper()
+
+	found := r.Find(matchers...)
+	if len(found) == 0 {
+		t.Errorf("expected an entry with %s, got:%s", describe(matchers), formatEntries(r.Entries()))
+		return nil
+	}
+
+	return found[0]
+}
+
+// AssertNotLogged fails the test if an entry matching every matcher was recorded
+func AssertNotLogged(t testing.TB, r *Recorder, matchers ...Matcher) {
+	t.Helper()
+
+	if found := r.Find(matchers...); len(found) > 0 {
+		t.Errorf("expected no entry with %s, got:%s", describe(matchers), formatEntries(found))
+	}
+}
+
+// AssertCount fails the test unless exactly n entries matching every matcher were recorded
+func AssertCount(t testing.TB, r *Recorder, n int, matchers ...Matcher) {
+	t.Helper()
+
+	if found := r.Find(matchers...); len(found) != n {
+		t.Errorf("expected %d entries with %s, got %d:%s", n, describe(matchers), len(found), formatEntries(found))
+	}
+}
+
+// AssertNothingAbove fails the test if an entry above the level was recorded,
+// e.g. AssertNothingAbove(t, r, core.Warning) rejects errors
+func AssertNothingAbove(t testing.TB, r *Recorder, level core.Level) {
+	t.Helper()
+
+	AssertNotLogged(t, r, Above(level))
+}
+
+// AssertWaitFor waits until n entries matching every matcher were recorded
+// and fails the test if that does not happen within timeout
+func AssertWaitFor(t testing.TB, r *Recorder, n int, timeout time.Duration, matchers ...Matcher) []*core.LogEntry {
+	t.Helper()
+
+	found, ok := r.WaitFor(n, timeout, matchers...)
+	if !ok {
+		t.Errorf("expected %d entries with %s within %v, got %d:%s", n, describe(matchers), timeout, len(found), formatEntries(found))
+	}
+
+	return found
+}
+
+// formatEntries renders entries for failure messages, one per line
+func formatEntries(entries []*core.LogEntry) string {
+	if len(entries) == 0 {
+		return " no entries"
+	}
+
+	var builder strings.Builder
+	for _, entry := range entries {
+		fmt.Fprintf(&builder, "\n\t[%s] %s", entry.Level, entry.Message)
+		if entry.TransactionID != "" {
+			fmt.Fprintf(&builder, " (tx: %s)", entry.TransactionID)
+		}
+		if len(entry.Attrs) > 0 {
+			fmt.Fprintf(&builder, " %v", map[string]string(entry.Attrs))
+		}
+	}
+
+	return builder.String()
+}
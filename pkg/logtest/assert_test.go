@@ -0,0 +1,77 @@
+package logtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// fakeT records the failures and output of the helpers under test
+type fakeT struct {
+	testing.TB
+	errors   []string
+	logs     []string
+	cleanups []func()
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) Log(args ...interface{}) {
+	f.logs = append(f.logs, fmt.Sprint(args...))
+}
+
+func (f *fakeT) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func TestAssertions(t *testing.T) {
+	recorder := NewRecorder()
+	logger := core.NewLogger(recorder)
+	logger.Info("user created", core.Attributes{"user": "alice"})
+	logger.Warning("quota almost reached")
+
+	passing := &fakeT{}
+	entry := AssertLogged(passing, recorder, Level(core.Info), Attr("user", "alice"))
+	AssertNotLogged(passing, recorder, MessageContains("deleted"))
+	AssertCount(passing, recorder, 2)
+	AssertNothingAbove(passing, recorder, core.Warning)
+	AssertWaitFor(passing, recorder, 1, time.Millisecond, Level(core.Warning))
+
+	if len(passing.errors) != 0 {
+		t.Errorf("Expected no failures, got %v", passing.errors)
+	}
+	if entry == nil || entry.Message != "user created" {
+		t.Errorf("Expected the matching entry, got %v", entry)
+	}
+
+	logger.Error("disk failure")
+
+	failing := &fakeT{}
+	if AssertLogged(failing, recorder, Message("user deleted")) != nil {
+		t.Error("Expected no entry to be returned")
+	}
+	AssertNotLogged(failing, recorder, Level(core.Warning))
+	AssertCount(failing, recorder, 1, AtLeast(core.Info))
+	AssertNothingAbove(failing, recorder, core.Warning)
+	AssertWaitFor(failing, recorder, 2, time.Millisecond, Level(core.Error))
+
+	if len(failing.errors) != 5 {
+		t.Fatalf("Expected 5 failures, got %d: %v", len(failing.errors), failing.errors)
+	}
+
+	// Failures describe the matchers and list the relevant entries
+	expected := "expected an entry with message == \"user deleted\", got:\n\t[INFO] user created map[user:alice]"
+	if !strings.HasPrefix(failing.errors[0], expected) {
+		t.Errorf("Unexpected failure message: %q", failing.errors[0])
+	}
+	if !strings.Contains(failing.errors[3], "[ERROR] disk failure") {
+		t.Errorf("Unexpected failure message: %q", failing.errors[3])
+	}
+}
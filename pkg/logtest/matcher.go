@@ -0,0 +1,140 @@
+package logtest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// Matcher selects log entries. Its description is used in assertion failures.
+type Matcher struct {
+	desc  string
+	match func(entry *core.LogEntry) bool
+}
+
+// NewMatcher creates a matcher from a description and a predicate
+func NewMatcher(desc string, match func(entry *core.LogEntry) bool) Matcher {
+	return Matcher{desc: desc, match: match}
+}
+
+// Matches reports whether the entry is selected by the matcher
+func (m Matcher) Matches(entry *core.LogEntry) bool {
+	return m.match(entry)
+}
+
+// String returns the description of the matcher
+func (m Matcher) String() string {
+	return m.desc
+}
+
+// Level matches entries at exactly the level
+func Level(level core.Level) Matcher {
+	return NewMatcher(fmt.Sprintf("level == %s", level), func(entry *core.LogEntry) bool {
+		return entry.Level == level
+	})
+}
+
+// AtLeast matches entries at or above the level
+func AtLeast(level core.Level) Matcher {
+	return NewMatcher(fmt.Sprintf("level >= %s", level), func(entry *core.LogEntry) bool {
+		return entry.Level >= level
+	})
+}
+
+// Above matches entries strictly above the level
+func Above(level core.Level) Matcher {
+	return NewMatcher(fmt.Sprintf("level > %s", level), func(entry *core.LogEntry) bool {
+		return entry.Level > level
+	})
+}
+
+// Message matches entries with exactly the message
+func Message(msg string) Matcher {
+	return NewMatcher(fmt.Sprintf("message == %q", msg), func(entry *core.LogEntry) bool {
+		return entry.Message == msg
+	})
+}
+
+// MessageContains matches entries whose message contains substr
+func MessageContains(substr string) Matcher {
+	return NewMatcher(fmt.Sprintf("message contains %q", substr), func(entry *core.LogEntry) bool {
+		return strings.Contains(entry.Message, substr)
+	})
+}
+
+// MessageMatches matches entries whose message matches the regular
+// expression. It panics if the expression is invalid.
+func MessageMatches(expr string) Matcher {
+	re := regexp.MustCompile(expr)
+	return NewMatcher(fmt.Sprintf("message matches /%s/", expr), func(entry *core.LogEntry) bool {
+		return re.MatchString(entry.Message)
+	})
+}
+
+// Attr matches entries having the attribute with the value
+func Attr(key, value string) Matcher {
+	return NewMatcher(fmt.Sprintf("attrs.%s == %q", key, value), func(entry *core.LogEntry) bool {
+		actual, ok := entry.Attrs[key]
+		return ok && actual == value
+	})
+}
+
+// HasAttr matches entries having the attribute, whatever its value
+func HasAttr(key string) Matcher {
+	return NewMatcher(fmt.Sprintf("has attrs.%s", key), func(entry *core.LogEntry) bool {
+		_, ok := entry.Attrs[key]
+		return ok
+	})
+}
+
+// Attrs matches entries having all of the attributes with equal values
+func Attrs(attrs core.Attributes) Matcher {
+	return NewMatcher(fmt.Sprintf("attrs include %v", map[string]string(attrs)), func(entry *core.LogEntry) bool {
+		for key, value := range attrs {
+			if actual, ok := entry.Attrs[key]; !ok || actual != value {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// TransactionID matches the entries of a transaction
+func TransactionID(txID string) Matcher {
+	return NewMatcher(fmt.Sprintf("transaction_id == %q", txID), func(entry *core.LogEntry) bool {
+		return entry.TransactionID == txID
+	})
+}
+
+// HasError matches entries with a Go error attached
+func HasError() Matcher {
+	return NewMatcher("has error", func(entry *core.LogEntry) bool {
+		return entry.Error != nil
+	})
+}
+
+// matchAll reports whether the entry is selected by every matcher
+func matchAll(entry *core.LogEntry, matchers []Matcher) bool {
+	for _, matcher := range matchers {
+		if !matcher.Matches(entry) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// describe joins the descriptions of matchers
+func describe(matchers []Matcher) string {
+	if len(matchers) == 0 {
+		return "any entry"
+	}
+
+	descs := make([]string, len(matchers))
+	for i, matcher := range matchers {
+		descs[i] = matcher.String()
+	}
+	return strings.Join(descs, " && ")
+}
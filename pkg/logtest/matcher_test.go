@@ -0,0 +1,64 @@
+package logtest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+func TestMatchers(t *testing.T) {
+	entry := &core.LogEntry{
+		Level:         core.Warning,
+		Message:       "payment declined for order 42",
+		TransactionID: "tx-7",
+		Attrs:         core.Attributes{"component": "billing", "order": "42"},
+		Error:         core.NewErrorInfo(errors.New("card expired")),
+	}
+
+	tests := []struct {
+		matcher  Matcher
+		expected bool
+	}{
+		{Level(core.Warning), true},
+		{Level(core.Error), false},
+		{AtLeast(core.Warning), true},
+		{AtLeast(core.Error), false},
+		{Above(core.Info), true},
+		{Above(core.Warning), false},
+		{Message("payment declined for order 42"), true},
+		{Message("payment declined"), false},
+		{MessageContains("declined"), true},
+		{MessageMatches(`order \d+$`), true},
+		{MessageMatches(`^order`), false},
+		{Attr("component", "billing"), true},
+		{Attr("component", "web"), false},
+		{Attr("region", ""), false},
+		{HasAttr("order"), true},
+		{HasAttr("region"), false},
+		{Attrs(core.Attributes{"component": "billing", "order": "42"}), true},
+		{Attrs(core.Attributes{"component": "billing", "order": "43"}), false},
+		{TransactionID("tx-7"), true},
+		{TransactionID("tx-8"), false},
+		{HasError(), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.matcher.String(), func(t *testing.T) {
+			if got := test.matcher.Matches(entry); got != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	if got := describe(nil); got != "any entry" {
+		t.Errorf("Unexpected description: %q", got)
+	}
+
+	expected := `level == ERROR && attrs.user == "bob"`
+	if got := describe([]Matcher{Level(core.Error), Attr("user", "bob")}); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
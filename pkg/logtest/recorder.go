@@ -0,0 +1,120 @@
+// Package logtest provides a recording driver, entry matchers and assertion
+// helpers for testing code that logs, and a driver that writes log output
+// through testing.T.
+package logtest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// Recorder is a thread-safe driver that keeps every entry it receives
+type Recorder struct {
+	mu      sync.Mutex
+	entries []*core.LogEntry
+	closed  bool
+	changed chan struct{}
+}
+
+// NewRecorder creates an empty recorder
+func NewRecorder() *Recorder {
+	return &Recorder{
+		changed: make(chan struct{}),
+	}
+}
+
+// Log records the entry
+func (r *Recorder) Log(entry *core.LogEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, entry)
+
+	// Wake up every WaitFor call
+	close(r.changed)
+	r.changed = make(chan struct{})
+
+	return nil
+}
+
+// Close marks the recorder as closed. Entries logged afterwards are still recorded.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+	return nil
+}
+
+// Closed reports whether Close was called
+func (r *Recorder) Closed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.closed
+}
+
+// Entries returns the recorded entries in the order they were logged
+func (r *Recorder) Entries() []*core.LogEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := make([]*core.LogEntry, len(r.entries))
+	copy(entries, r.entries)
+	return entries
+}
+
+// Find returns the recorded entries matching every matcher
+func (r *Recorder) Find(matchers ...Matcher) []*core.LogEntry {
+	var found []*core.LogEntry
+	for _, entry := range r.Entries() {
+		if matchAll(entry, matchers) {
+			found = append(found, entry)
+		}
+	}
+
+	return found
+}
+
+// Len returns the number of recorded entries
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.entries)
+}
+
+// Reset discards the recorded entries
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = nil
+}
+
+// WaitFor waits until at least n entries matching every matcher have been
+// recorded, for at most timeout. It returns the matching entries and whether
+// there were enough of them in time.
+func (r *Recorder) WaitFor(n int, timeout time.Duration, matchers ...Matcher) ([]*core.LogEntry, bool) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		r.mu.Lock()
+		changed := r.changed
+		r.mu.Unlock()
+
+		found := r.Find(matchers...)
+		if len(found) >= n {
+			return found, true
+		}
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return found, false
+		}
+	}
+}
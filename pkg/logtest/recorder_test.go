@@ -0,0 +1,79 @@
+package logtest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+func TestRecorder(t *testing.T) {
+	recorder := NewRecorder()
+	logger := core.NewLogger(recorder)
+
+	logger.Info("first", core.Attributes{"user": "alice"})
+	logger.NewTransaction("tx-1").Error("second")
+
+	entries := recorder.Entries()
+	if len(entries) != 2 || entries[0].Message != "first" || entries[1].TransactionID != "tx-1" {
+		t.Fatalf("Unexpected entries: %v", entries)
+	}
+
+	if found := recorder.Find(Level(core.Error)); len(found) != 1 || found[0].Message != "second" {
+		t.Errorf("Unexpected Find result: %v", found)
+	}
+
+	recorder.Reset()
+	if recorder.Len() != 0 {
+		t.Errorf("Expected no entries after Reset, got %d", recorder.Len())
+	}
+
+	logger.Close()
+	if !recorder.Closed() {
+		t.Error("Expected the recorder to be closed")
+	}
+}
+
+func TestRecorderConcurrent(t *testing.T) {
+	recorder := NewRecorder()
+	logger := core.NewLogger(recorder)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Info("message")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if recorder.Len() != 1000 {
+		t.Errorf("Expected 1000 entries, got %d", recorder.Len())
+	}
+}
+
+func TestRecorderWaitFor(t *testing.T) {
+	recorder := NewRecorder()
+	logger := core.NewLogger(recorder)
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			time.Sleep(5 * time.Millisecond)
+			logger.Warning("retrying")
+		}
+	}()
+
+	found, ok := recorder.WaitFor(3, time.Second, Message("retrying"))
+	if !ok || len(found) != 3 {
+		t.Fatalf("Expected 3 entries, got %d (ok=%v)", len(found), ok)
+	}
+
+	found, ok = recorder.WaitFor(1, 20*time.Millisecond, Level(core.Error))
+	if ok || len(found) != 0 {
+		t.Errorf("Expected a timeout, got %d entries (ok=%v)", len(found), ok)
+	}
+}
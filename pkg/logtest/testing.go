@@ -0,0 +1,82 @@
+package logtest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+	"github.com/MaoDaGreith/logging/pkg/drivers"
+)
+
+// TestingDriver writes entries through t.Log, so that they are attributed to
+// the test and only shown when it fails or runs with -v. Entries logged after
+// the test finished are dropped, since testing.T does not allow them.
+//
+// The file:line prefix that t.Log adds does not point at the logging call:
+// the driver marks its own frames as helpers, but the logger and console
+// driver frames in between cannot be. Use the core.AddCaller option to
+// record where an entry was logged.
+type TestingDriver struct {
+	t       testing.TB
+	console *drivers.ConsoleDriver
+	mu      sync.Mutex
+	done    bool
+}
+
+// NewTestingDriver creates a driver logging through t. The entries are
+// formatted like the console driver's, without colors.
+func NewTestingDriver(t testing.TB, options ...drivers.ConsoleDriverOption) *TestingDriver {
+	d := &TestingDriver{t: t}
+
+	writer := testingWriter{d}
+	options = append([]drivers.ConsoleDriverOption{
+		drivers.WithTimeFormat("15:04:05.000"),
+		drivers.WithColorized(false),
+	}, options...)
+	options = append(options, drivers.WithStdout(writer), drivers.WithStderr(writer))
+	d.console = drivers.NewConsoleDriverWithOptions(options...)
+
+	t.Cleanup(func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.done = true
+	})
+
+	return d
+}
+
+// Log writes the entry through t.Log
+func (d *TestingDriver) Log(entry *core.LogEntry) error {
+	d.t.Helper()
+	return d.console.Log(entry)
+}
+
+// Enabled reports whether the driver accepts entries at the level
+func (d *TestingDriver) Enabled(level core.Level) bool {
+	return d.console.Enabled(level)
+}
+
+// Close does nothing; the driver stops logging when the test finishes
+func (d *TestingDriver) Close() error {
+	return nil
+}
+
+// testingWriter passes each formatted line to t.Log
+type testingWriter struct {
+	d *TestingDriver
+}
+
+// Write logs p through t.Log unless the test has finished
+func (w testingWriter) Write(p []byte) (int, error) {
+	w.d.t.Helper()
+
+	w.d.mu.Lock()
+	defer w.d.mu.Unlock()
+
+	if !w.d.done {
+		w.d.t.Log(strings.TrimSuffix(string(p), "\n"))
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,51 @@
+package logtest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+	"github.com/MaoDaGreith/logging/pkg/drivers"
+)
+
+func TestTestingDriver(t *testing.T) {
+	fake := &fakeT{}
+	driver := NewTestingDriver(fake, drivers.WithMinLevel(core.Info))
+	logger := core.NewLogger(driver)
+
+	logger.Debug("hidden")
+	logger.Info("visible", core.Attributes{"k": "v"})
+	logger.Err(errors.New("boom"), "failed")
+
+	if len(fake.logs) != 2 {
+		t.Fatalf("Expected 2 log lines, got %d: %q", len(fake.logs), fake.logs)
+	}
+	if !strings.Contains(fake.logs[0], "[INFO] [k=v] visible") || strings.HasSuffix(fake.logs[0], "\n") {
+		t.Errorf("Unexpected line: %q", fake.logs[0])
+	}
+	if !strings.Contains(fake.logs[1], "[ERROR] failed") || !strings.Contains(fake.logs[1], "boom") {
+		t.Errorf("Unexpected line: %q", fake.logs[1])
+	}
+	if strings.Contains(fake.logs[0], "\033[") {
+		t.Error("Expected no colors")
+	}
+	if driver.Enabled(core.Debug) {
+		t.Error("Expected the level filter to apply")
+	}
+
+	// Once the test finished, entries are dropped
+	for _, cleanup := range fake.cleanups {
+		cleanup()
+	}
+	if err := logger.Info("late"); err != nil || len(fake.logs) != 2 {
+		t.Errorf("Expected the entry to be dropped, got %v, %q", err, fake.logs)
+	}
+}
+
+func TestTestingDriverWithRealT(t *testing.T) {
+	logger := core.NewLogger(NewTestingDriver(t))
+	if err := logger.Info("shown with -v"); err != nil {
+		t.Errorf("Log failed: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package processors
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+	"github.com/MaoDaGreith/logging/pkg/internal/optparse"
+)
+
+// DropProcessorName is the name to use in configuration
+const DropProcessorName = "drop"
+
+func init() {
+	Register(DropProcessorName, NewDropProcessor)
+}
+
+// DropProcessor vetoes noisy entries whose message matches one of a set of
+// regular expressions or whose attributes have given values
+type DropProcessor struct {
+	messages []*regexp.Regexp
+	attrs    map[string]string
+}
+
+// NewDropProcessor creates a new drop processor from a map of options
+func NewDropProcessor(options map[string]interface{}) (core.Processor, error) {
+	patterns, err := optparse.StringSlice(options["messages"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid messages: %w", err)
+	}
+
+	attrs, err := optparse.StringMap(options["attrs"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid attrs: %w", err)
+	}
+
+	processor := &DropProcessor{
+		attrs: attrs,
+	}
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid message pattern %q: %w", pattern, err)
+		}
+		processor.messages = append(processor.messages, re)
+	}
+
+	return processor, nil
+}
+
+// Process drops the entry if any rule matches it
+func (p *DropProcessor) Process(entry *core.LogEntry) bool {
+	for _, re := range p.messages {
+		if re.MatchString(entry.Message) {
+			return false
+		}
+	}
+
+	for k, v := range p.attrs {
+		if value, ok := entry.Attrs[k]; ok && value == v {
+			return false
+		}
+	}
+
+	return true
+}
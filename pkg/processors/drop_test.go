@@ -0,0 +1,43 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+func TestDropProcessor(t *testing.T) {
+	processor, err := NewDropProcessor(map[string]interface{}{
+		"messages": []interface{}{"^health check", "cache (hit|miss)"},
+		"attrs":    map[string]interface{}{"path": "/metrics"},
+	})
+	if err != nil {
+		t.Fatalf("NewDropProcessor() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		entry *core.LogEntry
+		keep  bool
+	}{
+		{"unrelated", &core.LogEntry{Message: "user created"}, true},
+		{"message prefix", &core.LogEntry{Message: "health check ok"}, false},
+		{"message pattern", &core.LogEntry{Message: "redis cache miss for key"}, false},
+		{"attribute", &core.LogEntry{Message: "request", Attrs: core.Attributes{"path": "/metrics"}}, false},
+		{"other attribute value", &core.LogEntry{Message: "request", Attrs: core.Attributes{"path": "/users"}}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := processor.Process(test.entry); got != test.keep {
+				t.Errorf("Process() = %v, want %v", got, test.keep)
+			}
+		})
+	}
+}
+
+func TestDropProcessorInvalidPattern(t *testing.T) {
+	if _, err := NewDropProcessor(map[string]interface{}{"messages": "("}); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
@@ -0,0 +1,83 @@
+package processors
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+	"github.com/MaoDaGreith/logging/pkg/internal/optparse"
+)
+
+// FieldsProcessorName is the name to use in configuration
+const FieldsProcessorName = "fields"
+
+func init() {
+	Register(FieldsProcessorName, NewFieldsProcessor)
+}
+
+// FieldsProcessor adds static attributes such as the hostname, the process
+// ID or the build version to every entry
+type FieldsProcessor struct {
+	fields    core.Attributes
+	overwrite bool
+}
+
+// NewFieldsProcessor creates a new fields processor from a map of options
+func NewFieldsProcessor(options map[string]interface{}) (core.Processor, error) {
+	attrs, err := optparse.StringMap(options["attrs"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid attrs: %w", err)
+	}
+
+	processor := &FieldsProcessor{
+		fields: make(core.Attributes, len(attrs)+2),
+	}
+
+	for k, v := range attrs {
+		processor.fields[k] = v
+	}
+
+	hostname, err := optparse.Bool(options["hostname"], false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hostname: %w", err)
+	}
+	if hostname {
+		name, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hostname: %w", err)
+		}
+		processor.fields["hostname"] = name
+	}
+
+	pid, err := optparse.Bool(options["pid"], false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pid: %w", err)
+	}
+	if pid {
+		processor.fields["pid"] = strconv.Itoa(os.Getpid())
+	}
+
+	if processor.overwrite, err = optparse.Bool(options["overwrite"], false); err != nil {
+		return nil, fmt.Errorf("invalid overwrite: %w", err)
+	}
+
+	return processor, nil
+}
+
+// Process adds the configured fields to the entry. Attributes set by the
+// caller take precedence unless overwrite is enabled.
+func (p *FieldsProcessor) Process(entry *core.LogEntry) bool {
+	if entry.Attrs == nil {
+		entry.Attrs = make(core.Attributes, len(p.fields))
+	}
+
+	for k, v := range p.fields {
+		if _, exists := entry.Attrs[k]; exists && !p.overwrite {
+			continue
+		}
+		entry.Attrs[k] = v
+	}
+
+	return true
+}
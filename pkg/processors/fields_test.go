@@ -0,0 +1,77 @@
+package processors
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+func TestFieldsProcessor(t *testing.T) {
+	processor, err := NewFieldsProcessor(map[string]interface{}{
+		"hostname": true,
+		"pid":      true,
+		"attrs": map[string]interface{}{
+			"version": "1.4.2",
+			"build":   1234,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewFieldsProcessor() error = %v", err)
+	}
+
+	entry := &core.LogEntry{Message: "message", Attrs: core.Attributes{"version": "caller"}}
+	if !processor.Process(entry) {
+		t.Fatal("Process() dropped the entry")
+	}
+
+	hostname, _ := os.Hostname()
+	expected := core.Attributes{
+		"hostname": hostname,
+		"pid":      strconv.Itoa(os.Getpid()),
+		"build":    "1234",
+		"version":  "caller",
+	}
+	for k, v := range expected {
+		if entry.Attrs[k] != v {
+			t.Errorf("Attribute %q = %q, want %q", k, entry.Attrs[k], v)
+		}
+	}
+}
+
+func TestFieldsProcessorOverwrite(t *testing.T) {
+	processor, err := NewFieldsProcessor(map[string]interface{}{
+		"attrs":     map[string]interface{}{"env": "prod"},
+		"overwrite": "true",
+	})
+	if err != nil {
+		t.Fatalf("NewFieldsProcessor() error = %v", err)
+	}
+
+	entry := &core.LogEntry{}
+	processor.Process(entry)
+	if entry.Attrs["env"] != "prod" {
+		t.Errorf("Attribute env = %q, want %q", entry.Attrs["env"], "prod")
+	}
+
+	entry = &core.LogEntry{Attrs: core.Attributes{"env": "dev"}}
+	processor.Process(entry)
+	if entry.Attrs["env"] != "prod" {
+		t.Errorf("Attribute env = %q, want %q", entry.Attrs["env"], "prod")
+	}
+}
+
+func TestFieldsProcessorInvalidOptions(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"attrs": "nope"},
+		{"hostname": "sometimes"},
+		{"pid": 1},
+		{"overwrite": "maybe"},
+	}
+	for _, options := range invalid {
+		if _, err := NewFieldsProcessor(options); err == nil {
+			t.Errorf("Expected an error for %v", options)
+		}
+	}
+}
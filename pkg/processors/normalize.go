@@ -0,0 +1,98 @@
+package processors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// NormalizeKeysProcessorName is the name to use in configuration
+const NormalizeKeysProcessorName = "normalize_keys"
+
+func init() {
+	Register(NormalizeKeysProcessorName, NewNormalizeKeysProcessor)
+}
+
+// NormalizeKeysProcessor rewrites attribute keys to a consistent case so that
+// "userId", "UserID" and "user-id" all become "user_id"
+type NormalizeKeysProcessor struct {
+	convert func(string) string
+}
+
+// NewNormalizeKeysProcessor creates a new key normalizing processor from a map of options.
+// The "case" option selects "snake" (default) or "lower".
+func NewNormalizeKeysProcessor(options map[string]interface{}) (core.Processor, error) {
+	processor := &NormalizeKeysProcessor{
+		convert: snakeCase,
+	}
+
+	if keyCase, ok := options["case"].(string); ok {
+		switch keyCase {
+		case "snake":
+			processor.convert = snakeCase
+		case "lower":
+			processor.convert = strings.ToLower
+		default:
+			return nil, fmt.Errorf("unknown case: %s", keyCase)
+		}
+	}
+
+	return processor, nil
+}
+
+// Process rewrites the attribute keys of the entry. When two keys normalize
+// to the same key, the one that was already normalized wins; otherwise the
+// first key in sorted order wins, so the result does not depend on map order.
+func (p *NormalizeKeysProcessor) Process(entry *core.LogEntry) bool {
+	keys := make([]string, 0, len(entry.Attrs))
+	for key := range entry.Attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		normalized := p.convert(strings.TrimSpace(key))
+		if normalized == key {
+			continue
+		}
+
+		value := entry.Attrs[key]
+		delete(entry.Attrs, key)
+		if _, exists := entry.Attrs[normalized]; !exists {
+			entry.Attrs[normalized] = value
+		}
+	}
+
+	return true
+}
+
+// snakeCase converts camelCase, PascalCase, kebab-case and space separated
+// keys to snake_case. Acronyms are kept together: "HTTPStatus" becomes
+// "http_status". Dots are preserved for namespaced keys.
+func snakeCase(key string) string {
+	runes := []rune(key)
+	var builder strings.Builder
+
+	for i, r := range runes {
+		switch {
+		case r == '-' || r == ' ':
+			builder.WriteRune('_')
+		case unicode.IsUpper(r):
+			if i > 0 {
+				prev := runes[i-1]
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					builder.WriteRune('_')
+				}
+			}
+			builder.WriteRune(unicode.ToLower(r))
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,93 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+func TestSnakeCase(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"user_id", "user_id"},
+		{"userId", "user_id"},
+		{"UserID", "user_id"},
+		{"HTTPStatus", "http_status"},
+		{"user-id", "user_id"},
+		{"request id", "request_id"},
+		{"http.method", "http.method"},
+		{"retry2Count", "retry2_count"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			if got := snakeCase(test.key); got != test.expected {
+				t.Errorf("snakeCase(%q) = %q, want %q", test.key, got, test.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeKeysProcessor(t *testing.T) {
+	processor, err := NewNormalizeKeysProcessor(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("NewNormalizeKeysProcessor() error = %v", err)
+	}
+
+	entry := &core.LogEntry{Attrs: core.Attributes{
+		"userId":    "1",
+		"user_id":   "2",
+		"RequestID": "abc",
+	}}
+	processor.Process(entry)
+
+	expected := core.Attributes{"user_id": "2", "request_id": "abc"}
+	if len(entry.Attrs) != len(expected) {
+		t.Errorf("Attributes = %v, want %v", entry.Attrs, expected)
+	}
+	for k, v := range expected {
+		if entry.Attrs[k] != v {
+			t.Errorf("Attribute %q = %q, want %q", k, entry.Attrs[k], v)
+		}
+	}
+}
+
+func TestNormalizeKeysProcessorCollision(t *testing.T) {
+	processor, err := NewNormalizeKeysProcessor(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("NewNormalizeKeysProcessor() error = %v", err)
+	}
+
+	// Neither key is normalized, so the first in sorted order wins every time
+	for i := 0; i < 50; i++ {
+		entry := &core.LogEntry{Attrs: core.Attributes{
+			"userId": "lower",
+			"UserId": "upper",
+		}}
+		processor.Process(entry)
+
+		if len(entry.Attrs) != 1 || entry.Attrs["user_id"] != "upper" {
+			t.Fatalf("Attributes = %v, want user_id=upper", entry.Attrs)
+		}
+	}
+}
+
+func TestNormalizeKeysProcessorLowerCase(t *testing.T) {
+	processor, err := NewNormalizeKeysProcessor(map[string]interface{}{"case": "lower"})
+	if err != nil {
+		t.Fatalf("NewNormalizeKeysProcessor() error = %v", err)
+	}
+
+	entry := &core.LogEntry{Attrs: core.Attributes{"UserId": "1"}}
+	processor.Process(entry)
+
+	if entry.Attrs["userid"] != "1" {
+		t.Errorf("Attributes = %v, want userid", entry.Attrs)
+	}
+
+	if _, err := NewNormalizeKeysProcessor(map[string]interface{}{"case": "kebab"}); err == nil {
+		t.Error("Expected error for unknown case")
+	}
+}
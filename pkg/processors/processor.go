@@ -0,0 +1,28 @@
+// Package processors provides the processor registry and the built-in
+// processors that enrich, normalize or drop entries before they reach the
+// drivers.
+package processors
+
+import (
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+// ProcessorConstructor is a function type that creates a new processor instance from options
+type ProcessorConstructor func(options map[string]interface{}) (core.Processor, error)
+
+// registry holds all registered processor constructors
+var registry = make(map[string]ProcessorConstructor)
+
+// Register adds a processor constructor to the registry
+func Register(name string, constructor ProcessorConstructor) {
+	registry[name] = constructor
+}
+
+// Create instantiates a processor by name with the given options
+func Create(name string, options map[string]interface{}) (core.Processor, error) {
+	if constructor, ok := registry[name]; ok {
+		return constructor(options)
+	}
+
+	return nil, core.ErrProcessorNotFound
+}
@@ -0,0 +1,52 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+)
+
+func TestProcessorRegistry(t *testing.T) {
+	// Save original registry
+	originalRegistry := make(map[string]ProcessorConstructor)
+	for k, v := range registry {
+		originalRegistry[k] = v
+	}
+	defer func() { registry = originalRegistry }()
+
+	registry = make(map[string]ProcessorConstructor)
+
+	Register("test", func(options map[string]interface{}) (core.Processor, error) {
+		return core.ProcessorFunc(func(entry *core.LogEntry) bool { return true }), nil
+	})
+
+	processor, err := Create("test", nil)
+	if err != nil {
+		t.Errorf("Create() error = %v", err)
+	}
+	if processor == nil {
+		t.Error("Expected non-nil processor")
+	}
+
+	processor, err = Create("non-existent", nil)
+	if err != core.ErrProcessorNotFound {
+		t.Errorf("Create() error = %v, want %v", err, core.ErrProcessorNotFound)
+	}
+	if processor != nil {
+		t.Error("Expected nil processor for non-existent type")
+	}
+}
+
+func TestBuiltinProcessorRegistration(t *testing.T) {
+	for _, name := range []string{FieldsProcessorName, NormalizeKeysProcessorName, DropProcessorName, RedactProcessorName} {
+		t.Run(name, func(t *testing.T) {
+			processor, err := Create(name, map[string]interface{}{})
+			if err != nil {
+				t.Errorf("Create() error = %v", err)
+			}
+			if processor == nil {
+				t.Error("Expected non-nil processor")
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package processors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/MaoDaGreith/logging/pkg/core"
+	"github.com/MaoDaGreith/logging/pkg/internal/optparse"
+)
+
+// RedactProcessorName is the name to use in configuration
+const RedactProcessorName = "redact"
+
+func init() {
+	Register(RedactProcessorName, NewRedactProcessor)
+}
+
+// RedactMode selects what happens to a sensitive value
+type RedactMode string
+
+const (
+	// RedactMask replaces the value with a fixed mask
+	RedactMask RedactMode = "mask"
+	// RedactHash replaces the value with a truncated SHA-256 hash so that
+	// equal values can still be correlated
+	RedactHash RedactMode = "hash"
+	// RedactDrop removes the attribute, or the matched text from messages
+	RedactDrop RedactMode = "drop"
+)
+
+// defaultRedactMask is the replacement used in mask mode
+const defaultRedactMask = "[REDACTED]"
+
+// defaultRedactKeys are the attribute key globs redacted when none are configured
+var defaultRedactKeys = []string{
+	"password", "passwd", "*password*", "secret", "*secret*",
+	"authorization", "token", "*_token", "api_key", "apikey", "cookie", "set-cookie",
+}
+
+// valuePattern finds sensitive values inside arbitrary text
+type valuePattern struct {
+	re       *regexp.Regexp
+	validate func(match string) bool
+}
+
+// redactPatterns are the built-in value patterns, selectable by name
+var redactPatterns = map[string]valuePattern{
+	"email": {
+		re: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	},
+	"credit_card": {
+		re:       regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		validate: cardNumberValid,
+	},
+	"jwt": {
+		re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	},
+	"aws_access_key": {
+		re: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`),
+	},
+}
+
+// RedactProcessor removes passwords, tokens and personal data from entries.
+// Attributes whose key matches one of the key globs are redacted entirely;
+// everywhere else, text matching a value pattern is redacted. Messages,
+// attribute values and attached error messages are all covered.
+type RedactProcessor struct {
+	keys     []string
+	patterns []valuePattern
+	mode     RedactMode
+	mask     string
+	salt     string
+}
+
+// NewRedactProcessor creates a new redaction processor from a map of options
+func NewRedactProcessor(options map[string]interface{}) (core.Processor, error) {
+	processor := &RedactProcessor{
+		keys: defaultRedactKeys,
+		mode: RedactMask,
+		mask: defaultRedactMask,
+	}
+
+	if keys, err := optparse.StringSlice(options["keys"]); err != nil {
+		return nil, fmt.Errorf("invalid keys: %w", err)
+	} else if keys != nil {
+		processor.keys = nil
+		for _, key := range keys {
+			if _, err := path.Match(key, ""); err != nil {
+				return nil, fmt.Errorf("invalid key glob %q: %w", key, err)
+			}
+			processor.keys = append(processor.keys, strings.ToLower(key))
+		}
+	}
+
+	names, err := optparse.StringSlice(options["patterns"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid patterns: %w", err)
+	}
+	if names == nil {
+		names = []string{"email", "credit_card", "jwt", "aws_access_key"}
+	}
+	for _, name := range names {
+		pattern, ok := redactPatterns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown pattern: %s", name)
+		}
+		processor.patterns = append(processor.patterns, pattern)
+	}
+
+	custom, err := optparse.StringSlice(options["custom_patterns"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid custom_patterns: %w", err)
+	}
+	for _, expr := range custom {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid custom pattern %q: %w", expr, err)
+		}
+		processor.patterns = append(processor.patterns, valuePattern{re: re})
+	}
+
+	if mode, ok := options["mode"].(string); ok {
+		switch RedactMode(mode) {
+		case RedactMask, RedactHash, RedactDrop:
+			processor.mode = RedactMode(mode)
+		default:
+			return nil, fmt.Errorf("unknown mode: %s", mode)
+		}
+	}
+
+	if mask, ok := options["mask"].(string); ok {
+		processor.mask = mask
+	}
+
+	if salt, ok := options["hash_salt"].(string); ok {
+		processor.salt = salt
+	}
+
+	return processor, nil
+}
+
+// Process redacts sensitive data in the entry. It never drops the entry.
+func (p *RedactProcessor) Process(entry *core.LogEntry) bool {
+	for key, value := range entry.Attrs {
+		if p.sensitiveKey(key) {
+			if p.mode == RedactDrop {
+				delete(entry.Attrs, key)
+			} else {
+				entry.Attrs[key] = p.replacement(value)
+			}
+			continue
+		}
+
+		redacted, found := p.redactText(value)
+		switch {
+		case found && p.mode == RedactDrop:
+			delete(entry.Attrs, key)
+		case found:
+			entry.Attrs[key] = redacted
+		}
+	}
+
+	entry.Message, _ = p.redactText(entry.Message)
+
+	if entry.Error != nil {
+		// Copy the error info so that entries sharing it are not affected
+		info := *entry.Error
+		info.Message, _ = p.redactText(info.Message)
+		info.Chain = make([]core.ErrorCause, len(entry.Error.Chain))
+		for i, cause := range entry.Error.Chain {
+			cause.Message, _ = p.redactText(cause.Message)
+			info.Chain[i] = cause
+		}
+		entry.Error = &info
+	}
+
+	return true
+}
+
+// sensitiveKey reports whether the attribute key matches a key glob
+func (p *RedactProcessor) sensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, glob := range p.keys {
+		if matched, _ := path.Match(glob, key); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactText redacts every value pattern match in text and reports whether
+// anything was found
+func (p *RedactProcessor) redactText(text string) (string, bool) {
+	found := false
+
+	for _, pattern := range p.patterns {
+		text = pattern.re.ReplaceAllStringFunc(text, func(match string) string {
+			if pattern.validate != nil && !pattern.validate(match) {
+				return match
+			}
+			found = true
+			if p.mode == RedactDrop {
+				return ""
+			}
+			return p.replacement(match)
+		})
+	}
+
+	return text, found
+}
+
+// replacement returns the masked or hashed form of a sensitive value
+func (p *RedactProcessor) replacement(value string) string {
+	if p.mode == RedactHash {
+		sum := sha256.Sum256([]byte(p.salt + value))
+		return "sha256:" + hex.EncodeToString(sum[:8])
+	}
+
+	return p.mask
+}
+
+// cardRange is a range of issuer identification numbers: card numbers
+// whose first prefixLen digits are between low and high and whose length is
+// one of lengths
+type cardRange struct {
+	prefixLen int
+	low, high int
+	lengths   []int
+}
+
+// cardRanges are the issuer ranges of the major card networks. Checking them
+// keeps order IDs, timestamps and other long numbers that happen to pass the
+// Luhn check from being redacted.
+var cardRanges = []cardRange{
+	{1, 4, 4, []int{13, 16, 19}},             // Visa
+	{2, 51, 55, []int{16}},                   // Mastercard
+	{4, 2221, 2720, []int{16}},               // Mastercard 2-series
+	{2, 34, 34, []int{15}},                   // American Express
+	{2, 37, 37, []int{15}},                   // American Express
+	{3, 300, 305, []int{14, 16, 17, 18, 19}}, // Diners Club
+	{2, 36, 36, []int{14, 16, 17, 18, 19}},   // Diners Club
+	{2, 38, 39, []int{16, 17, 18, 19}},       // Diners Club
+	{4, 6011, 6011, []int{16, 17, 18, 19}},   // Discover
+	{3, 644, 649, []int{16, 17, 18, 19}},     // Discover
+	{2, 65, 65, []int{16, 17, 18, 19}},       // Discover
+	{4, 3528, 3589, []int{16, 17, 18, 19}},   // JCB
+	{2, 62, 62, []int{16, 17, 18, 19}},       // UnionPay
+}
+
+// cardNumberValid reports whether s, ignoring spaces and dashes, has the
+// prefix and length of a card network and passes the Luhn check
+func cardNumberValid(s string) bool {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(s)
+
+	known := false
+	for _, r := range cardRanges {
+		if len(digits) < r.prefixLen {
+			continue
+		}
+		prefix, err := strconv.Atoi(digits[:r.prefixLen])
+		if err != nil || prefix < r.low || prefix > r.high {
+			continue
+		}
+		for _, length := range r.lengths {
+			known = known || len(digits) == length
+		}
+	}
+
+	return known && luhnValid(digits)
+}
+
+// luhnValid reports whether the digits in s pass the Luhn checksum used by
+// payment card numbers. Spaces and dashes are ignored.
+func luhnValid(s string) bool {
+	sum := 0
+	digits := 0
+	double := false
+
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		if c < '0' || c > '9' {
+			return false
+		}
+
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+		double = !double
+	}
+
+	return digits >= 13 && digits <= 19 && sum%10 == 0
+}